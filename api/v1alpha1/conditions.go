@@ -4,3 +4,36 @@ type ConditionType string
 type ConditionReason string
 
 const ConditionTypeReady ConditionType = "Ready"
+
+// ConditionTypePublishSLOExceeded is set when a spec change has not been validated in the provider within the configured publish SLO
+const ConditionTypePublishSLOExceeded ConditionType = "PublishSLOExceeded"
+
+// ConditionTypePublishDeadlineExceeded is set when a spec change has not been validated in the provider within the record's publish deadline
+const ConditionTypePublishDeadlineExceeded ConditionType = "PublishDeadlineExceeded"
+
+// ConditionTypeChurnAnomaly is set when changes of a record take the rate of deletions and target changes in its zone above the configured limit
+const ConditionTypeChurnAnomaly ConditionType = "ChurnAnomaly"
+
+// ConditionTypeOverridden is set when the targets of a record are pinned by the override annotations
+const ConditionTypeOverridden ConditionType = "Overridden"
+
+// ConditionTypeShadowDiverged is set, in shadow mode, when the provider zone does not hold the records of all endpoints of a record
+const ConditionTypeShadowDiverged ConditionType = "ShadowDiverged"
+
+// ConditionTypeDryRun is set, in dry run mode, to whether the provider would accept the changes of a record
+const ConditionTypeDryRun ConditionType = "DryRun"
+
+// ConditionTypePublished is set when the endpoints of the current generation of a record are published to the provider zone
+const ConditionTypePublished ConditionType = "Published"
+
+// ConditionTypeStale is set when the provider zone holds the endpoints of an older generation of a record than the current one
+const ConditionTypeStale ConditionType = "Stale"
+
+// ConditionTypeProviderError is set when the last operation on the provider for a record failed
+const ConditionTypeProviderError ConditionType = "ProviderError"
+
+// ConditionTypeTargetsExcluded is set when targets of a record are within the excluded target CIDRs and not published
+const ConditionTypeTargetsExcluded ConditionType = "TargetsExcluded"
+
+// ConditionTypePropagated is set, when propagation is verified, to whether the authoritative name servers of the zone serve the endpoints of a record
+const ConditionTypePropagated ConditionType = "Propagated"
@@ -18,8 +18,11 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net/netip"
+	"regexp"
 	"strings"
 
+	"golang.org/x/net/publicsuffix"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	externaldns "sigs.k8s.io/external-dns/endpoint"
 
@@ -58,12 +61,46 @@ type HealthCheckStatus struct {
 	Probes     []HealthCheckStatusProbe `json:"probes,omitempty"`
 }
 
+// ProbesFor returns the probes of the endpoint with the given dnsName and setIdentifier
+func (s *HealthCheckStatus) ProbesFor(dnsName, setIdentifier string) []HealthCheckStatusProbe {
+	var probes []HealthCheckStatusProbe
+	for _, probe := range s.Probes {
+		if probe.DNSName == dnsName && probe.SetIdentifier == setIdentifier {
+			probes = append(probes, probe)
+		}
+	}
+	return probes
+}
+
 type HealthCheckStatusProbe struct {
-	ID         string             `json:"id"`
-	IPAddress  string             `json:"ipAddress"`
-	Host       string             `json:"host"`
-	Synced     bool               `json:"synced,omitempty"`
-	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	ID        string `json:"id"`
+	IPAddress string `json:"ipAddress"`
+	Host      string `json:"host"`
+	// dnsName is the dnsName of the endpoint the probe is monitoring a target of
+	DNSName string `json:"dnsName,omitempty"`
+	// setIdentifier is the setIdentifier of the endpoint the probe is monitoring a target of
+	SetIdentifier string             `json:"setIdentifier,omitempty"`
+	Synced        bool               `json:"synced,omitempty"`
+	Conditions    []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// OwnedRecord identifies a record set in the provider zone
+type OwnedRecord struct {
+	DNSName       string `json:"dnsName"`
+	RecordType    string `json:"recordType"`
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+}
+
+// PublishedChange records changes published to the provider zone and their cause
+type PublishedChange struct {
+	// time the changes were published
+	Time metav1.Time `json:"time"`
+	// generation of the record the changes were published for
+	Generation int64 `json:"generation"`
+	// cause of the changes, the value of the kuadrant.io/change-cause annotation if set for the generation, otherwise
+	// what triggered the changes and a summary of the endpoint changes
+	// +kubebuilder:validation:MaxLength=1024
+	Cause string `json:"cause"`
 }
 
 // DNSRecordSpec defines the desired state of DNSRecord
@@ -91,11 +128,36 @@ type DNSRecordSpec struct {
 
 	// endpoints is a list of endpoints that will be published into the dns provider.
 	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:XValidation:rule="self.all(ep, !has(ep.recordTTL) || (ep.recordTTL >= 0 && ep.recordTTL <= 2147483647))",message="Endpoint TTLs must be between 0 and 2147483647"
 	// +optional
 	Endpoints []*externaldns.Endpoint `json:"endpoints,omitempty"`
 
+	// providerSpecific are provider specific properties set on all endpoints that do not set them themselves,
+	// e.g. aws/evaluate-target-health. They are passed to the provider as they are.
+	// +optional
+	ProviderSpecific externaldns.ProviderSpecific `json:"providerSpecific,omitempty"`
+
 	// +optional
 	HealthCheck *HealthCheckSpec `json:"healthCheck,omitempty"`
+
+	// deletionPolicy is what happens to the records of the DNSRecord in the provider when it is deleted.
+	// Delete, the default, removes them. Retain leaves them, and their ownership, in the provider, e.g. so the
+	// DNSRecord can be deleted during a migration without taking DNS down.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// publishDeadline is the time a spec change must be published and validated in the provider within.
+	// If exceeded, the PublishDeadlineExceeded condition is set until the change is validated.
+	// +optional
+	PublishDeadline *metav1.Duration `json:"publishDeadline,omitempty"`
+
+	// resyncInterval is the maximum time between validations of the DNSRecord in the provider. Records in the provider
+	// zone found to have drifted from the endpoints, e.g. edited in the provider console, are corrected on validation.
+	// Defaults to the maximum requeue time of the controller.
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('30s')",message="resyncInterval must be at least 30s"
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
 }
 
 // DNSRecordStatus defines the observed state of DNSRecord
@@ -111,6 +173,10 @@ type DNSRecordStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// lastAppliedGeneration is the most recent generation of the DNSRecord whose endpoints were published to the provider zone.
+	// +optional
+	LastAppliedGeneration int64 `json:"lastAppliedGeneration,omitempty"`
+
 	// QueuedAt is a time when DNS record was received for the reconciliation
 	QueuedAt metav1.Time `json:"queuedAt,omitempty"`
 
@@ -127,6 +193,10 @@ type DNSRecordStatus struct {
 	// endpoints are the last endpoints that were successfully published to the provider zone
 	Endpoints []*externaldns.Endpoint `json:"endpoints,omitempty"`
 
+	// endpointsCount is the number of endpoints last successfully published to the provider zone
+	// +optional
+	EndpointsCount int `json:"endpointsCount,omitempty"`
+
 	HealthCheck *HealthCheckStatus `json:"healthCheck,omitempty"`
 
 	// ownerID is a unique string used to identify the owner of this record.
@@ -135,16 +205,31 @@ type DNSRecordStatus struct {
 	// DomainOwners is a list of all the owners working against the root domain of this record
 	DomainOwners []string `json:"domainOwners,omitempty"`
 
+	// ownedRecords are the record sets, including registry TXT records, this record owns in the provider zone.
+	// +optional
+	OwnedRecords []OwnedRecord `json:"ownedRecords,omitempty"`
+
 	// zoneID is the provider specific id to which this dns record is publishing endpoints
 	ZoneID string `json:"zoneID,omitempty"`
 
 	// zoneDomainName is the domain name of the zone that the dns record is publishing endpoints
 	ZoneDomainName string `json:"zoneDomainName,omitempty"`
+
+	// specChangedAt is the time a spec change, not yet validated in the provider, was first observed.
+	// It is cleared once the provider is found to hold the published endpoints.
+	// +optional
+	SpecChangedAt *metav1.Time `json:"specChangedAt,omitempty"`
+
+	// publishedChanges are the most recent changes published to the provider zone, and their cause, newest first.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	PublishedChanges []PublishedChange `json:"publishedChanges,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="DNSRecord ready."
+//+kubebuilder:printcolumn:name="Endpoints",type="integer",JSONPath=".status.endpointsCount",description="Number of published endpoints.",priority=1
 
 // DNSRecord is the Schema for the dnsrecords API
 type DNSRecord struct {
@@ -164,6 +249,17 @@ type DNSRecordList struct {
 	Items           []DNSRecord `json:"items"`
 }
 
+// DeletionPolicy is what happens to the records of a DNSRecord in the provider when it is deleted
+// +kubebuilder:validation:Enum=Delete;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete removes the records of a DNSRecord from the provider when it is deleted
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain leaves the records of a DNSRecord, and their ownership, in the provider when it is deleted
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // DNSRecordType is a DNS resource record type.
 // +kubebuilder:validation:Enum=CNAME;A
 type DNSRecordType string
@@ -183,19 +279,74 @@ const (
 
 const WildcardPrefix = "*."
 
+// AcknowledgeChurnAnnotation is the annotation acknowledging the changes of the generation of a DNSRecord it is set
+// to, allowing them to be written while writes exceeding the zone churn limit are paused.
+const AcknowledgeChurnAnnotation = "kuadrant.io/acknowledge-churn"
+
+// ChangeCauseAnnotation is the annotation, set by whoever updates a DNSRecord, describing the cause of the update,
+// e.g. the source object and field that changed. It is recorded in status.publishedChanges when the update is published,
+// and removed once the generation it was set on is published so it is not recorded for later generations.
+const ChangeCauseAnnotation = "kuadrant.io/change-cause"
+
+// MaxPublishedChanges is the number of published changes kept in status.publishedChanges
+const MaxPublishedChanges = 10
+
+// MaxChangeCauseLength is the length published change causes are truncated to
+const MaxChangeCauseLength = 1024
+
+// OverrideTargetsAnnotation is the break-glass annotation pinning hosts of a DNSRecord to other targets during an
+// incident. Its value is a JSON object of endpoint dnsName to targets, e.g. {"app.example.com": ["172.32.200.1"]}.
+// The override is published in place of the spec targets until the time of the OverrideExpiresAnnotation.
+const OverrideTargetsAnnotation = "kuadrant.io/override-targets"
+
+// OverrideExpiresAnnotation is the RFC 3339 time the OverrideTargetsAnnotation expires, after which the spec targets
+// are published again. It is required by the OverrideTargetsAnnotation.
+const OverrideExpiresAnnotation = "kuadrant.io/override-expires"
+
+// AdoptRecordsAnnotation, set to "true", allows a DNSRecord to take ownership of records already present in the
+// provider zone that are not owned by any DNSRecord, writing their registry TXT records, instead of reporting an owner
+// conflict. It is ignored unless the controller enables adoption.
+const AdoptRecordsAnnotation = "kuadrant.io/adopt-records"
+
+// RegistryTXTPrefix is the prefix of the TXT records holding ownership of endpoints in the provider zone.
+// Hostnames starting with the prefix followed by a managed record type, e.g. "kuadrant-a-", are reserved.
+const RegistryTXTPrefix = "kuadrant-"
+
+// LoadBalancedLabel is the label the load-balanced records of a root host are published under, e.g.
+// klb.app.example.com, so root hosts must not start with it
+const LoadBalancedLabel = "klb"
+
+// MaxTTL is the maximum TTL of an endpoint, as defined by RFC 2181
+const MaxTTL = 2147483647
+
 func (s *DNSRecord) Validate() error {
 	root := s.Spec.RootHost
 	if len(s.Spec.Endpoints) == 0 {
 		return fmt.Errorf("no endpoints defined for DNSRecord. Nothing to do")
 	}
 
+	if err := validateWildcard(root); err != nil {
+		return fmt.Errorf("invalid rootHost %s, %w", root, err)
+	}
 	root, _ = strings.CutPrefix(root, WildcardPrefix)
 
 	rootEndpointFound := false
 	for _, ep := range s.Spec.Endpoints {
-		if !strings.HasSuffix(ep.DNSName, root) {
+		if ep == nil {
+			return fmt.Errorf("invalid endpoint set, endpoints must not be null")
+		}
+		if err := validateWildcard(ep.DNSName); err != nil {
+			return fmt.Errorf("invalid endpoint discovered %s, dnsName %w", ep.DNSName, err)
+		}
+		if !inDomain(ep.DNSName, root) {
 			return fmt.Errorf("invalid endpoint discovered %s all endpoints should be equal to or end with the rootHost %s", ep.DNSName, root)
 		}
+		if err := validateEndpoint(withProviderSpecific(ep, s.Spec.ProviderSpecific)); err != nil {
+			return err
+		}
+		if err := validateTargets(ep); err != nil {
+			return err
+		}
 		if !rootEndpointFound {
 			//check original root
 			if ep.DNSName == s.Spec.RootHost {
@@ -206,9 +357,184 @@ func (s *DNSRecord) Validate() error {
 	if !rootEndpointFound {
 		return fmt.Errorf("invalid endpoint set. rootHost is set but found no endpoint defining a record for the rootHost %s", root)
 	}
+	return validateFailover(s.Spec.Endpoints, s.Spec.ProviderSpecific)
+}
+
+// ValidateAdmission applies, on top of Validate, the checks introduced after DNSRecords may have been stored without
+// them. They are only applied when DNSRecords are admitted, so stored DNSRecords that fail them are still reconciled.
+func (s *DNSRecord) ValidateAdmission() error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if err := validateHostname(s.Spec.RootHost); err != nil {
+		return fmt.Errorf("invalid rootHost %s, %w", s.Spec.RootHost, err)
+	}
+	if strings.HasPrefix(strings.TrimPrefix(strings.ToLower(s.Spec.RootHost), WildcardPrefix), LoadBalancedLabel+".") {
+		return fmt.Errorf("invalid rootHost %s, the %s label is reserved for the load-balanced records of a root host", s.Spec.RootHost, LoadBalancedLabel)
+	}
+	keys := make(map[externaldns.EndpointKey]bool, len(s.Spec.Endpoints))
+	for _, ep := range s.Spec.Endpoints {
+		if err := validateHostname(ep.DNSName); err != nil {
+			return fmt.Errorf("invalid endpoint discovered %s, dnsName %w", ep.DNSName, err)
+		}
+		if keys[ep.Key()] {
+			return fmt.Errorf("invalid endpoint discovered %s, duplicate %s endpoint with setIdentifier '%s'", ep.DNSName, ep.RecordType, ep.SetIdentifier)
+		}
+		keys[ep.Key()] = true
+		if ep.RecordType != externaldns.RecordTypeCNAME {
+			continue
+		}
+		for _, target := range ep.Targets {
+			if !isFQDN(target) {
+				return fmt.Errorf("invalid target %s for CNAME endpoint %s, CNAME targets must be fully qualified domain names", target, ep.DNSName)
+			}
+		}
+	}
 	return nil
 }
 
+// ApplyProviderSpecific sets the provider specific properties of the spec on all endpoints that do not set them themselves
+func (s *DNSRecord) ApplyProviderSpecific() {
+	for i, ep := range s.Spec.Endpoints {
+		s.Spec.Endpoints[i] = withProviderSpecific(ep, s.Spec.ProviderSpecific)
+	}
+}
+
+// withProviderSpecific returns a copy of the given endpoint with the given provider specific properties it does not set
+func withProviderSpecific(ep *externaldns.Endpoint, providerSpecific externaldns.ProviderSpecific) *externaldns.Endpoint {
+	if len(providerSpecific) == 0 {
+		return ep
+	}
+	ep = ep.DeepCopy()
+	for _, property := range providerSpecific {
+		if _, ok := ep.GetProviderSpecificProperty(property.Name); !ok {
+			ep.WithProviderSpecific(property.Name, property.Value)
+		}
+	}
+	return ep
+}
+
+// validateEndpoint checks the structural rules of an endpoint. The TTL range is also a CEL validation rule of the CRD.
+// The others are not, as CEL rules are applied to every update, so records stored before they were introduced could no
+// longer be updated, e.g. to remove their finalizer.
+func validateEndpoint(ep *externaldns.Endpoint) error {
+	if ep.RecordType == externaldns.RecordTypeCNAME && len(ep.Targets) > 1 {
+		return fmt.Errorf("invalid endpoint discovered %s, CNAME endpoints must have a single target", ep.DNSName)
+	}
+	if ep.RecordTTL < 0 || ep.RecordTTL > MaxTTL {
+		return fmt.Errorf("invalid endpoint discovered %s, TTL %d must be between 0 and %d", ep.DNSName, ep.RecordTTL, MaxTTL)
+	}
+	if failover, ok := ep.GetProviderSpecificProperty(ProviderSpecificFailover); ok {
+		if failover != FailoverPrimary && failover != FailoverSecondary {
+			return fmt.Errorf("invalid endpoint discovered %s, failover must be one of %s or %s", ep.DNSName, FailoverPrimary, FailoverSecondary)
+		}
+		if ep.SetIdentifier == "" {
+			return fmt.Errorf("invalid endpoint discovered %s, failover endpoints must have a setIdentifier", ep.DNSName)
+		}
+	}
+	if _, ok := ep.GetProviderSpecificProperty(ProviderSpecificRegion); ok && ep.SetIdentifier == "" {
+		return fmt.Errorf("invalid endpoint discovered %s, latency endpoints must have a setIdentifier", ep.DNSName)
+	}
+	return nil
+}
+
+// validateFailover ensures every dnsName and record type using failover routing has exactly one PRIMARY and one
+// SECONDARY endpoint
+func validateFailover(endpoints []*externaldns.Endpoint, providerSpecific externaldns.ProviderSpecific) error {
+	type failoverKey struct{ dnsName, recordType string }
+	counts := map[failoverKey]map[string]int{}
+	var keys []failoverKey
+	for _, ep := range endpoints {
+		failover, ok := withProviderSpecific(ep, providerSpecific).GetProviderSpecificProperty(ProviderSpecificFailover)
+		if !ok {
+			continue
+		}
+		key := failoverKey{ep.DNSName, ep.RecordType}
+		if counts[key] == nil {
+			counts[key] = map[string]int{}
+			keys = append(keys, key)
+		}
+		counts[key][failover]++
+	}
+	for _, key := range keys {
+		if counts[key][FailoverPrimary] != 1 || counts[key][FailoverSecondary] != 1 {
+			return fmt.Errorf("invalid endpoint discovered %s, failover %s endpoints must be exactly one %s and one %s endpoint", key.dnsName, key.recordType, FailoverPrimary, FailoverSecondary)
+		}
+	}
+	return nil
+}
+
+// validateTargets ensures all targets of A and AAAA endpoints are valid IPv4 and IPv6 addresses respectively
+func validateTargets(ep *externaldns.Endpoint) error {
+	var family string
+	var inFamily func(netip.Addr) bool
+	switch ep.RecordType {
+	case externaldns.RecordTypeA:
+		family, inFamily = "IPv4", netip.Addr.Is4
+	case externaldns.RecordTypeAAAA:
+		family, inFamily = "IPv6", func(addr netip.Addr) bool { return addr.Is6() && !addr.Is4In6() && addr.Zone() == "" }
+	default:
+		return nil
+	}
+	for _, target := range ep.Targets {
+		if addr, err := netip.ParseAddr(target); err != nil || !inFamily(addr) {
+			return fmt.Errorf("invalid target %s for %s endpoint %s, all targets must be %s addresses", target, ep.RecordType, ep.DNSName, family)
+		}
+	}
+	return nil
+}
+
+// dnsLabel matches a single label of a domain name. Underscores are allowed as they are used by service records.
+var dnsLabel = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?$`)
+
+// isFQDN returns true if the given name, with or without a trailing dot, is a domain name of at least two labels
+// and not an IP address
+func isFQDN(name string) bool {
+	name = strings.TrimSuffix(name, ".")
+	labels := strings.Split(name, ".")
+	if _, err := netip.ParseAddr(name); err == nil || len(name) > 253 || len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if !dnsLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateHostname returns an error if the given name is not a fully qualified domain name, without a trailing dot,
+// optionally prefixed with a single wildcard label, or if it is a wildcard validateWildcard rejects
+func validateHostname(name string) error {
+	base, _ := strings.CutPrefix(name, WildcardPrefix)
+	if !strings.Contains(base, "*") && (strings.HasSuffix(base, ".") || !isFQDN(base)) {
+		return fmt.Errorf("must be a fully qualified domain name, optionally prefixed with %s", WildcardPrefix)
+	}
+	return validateWildcard(name)
+}
+
+// validateWildcard returns an error if the given name has a wildcard other than as its whole leftmost label, or is a
+// wildcard directly below a public suffix, e.g. *.co.uk, as they can never be published in a zone
+func validateWildcard(name string) error {
+	base, wildcard := strings.CutPrefix(name, WildcardPrefix)
+	if strings.Contains(base, "*") {
+		return fmt.Errorf("must only have a wildcard as its whole leftmost label, e.g. %sexample.com", WildcardPrefix)
+	}
+	if wildcard {
+		base = strings.ToLower(strings.TrimSuffix(base, "."))
+		if suffix, icann := publicsuffix.PublicSuffix(base); icann && suffix == base {
+			return fmt.Errorf("must not be a wildcard of the public suffix %s", base)
+		}
+	}
+	return nil
+}
+
+// inDomain returns true if the given name is equal to, or a subdomain of, the given domain, ignoring case
+func inDomain(name, domain string) bool {
+	name, domain = strings.ToLower(name), strings.ToLower(domain)
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
 var _ ProviderAccessor = &DNSRecord{}
 
 // GetUIDHash returns a hash of the current records UID with a fixed length of 8.
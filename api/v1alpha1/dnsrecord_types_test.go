@@ -1,9 +1,15 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"net/netip"
+	"os"
+	"reflect"
 	"testing"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/yaml"
 )
 
 func TestValidate(t *testing.T) {
@@ -12,6 +18,8 @@ func TestValidate(t *testing.T) {
 		rootHost string
 		dnsNames []string
 		wantErr  bool
+		// wantAdmissionErr is set for records failing only the admission checks
+		wantAdmissionErr bool
 	}{
 		{
 			name:     "invalid domain",
@@ -56,6 +64,69 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:     "duplicate endpoints",
+			rootHost: "example.com",
+			dnsNames: []string{
+				"example.com",
+				"a.example.com",
+				"a.example.com",
+			},
+			wantAdmissionErr: true,
+		},
+		{
+			name:     "endpoint outside root domain",
+			rootHost: "example.com",
+			dnsNames: []string{
+				"example.com",
+				"fooexample.com",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "endpoint with invalid label",
+			rootHost: "example.com",
+			dnsNames: []string{
+				"example.com",
+				"a..example.com",
+			},
+			wantAdmissionErr: true,
+		},
+		{
+			name:     "endpoint with trailing dot",
+			rootHost: "example.com",
+			dnsNames: []string{
+				"example.com",
+				"a.example.com.",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "root host with trailing dot",
+			rootHost: "example.com.",
+			dnsNames: []string{
+				"example.com.",
+			},
+			wantAdmissionErr: true,
+		},
+		{
+			name:     "endpoint with label that is not a valid DNS label",
+			rootHost: "example.com",
+			dnsNames: []string{
+				"example.com",
+				"-a.example.com",
+			},
+			wantAdmissionErr: true,
+		},
+		{
+			name:     "endpoint with wildcard not in first label",
+			rootHost: "example.com",
+			dnsNames: []string{
+				"example.com",
+				"a.*.example.com",
+			},
+			wantErr: true,
+		},
 		{
 			name:     "valid wildcard domain no endpoint",
 			rootHost: "*.example.com",
@@ -67,6 +138,48 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:     "multi-level wildcard",
+			rootHost: "*.*.example.com",
+			dnsNames: []string{
+				"*.*.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "wildcard of a public suffix",
+			rootHost: "*.co.uk",
+			dnsNames: []string{
+				"*.co.uk",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "root host in the load-balanced records of another root host",
+			rootHost: "klb.example.com",
+			dnsNames: []string{
+				"klb.example.com",
+			},
+			wantAdmissionErr: true,
+		},
+		{
+			name:     "wildcard root host in the load-balanced records of another root host",
+			rootHost: "*.klb.example.com",
+			dnsNames: []string{
+				"*.klb.example.com",
+			},
+			wantAdmissionErr: true,
+		},
+		{
+			name:     "load-balanced records below the root host",
+			rootHost: "app.example.com",
+			dnsNames: []string{
+				"app.example.com",
+				"klb.app.example.com",
+				"eu.klb.app.example.com",
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -84,6 +197,404 @@ func TestValidate(t *testing.T) {
 				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			err = record.ValidateAdmission()
+			if (err != nil) != (tt.wantErr || tt.wantAdmissionErr) {
+				t.Errorf("ValidateAdmission() error = %v, wantErr %v", err, tt.wantErr || tt.wantAdmissionErr)
+			}
+		})
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr string
+	}{
+		{
+			name: "hostname",
+			host: "app.example.com",
+		},
+		{
+			name: "wildcard",
+			host: "*.example.com",
+		},
+		{
+			name: "wildcard of a private suffix",
+			host: "*.github.io",
+		},
+		{
+			name:    "sub-wildcard",
+			host:    "*.apps.*.example.com",
+			wantErr: "must only have a wildcard as its whole leftmost label, e.g. *.example.com",
+		},
+		{
+			name:    "multi-level wildcard",
+			host:    "*.*.example.com",
+			wantErr: "must only have a wildcard as its whole leftmost label, e.g. *.example.com",
+		},
+		{
+			name:    "partial wildcard label",
+			host:    "app-*.example.com",
+			wantErr: "must only have a wildcard as its whole leftmost label, e.g. *.example.com",
+		},
+		{
+			name:    "wildcard of a top level domain",
+			host:    "*.com",
+			wantErr: "must be a fully qualified domain name, optionally prefixed with *.",
+		},
+		{
+			name:    "wildcard of a public suffix",
+			host:    "*.co.uk",
+			wantErr: "must not be a wildcard of the public suffix co.uk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHostname(tt.host)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateHostname() unexpected error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("validateHostname() error = %v, want %s", err, tt.wantErr)
+			}
 		})
 	}
 }
+
+func TestValidateTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		targets    []string
+		wantErr    bool
+		// wantAdmissionErr is set for targets failing only the admission checks
+		wantAdmissionErr bool
+	}{
+		{
+			name:       "valid A targets",
+			recordType: endpoint.RecordTypeA,
+			targets:    []string{"127.0.0.1", "127.0.0.2"},
+			wantErr:    false,
+		},
+		{
+			name:       "invalid A target",
+			recordType: endpoint.RecordTypeA,
+			targets:    []string{"127.0.0.256"},
+			wantErr:    true,
+		},
+		{
+			name:       "mixed families in A targets",
+			recordType: endpoint.RecordTypeA,
+			targets:    []string{"127.0.0.1", "2001:db8::1"},
+			wantErr:    true,
+		},
+		{
+			name:       "valid AAAA targets",
+			recordType: endpoint.RecordTypeAAAA,
+			targets:    []string{"2001:db8::1", "2001:DB8:0:0:0:0:0:2"},
+			wantErr:    false,
+		},
+		{
+			name:       "IPv4 AAAA target",
+			recordType: endpoint.RecordTypeAAAA,
+			targets:    []string{"127.0.0.1"},
+			wantErr:    true,
+		},
+		{
+			name:       "IPv4 mapped AAAA target",
+			recordType: endpoint.RecordTypeAAAA,
+			targets:    []string{"::ffff:127.0.0.1"},
+			wantErr:    true,
+		},
+		{
+			name:       "zoned AAAA target",
+			recordType: endpoint.RecordTypeAAAA,
+			targets:    []string{"fe80::1%eth0"},
+			wantErr:    true,
+		},
+		{
+			name:       "valid CNAME target",
+			recordType: endpoint.RecordTypeCNAME,
+			targets:    []string{"lb.example.com"},
+			wantErr:    false,
+		},
+		{
+			name:       "CNAME target with trailing dot",
+			recordType: endpoint.RecordTypeCNAME,
+			targets:    []string{"lb.example.com."},
+			wantErr:    false,
+		},
+		{
+			name:             "non FQDN CNAME target",
+			recordType:       endpoint.RecordTypeCNAME,
+			targets:          []string{"lb"},
+			wantAdmissionErr: true,
+		},
+		{
+			name:             "malformed CNAME target",
+			recordType:       endpoint.RecordTypeCNAME,
+			targets:          []string{"lb..example.com"},
+			wantAdmissionErr: true,
+		},
+		{
+			name:             "IP address CNAME target",
+			recordType:       endpoint.RecordTypeCNAME,
+			targets:          []string{"127.0.0.1"},
+			wantAdmissionErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &DNSRecord{
+				Spec: DNSRecordSpec{
+					RootHost:  "example.com",
+					Endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", tt.recordType, tt.targets...)},
+				},
+			}
+			err := record.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			err = record.ValidateAdmission()
+			if (err != nil) != (tt.wantErr || tt.wantAdmissionErr) {
+				t.Errorf("ValidateAdmission() error = %v, wantErr %v", err, tt.wantErr || tt.wantAdmissionErr)
+			}
+		})
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *endpoint.Endpoint
+		wantErr  bool
+	}{
+		{
+			name:     "valid CNAME",
+			endpoint: endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeCNAME, 60, "lb.example.org"),
+			wantErr:  false,
+		},
+		{
+			name:     "CNAME with multiple targets",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb1.example.org", "lb2.example.org"),
+			wantErr:  true,
+		},
+		{
+			name:     "negative TTL",
+			endpoint: endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, -1, "127.0.0.1"),
+			wantErr:  true,
+		},
+		{
+			name:     "TTL above maximum",
+			endpoint: endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, MaxTTL+1, "127.0.0.1"),
+			wantErr:  true,
+		},
+		{
+			name: "failover primary",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb.eu.example.org").
+				WithSetIdentifier("eu").WithProviderSpecific(ProviderSpecificFailover, FailoverPrimary),
+			wantErr: false,
+		},
+		{
+			name: "invalid failover value",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb.eu.example.org").
+				WithSetIdentifier("eu").WithProviderSpecific(ProviderSpecificFailover, "TERTIARY"),
+			wantErr: true,
+		},
+		{
+			name: "latency without setIdentifier",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb.eu.example.org").
+				WithProviderSpecific(ProviderSpecificRegion, "eu-west-1"),
+			wantErr: true,
+		},
+		{
+			name: "failover without setIdentifier",
+			endpoint: endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb.us.example.org").
+				WithProviderSpecific(ProviderSpecificFailover, FailoverSecondary),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEndpoint(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFailover(t *testing.T) {
+	failover := func(setIdentifier, value string) *endpoint.Endpoint {
+		return endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb."+setIdentifier+".example.org").
+			WithSetIdentifier(setIdentifier).WithProviderSpecific(ProviderSpecificFailover, value)
+	}
+	tests := []struct {
+		name      string
+		endpoints []*endpoint.Endpoint
+		wantErr   bool
+	}{
+		{
+			name:      "primary and secondary",
+			endpoints: []*endpoint.Endpoint{failover("eu", FailoverPrimary), failover("us", FailoverSecondary)},
+			wantErr:   false,
+		},
+		{
+			name:      "primary only",
+			endpoints: []*endpoint.Endpoint{failover("eu", FailoverPrimary)},
+			wantErr:   true,
+		},
+		{
+			name:      "two primaries",
+			endpoints: []*endpoint.Endpoint{failover("eu", FailoverPrimary), failover("us", FailoverPrimary)},
+			wantErr:   true,
+		},
+		{
+			name: "two secondaries",
+			endpoints: []*endpoint.Endpoint{
+				failover("eu", FailoverPrimary), failover("us", FailoverSecondary), failover("ap", FailoverSecondary),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no failover",
+			endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "127.0.0.1"),
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &DNSRecord{
+				Spec: DNSRecordSpec{
+					RootHost:  "example.com",
+					Endpoints: tt.endpoints,
+				},
+			}
+			if err := record.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHealthCheckStatusProbesFor(t *testing.T) {
+	status := &HealthCheckStatus{
+		Probes: []HealthCheckStatusProbe{
+			{ID: "1", IPAddress: "127.0.0.1", DNSName: "a.example.com", SetIdentifier: "cluster1"},
+			{ID: "2", IPAddress: "127.0.0.2", DNSName: "a.example.com", SetIdentifier: "cluster1"},
+			{ID: "3", IPAddress: "127.0.0.1", DNSName: "a.example.com", SetIdentifier: "cluster2"},
+			{ID: "4", IPAddress: "127.0.0.1", DNSName: "b.example.com", SetIdentifier: "cluster1"},
+		},
+	}
+
+	probes := status.ProbesFor("a.example.com", "cluster1")
+	if len(probes) != 2 || probes[0].ID != "1" || probes[1].ID != "2" {
+		t.Errorf("ProbesFor() = %v, want probes 1 and 2", probes)
+	}
+	if probes := status.ProbesFor("c.example.com", ""); len(probes) != 0 {
+		t.Errorf("ProbesFor() = %v, want no probes", probes)
+	}
+}
+
+func TestApplyProviderSpecific(t *testing.T) {
+	record := &DNSRecord{
+		Spec: DNSRecordSpec{
+			RootHost: "example.com",
+			Endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "127.0.0.1"),
+				endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "127.0.0.2").
+					WithProviderSpecific("aws/evaluate-target-health", "false"),
+			},
+			ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/evaluate-target-health", Value: "true"}},
+		},
+	}
+	original := record.Spec.Endpoints[0]
+
+	record.ApplyProviderSpecific()
+
+	for _, want := range []struct{ dnsName, value string }{{"example.com", "true"}, {"a.example.com", "false"}} {
+		for _, ep := range record.Spec.Endpoints {
+			if ep.DNSName != want.dnsName {
+				continue
+			}
+			if value, _ := ep.GetProviderSpecificProperty("aws/evaluate-target-health"); value != want.value {
+				t.Errorf("ApplyProviderSpecific() %s aws/evaluate-target-health = %q, want %q", ep.DNSName, value, want.value)
+			}
+		}
+	}
+	if len(original.ProviderSpecific) != 0 {
+		t.Errorf("ApplyProviderSpecific() modified the original endpoint")
+	}
+
+	record.Spec.ProviderSpecific = endpoint.ProviderSpecific{{Name: ProviderSpecificFailover, Value: FailoverPrimary}}
+	if err := record.Validate(); err == nil {
+		t.Errorf("Validate() expected error for record failover without setIdentifier")
+	}
+}
+
+func FuzzValidate(f *testing.F) {
+	f.Add("example.com", "example.com", endpoint.RecordTypeA, "127.0.0.1")
+	f.Add("*.example.com", "*.example.com", endpoint.RecordTypeCNAME, "lb.example.org")
+	f.Add("example.com", "a.example.com", endpoint.RecordTypeAAAA, "2001:db8::1")
+	f.Add("example.com", "example.com.", endpoint.RecordTypeCNAME, "lb.example.org.")
+	f.Add("example.com", "*.*.example.com", endpoint.RecordTypeA, "::ffff:127.0.0.1")
+	f.Add("example.com", "a..example.com", endpoint.RecordTypeCNAME, "127.0.0.1")
+	f.Add("*.co.uk", "*.co.uk", endpoint.RecordTypeA, "127.0.0.1")
+
+	f.Fuzz(func(t *testing.T, rootHost, dnsName, recordType, target string) {
+		record := &DNSRecord{
+			Spec: DNSRecordSpec{
+				RootHost:  rootHost,
+				Endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint(dnsName, recordType, target)},
+			},
+		}
+		if err := record.Validate(); err != nil {
+			return
+		}
+		// NewEndpoint trims trailing dots of the dnsName and targets
+		ep := record.Spec.Endpoints[0]
+		dnsName, target = ep.DNSName, ep.Targets[0]
+		if err := validateWildcard(dnsName); err != nil {
+			t.Errorf("Validate() accepted invalid wildcard dnsName %q", dnsName)
+		}
+		if err := validateHostname(dnsName); err != nil && record.ValidateAdmission() == nil {
+			t.Errorf("ValidateAdmission() accepted invalid dnsName %q", dnsName)
+		}
+		switch recordType {
+		case endpoint.RecordTypeA, endpoint.RecordTypeAAAA:
+			if _, err := netip.ParseAddr(target); err != nil {
+				t.Errorf("Validate() accepted invalid %s target %q", recordType, target)
+			}
+		case endpoint.RecordTypeCNAME:
+			if !isFQDN(target) && record.ValidateAdmission() == nil {
+				t.Errorf("ValidateAdmission() accepted invalid CNAME target %q", target)
+			}
+		}
+	})
+}
+
+// TestCRDValidationRules ensures the CEL validation rules of the generated CRD are those validateEndpoint mirrors
+func TestCRDValidationRules(t *testing.T) {
+	data, err := os.ReadFile("../../config/crd/bases/kuadrant.io_dnsrecords.yaml")
+	if err != nil {
+		t.Fatalf("reading CRD: %v", err)
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err = yaml.Unmarshal(data, crd); err != nil {
+		t.Fatalf("decoding CRD: %v", err)
+	}
+	var rules []string
+	for _, rule := range crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["endpoints"].XValidations {
+		rules = append(rules, rule.Rule)
+	}
+	want := []string{fmt.Sprintf("self.all(ep, !has(ep.recordTTL) || (ep.recordTTL >= 0 && ep.recordTTL <= %d))", MaxTTL)}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("endpoints validation rules = %v, want %v", rules, want)
+	}
+}
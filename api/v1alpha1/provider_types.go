@@ -17,6 +17,19 @@ limitations under the License.
 package v1alpha1
 
 const (
-	ProviderSpecificWeight  = "weight"
-	ProviderSpecificGeoCode = "geo-code"
+	ProviderSpecificWeight   = "weight"
+	ProviderSpecificGeoCode  = "geo-code"
+	ProviderSpecificFailover = "failover"
+	ProviderSpecificRegion   = "region"
+)
+
+// ContinentCodePrefix prefixes continent values of the ProviderSpecificGeoCode property, e.g. GEO-EU, distinguishing
+// them from ISO 3166 country codes with the same letters, e.g. GEO-NA (North America) and NA (Namibia).
+const ContinentCodePrefix = "GEO-"
+
+// Values of the ProviderSpecificFailover property. Endpoints of the same dnsName and record type sharing the failover
+// property are answered from the primary endpoint while it is healthy, and from the secondary endpoint otherwise.
+const (
+	FailoverPrimary   = "PRIMARY"
+	FailoverSecondary = "SECONDARY"
 )
@@ -32,6 +32,8 @@ const (
 	AWSSecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
 	// AWSRegionKey is the key of the optional region for SecretTypeKuadrantAWS provider secrets
 	AWSRegionKey = "AWS_REGION"
+	// AWSSessionTokenKey is the key of the optional session token, of temporary credentials, for SecretTypeKuadrantAWS provider secrets
+	AWSSessionTokenKey = "AWS_SESSION_TOKEN"
 
 	// SecretTypeKuadrantGCP contains data needed for gcp(google cloud dns) authentication and configuration.
 	//
@@ -59,6 +61,30 @@ const (
 
 	// InmemInitZonesKey is the key of the optional comma separated list of zone names to initialise in the SecretTypeKuadrantInmemory provider secrets
 	InmemInitZonesKey = "INMEM_INIT_ZONES"
+
+	// VaultCredentialsPathKey is the key of the optional path, of a Vault AWS or GCP secrets engine endpoint, to fetch
+	// short-lived credentials from for SecretTypeKuadrantAWS and SecretTypeKuadrantGCP provider secrets,
+	// e.g. "aws/creds/dns" or "gcp/roleset/dns/key". It must be within the path bound to the namespace of the secret by
+	// the operator. Credentials fetched from Vault replace any in the secret.
+	VaultCredentialsPathKey = "VAULT_CREDENTIALS_PATH"
+	// VaultRoleKey is the key of the optional Vault kubernetes auth role the operator logs in with. The role is bound to
+	// the namespace of the secret by the operator, secrets naming any other role are rejected.
+	VaultRoleKey = "VAULT_ROLE"
+	// VaultAddressKey is the key of the Vault address. Provider secrets setting it are rejected, the operator only logs
+	// in to the Vault it is configured with, so its ServiceAccount token is never sent to a server named by a tenant.
+	VaultAddressKey = "VAULT_ADDR"
+	// VaultAuthPathKey is the key of the mount path of the Vault kubernetes auth method. Provider secrets setting it are
+	// rejected, as with VaultAddressKey.
+	VaultAuthPathKey = "VAULT_AUTH_PATH"
+
+	// FreezeZonesAnnotation is the annotation, on any provider secret, holding a comma separated list of zone domain names
+	// to stop all record writes to, or "*" for all zones. Desired state is still computed and reported while a zone is frozen.
+	// Intended for use while the DNS provider has an ongoing incident.
+	FreezeZonesAnnotation = "kuadrant.io/freeze-zones"
+
+	// SecretDecrypterAnnotation is the annotation, on any provider secret, naming the registered secret decrypter
+	// all of its data values are encrypted for, e.g. "aws-kms". Secrets without it hold plaintext data.
+	SecretDecrypterAnnotation = "kuadrant.io/secret-decrypter"
 )
 
 type ProviderRef struct {
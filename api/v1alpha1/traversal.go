@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	externaldns "sigs.k8s.io/external-dns/endpoint"
+)
+
+// EndpointsTraversable returns nil if all the given targets are reached by following the given endpoints from host,
+// or an error describing the broken hop otherwise. The endpoints of a hostname are followed to their targets, and
+// targets that are the hostname of other endpoints are followed in turn, so a target is reached when it is the target
+// of an endpoint on any path from host. Endpoints forming a cycle are reported as an error.
+func EndpointsTraversable(endpoints []*externaldns.Endpoint, host string, targets []string) error {
+	byName := map[string][]*externaldns.Endpoint{}
+	for _, ep := range endpoints {
+		byName[ep.DNSName] = append(byName[ep.DNSName], ep)
+	}
+	if _, ok := byName[host]; !ok {
+		return fmt.Errorf("no endpoint defines a record for host %s", host)
+	}
+
+	reached := map[string]bool{}
+	visited := map[string]bool{}
+	var walk func(path []string) error
+	walk = func(path []string) error {
+		name := path[len(path)-1]
+		visited[name] = true
+		for _, ep := range byName[name] {
+			for _, target := range ep.Targets {
+				reached[target] = true
+				if _, ok := byName[target]; !ok {
+					continue
+				}
+				if slices.Contains(path, target) {
+					return fmt.Errorf("endpoints form a cycle %s", strings.Join(append(slices.Clone(path), target), " -> "))
+				}
+				if visited[target] {
+					continue
+				}
+				if err := walk(append(slices.Clone(path), target)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk([]string{host}); err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if reached[target] {
+			continue
+		}
+		for _, ep := range endpoints {
+			if slices.Contains(ep.Targets, target) {
+				return fmt.Errorf("target %s of endpoint %s is not reachable from host %s", target, ep.DNSName, host)
+			}
+		}
+		return fmt.Errorf("target %s is not the target of any endpoint", target)
+	}
+	return nil
+}
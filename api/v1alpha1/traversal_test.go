@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestEndpointsTraversable(t *testing.T) {
+	loadBalanced := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeCNAME, "klb.app.example.com"),
+		endpoint.NewEndpoint("klb.app.example.com", endpoint.RecordTypeCNAME, "eu.klb.app.example.com").WithSetIdentifier("EU"),
+		endpoint.NewEndpoint("klb.app.example.com", endpoint.RecordTypeCNAME, "us.klb.app.example.com").WithSetIdentifier("US"),
+		endpoint.NewEndpoint("eu.klb.app.example.com", endpoint.RecordTypeCNAME, "cluster1.klb.app.example.com"),
+		endpoint.NewEndpoint("us.klb.app.example.com", endpoint.RecordTypeCNAME, "cluster1.klb.app.example.com"),
+		endpoint.NewEndpoint("cluster1.klb.app.example.com", endpoint.RecordTypeA, "172.32.200.1"),
+		endpoint.NewEndpoint("other.example.com", endpoint.RecordTypeA, "172.32.200.2"),
+	}
+
+	tests := []struct {
+		name      string
+		endpoints []*endpoint.Endpoint
+		host      string
+		targets   []string
+		wantErr   string
+	}{
+		{
+			name:      "targets reached through shared subtree",
+			endpoints: loadBalanced,
+			host:      "app.example.com",
+			targets:   []string{"172.32.200.1", "cluster1.klb.app.example.com"},
+		},
+		{
+			name:      "no endpoint for host",
+			endpoints: loadBalanced,
+			host:      "missing.example.com",
+			targets:   []string{"172.32.200.1"},
+			wantErr:   "no endpoint defines a record for host missing.example.com",
+		},
+		{
+			name:      "target of unreachable endpoint",
+			endpoints: loadBalanced,
+			host:      "app.example.com",
+			targets:   []string{"172.32.200.2"},
+			wantErr:   "target 172.32.200.2 of endpoint other.example.com is not reachable from host app.example.com",
+		},
+		{
+			name:      "unknown target",
+			endpoints: loadBalanced,
+			host:      "app.example.com",
+			targets:   []string{"172.32.200.3"},
+			wantErr:   "target 172.32.200.3 is not the target of any endpoint",
+		},
+		{
+			name: "cycle",
+			endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeCNAME, "b.example.com"),
+				endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeCNAME, "a.example.com"),
+			},
+			host:    "a.example.com",
+			targets: []string{"b.example.com"},
+			wantErr: "endpoints form a cycle a.example.com -> b.example.com -> a.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := EndpointsTraversable(tt.endpoints, tt.host, tt.targets)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("EndpointsTraversable() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("EndpointsTraversable() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
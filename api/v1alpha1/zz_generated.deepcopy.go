@@ -100,11 +100,26 @@ func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
 			}
 		}
 	}
+	if in.ProviderSpecific != nil {
+		in, out := &in.ProviderSpecific, &out.ProviderSpecific
+		*out = make(endpoint.ProviderSpecific, len(*in))
+		copy(*out, *in)
+	}
 	if in.HealthCheck != nil {
 		in, out := &in.HealthCheck, &out.HealthCheck
 		*out = new(HealthCheckSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PublishDeadline != nil {
+		in, out := &in.PublishDeadline, &out.PublishDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordSpec.
@@ -150,6 +165,22 @@ func (in *DNSRecordStatus) DeepCopyInto(out *DNSRecordStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OwnedRecords != nil {
+		in, out := &in.OwnedRecords, &out.OwnedRecords
+		*out = make([]OwnedRecord, len(*in))
+		copy(*out, *in)
+	}
+	if in.SpecChangedAt != nil {
+		in, out := &in.SpecChangedAt, &out.SpecChangedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PublishedChanges != nil {
+		in, out := &in.PublishedChanges, &out.PublishedChanges
+		*out = make([]PublishedChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordStatus.
@@ -243,6 +274,21 @@ func (in *HealthCheckStatusProbe) DeepCopy() *HealthCheckStatusProbe {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnedRecord) DeepCopyInto(out *OwnedRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnedRecord.
+func (in *OwnedRecord) DeepCopy() *OwnedRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnedRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderRef) DeepCopyInto(out *ProviderRef) {
 	*out = *in
@@ -257,3 +303,19 @@ func (in *ProviderRef) DeepCopy() *ProviderRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishedChange) DeepCopyInto(out *PublishedChange) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishedChange.
+func (in *PublishedChange) DeepCopy() *PublishedChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishedChange)
+	in.DeepCopyInto(out)
+	return out
+}
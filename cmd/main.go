@@ -17,36 +17,61 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsprovider "sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/yaml"
 
 	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/common"
 	"github.com/kuadrant/dns-operator/internal/controller"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+	"github.com/kuadrant/dns-operator/internal/profiling"
 	"github.com/kuadrant/dns-operator/internal/provider"
 	_ "github.com/kuadrant/dns-operator/internal/provider/aws"
 	_ "github.com/kuadrant/dns-operator/internal/provider/azure"
 	_ "github.com/kuadrant/dns-operator/internal/provider/google"
 	_ "github.com/kuadrant/dns-operator/internal/provider/inmemory"
+	dnswebhook "github.com/kuadrant/dns-operator/internal/webhook"
+	"github.com/kuadrant/dns-operator/pkg/validation"
+	"github.com/kuadrant/dns-operator/pkg/zone"
 	//+kubebuilder:scaffold:imports
 )
 
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	ownerIDPrefixRegexp = regexp.MustCompile(`^[a-z0-9-]*$`)
 )
 
 const (
@@ -63,13 +88,56 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(validate(os.Args[2:]))
+		case "import":
+			os.Exit(importZone(os.Args[2:]))
+		case "diff":
+			os.Exit(diffZone(os.Args[2:]))
+		}
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var minRequeueTime time.Duration
 	var validFor time.Duration
 	var maxRequeueTime time.Duration
+	var publishSLO time.Duration
+	var enforceHostClaims bool
 	var providers stringSliceFlags
+	var enableFaultInjection bool
+	var faultInjectionConfig provider.FaultInjectionConfig
+	var faultInjectionZones stringSliceFlags
+	var zoneMinValidationIntervals zoneDurationFlags
+	var zonesWithoutHealthChecks stringSliceFlags
+	var zonesRejectingWildcards stringSliceFlags
+	var requestCosts requestCostFlags
+	var managedRecordTypes stringSliceFlags
+	var routingChangeDampening time.Duration
+	var impersonateServiceAccount string
+	var registrySigningKeyFile string
+	var registrySigningMigration bool
+	var churnLimits controller.ChurnLimits
+	var verifyZoneDelegation bool
+	var verifyPropagation bool
+	var shadowMode bool
+	var dryRun bool
+	var excludedTargetCIDRs cidrFlags
+	var orphanRecordGC controller.OrphanRecordGC
+	var ownerIDPrefix string
+	var adoption string
+	var adoptionNamespaces stringSliceFlags
+	var enableWebhooks bool
+	var enablePprof bool
+	var providerReadinessWindow time.Duration
+	var providerReadinessSecrets stringSliceFlags
+	var heapProfileThreshold bytesFlag
+	var vaultConfig provider.VaultConfig
+	var vaultBindings vaultBindingFlags
+	heapProfiler := profiling.HeapProfiler{}
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -85,7 +153,114 @@ func main() {
 	flag.DurationVar(&minRequeueTime, "min-requeue-time", DefaultValidationDuration,
 		"The minimal timeout between calls to the DNS Provider"+
 			"Controls if we commit to the full reconcile loop")
+	flag.DurationVar(&publishSLO, "publish-slo", 0,
+		"The time a DNS Record spec change is expected to be validated in the DNS Provider within. "+
+			"Records exceeding it have the PublishSLOExceeded condition set. Zero disables the check")
+	flag.BoolVar(&enforceHostClaims, "enforce-host-claims", false,
+		"Reject DNS Records with endpoints for hostnames already claimed by a DNS Record in another namespace. "+
+			"The first DNS Record to claim a hostname in a zone owns it")
 	flag.Var(&providers, "provider", "DNS Provider(s) to enable. Can be passed multiple times e.g. --provider aws --provider google, or as a comma separated list e.g. --provider aws,gcp")
+	flag.BoolVar(&enableFaultInjection, "enable-fault-injection", false,
+		"Enable injection of faults into DNS Provider writes. For testing alerting and failover in non production environments only")
+	flag.Var(&faultInjectionZones, "fault-injection-zone", "Zone domain name(s) to inject DNS Provider write faults for. "+
+		"Can be passed multiple times or as a comma separated list. Requires --enable-fault-injection")
+	flag.DurationVar(&faultInjectionConfig.WriteDelay, "fault-injection-write-delay", 0,
+		"Delay added to DNS Provider writes for fault injection zones. Requires --enable-fault-injection")
+	flag.BoolVar(&faultInjectionConfig.FailWrites, "fault-injection-fail-writes", false,
+		"Fail DNS Provider writes for fault injection zones. Requires --enable-fault-injection")
+	flag.Var(&zoneMinValidationIntervals, "zone-min-validation-interval", "Minimum time between validations of DNS Records in a zone, "+
+		"to limit DNS Provider API costs, in the form <zone domain name>=<duration>. Can be passed multiple times e.g. "+
+		"--zone-min-validation-interval example.com=10m --zone-min-validation-interval example.org=1h")
+	flag.Var(&zonesWithoutHealthChecks, "zone-disable-health-checks", "Zone domain name(s) to disable DNS Provider health checks for, "+
+		"to limit DNS Provider costs. Can be passed multiple times or as a comma separated list")
+	flag.Var(&zonesRejectingWildcards, "zone-reject-wildcards", "Zone domain name(s) in which DNS Records with wildcard "+
+		"endpoints fail rather than being published. Can be passed multiple times or as a comma separated list")
+	flag.Var(&requestCosts, "provider-request-cost", "Estimated cost of a request made to a DNS Provider API, added up by the "+
+		"dns_provider_request_estimated_cost_total metric, in the form <provider>/<operation>=<cost>. Operations are Records, ApplyChanges, "+
+		"DNSZones, DNSZoneForHost, ReconcileHealthCheck, DeleteHealthCheck and HealthCheckExists. Can be passed multiple times e.g. "+
+		"--provider-request-cost aws/ApplyChanges=0.0001 --provider-request-cost aws/ReconcileHealthCheck=0.0001")
+	flag.Var(&managedRecordTypes, "managed-record-types", fmt.Sprintf("Record types to manage in DNS Provider zones, records of any other type are never changed. "+
+		"Can be passed multiple times or as a comma separated list. Defaults to %s", strings.Join(controller.DefaultManagedRecordTypes, ",")))
+	flag.DurationVar(&routingChangeDampening, "routing-change-dampening", 0,
+		"The time a change to only the weight or geo of DNS Record endpoints must be stable for before it is published. "+
+			"Coalesces rapid oscillations into a single DNS Provider write. Zero publishes routing changes immediately")
+	flag.StringVar(&impersonateServiceAccount, "impersonate-service-account", "",
+		"Name of the ServiceAccount to impersonate, in the namespace of each DNS Record, when reading its DNS Provider secret. "+
+			"Enforces tenant isolation with the RBAC of each namespace. Requires permission to impersonate the ServiceAccounts")
+	flag.StringVar(&registrySigningKeyFile, "registry-signing-key-file", "",
+		"Path of a file holding the key to sign registry TXT records with. Ownership TXT records without a valid signature are ignored. "+
+			"All clusters sharing a zone must use the same key")
+	flag.StringVar(&adoption, "adopt-records", "",
+		"How DNS Records annotated with kuadrant.io/adopt-records take ownership of unowned records in their zone: "+
+			"\"matching\" adopts records whose targets already match, \"replace\" replaces their targets. Unset never adopts records")
+	flag.Var(&adoptionNamespaces, "adopt-records-namespaces", "Namespace(s) whose DNS Records may adopt unowned records. "+
+		"Can be passed multiple times or as a comma separated list. Unset allows all namespaces. Requires --adopt-records")
+	flag.BoolVar(&registrySigningMigration, "registry-signing-migration", false,
+		"Accept unsigned ownership TXT records whose owner is the owner of the DNS Record, replacing them with signed ones. "+
+			"Enable while introducing --registry-signing-key-file to clusters with published records")
+	flag.IntVar(&churnLimits.MaxChanges, "churn-max-changes", 0,
+		"The number of record deletions and target changes in a zone within the churn window above which churn is anomalous. "+
+			"Records whose changes exceed it have the ChurnAnomaly condition set, until the churn window has passed since. Zero disables the check")
+	flag.DurationVar(&churnLimits.Window, "churn-window", time.Minute*10,
+		"The period record deletions and target changes in a zone are counted over. Requires --churn-max-changes")
+	flag.BoolVar(&churnLimits.PauseWrites, "churn-pause-writes", false,
+		"Pause writing changes that exceed the churn limit until acknowledged by setting the "+v1alpha1.AcknowledgeChurnAnnotation+
+			" annotation of the DNS Record to its generation. Requires --churn-max-changes")
+	flag.BoolVar(&verifyZoneDelegation, "verify-zone-delegation", false,
+		"Only publish DNS Records once the NS delegation of their zone, as served by the name servers of its parent zone, resolves "+
+			"to the DNS Provider name servers")
+	flag.BoolVar(&verifyPropagation, "verify-propagation", false,
+		"Query the authoritative name servers of the zone of DNS Records once they are published, reporting whether they serve "+
+			"the endpoints of each DNS Record, and the time taken since they were published, with the Propagated condition")
+	flag.BoolVar(&shadowMode, "shadow-mode", false,
+		"Compare DNS Records with the records in their DNS Provider zone, e.g. those maintained by an existing external-dns deployment, "+
+			"reporting divergence with the ShadowDiverged condition instead of publishing them. Nothing is written to DNS Providers")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Apply the changes of DNS Records to their DNS Providers in dry run mode, validating them as far as each provider allows, "+
+			"and report whether they would be accepted with the DryRun condition instead of publishing them. Nothing is written to DNS Providers")
+	flag.Var(&excludedTargetCIDRs, "excluded-target-cidrs", "CIDR(s) whose addresses are never published as targets of A or AAAA "+
+		"DNS Record endpoints, e.g. internal only addresses. Endpoints left without targets, and CNAME endpoints pointing at them, "+
+		"are not published. Can be passed multiple times or as a comma separated list")
+	flag.DurationVar(&orphanRecordGC.Interval, "orphan-record-gc-interval", 0,
+		"The time between sweeps of the DNS Provider zones of DNS Records for orphaned records, records whose owners are no longer "+
+			"the owner of any DNS Record. Sweeps are skipped when WATCH_NAMESPACES is set. Zero disables the sweep")
+	flag.BoolVar(&orphanRecordGC.Delete, "orphan-record-gc-delete", false,
+		"Delete orphaned records found by the sweep, they are only reported otherwise. Only records whose owner IDs all start with "+
+			"--owner-id-prefix are deleted, once found orphaned by several consecutive sweeps. Records retained by the Retain deletion "+
+			"policy are never deleted. Requires --orphan-record-gc-interval and --owner-id-prefix")
+	flag.StringVar(&ownerIDPrefix, "owner-id-prefix", "",
+		"Prefix of the owner IDs assigned to DNS Records without spec.ownerID, e.g. a cluster name followed by '-'. Identifies the records "+
+			"published by this cluster, including before it was rebuilt, as those orphaned records may be deleted of. Must be unique to the "+
+			"cluster across all clusters sharing a zone, and kept when the cluster is rebuilt")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the validating admission webhook rejecting invalid DNS Records at creation and update. "+
+			"Requires a serving certificate and the ValidatingWebhookConfiguration to be deployed")
+	flag.DurationVar(&providerReadinessWindow, "provider-readiness-window", 0,
+		"The time any of the --provider-readiness-secrets may go without successfully listing zones and records before the controller "+
+			"is reported not ready. Their zones are listed every third of the window. Zero excludes DNS Providers from the readiness check. "+
+			"Requires --provider-readiness-secrets")
+	flag.Var(&providerReadinessSecrets, "provider-readiness-secrets", "Provider secret(s) owned by the operator, as <namespace>/<name>, "+
+		"checked by the readiness check. Can be passed multiple times or as a comma separated list")
+	flag.BoolVar(&enablePprof, "enable-pprof", false,
+		"Serve the pprof profiling endpoints under "+profiling.PathPrefix+" on the metrics endpoint. Requests must carry the bearer "+
+			"token of a user allowed to get their path as a non resource URL, e.g. by the pprof-reader ClusterRole")
+	flag.Var(&heapProfileThreshold, "heap-profile-threshold", "Heap in use, as a quantity e.g. 512Mi, above which a heap profile is "+
+		"captured, served under "+profiling.CapturedPathPrefix+". Zero disables the capture. Requires --enable-pprof")
+	flag.DurationVar(&heapProfiler.Interval, "heap-profile-interval", time.Second*30,
+		"The time between checks of the heap in use against the heap profile threshold. Requires --heap-profile-threshold")
+	flag.StringVar(&heapProfiler.Dir, "heap-profile-dir", filepath.Join(os.TempDir(), "heap-profiles"),
+		"Directory captured heap profiles are written to. Requires --heap-profile-threshold")
+	flag.IntVar(&heapProfiler.MaxProfiles, "heap-profile-max", 5,
+		"The number of captured heap profiles kept, older profiles are removed. Requires --heap-profile-threshold")
+	flag.StringVar(&vaultConfig.Address, "vault-addr", "",
+		"Address of the Vault server to fetch short-lived DNS Provider credentials from, for provider secrets setting "+
+			v1alpha1.VaultCredentialsPathKey+". The operator logs in with its ServiceAccount token. Empty disables Vault credentials")
+	flag.StringVar(&vaultConfig.AuthPath, "vault-auth-path", "kubernetes",
+		"Mount path of the Vault Kubernetes auth method the operator logs in with. Requires --vault-addr")
+	flag.Var(&vaultBindings, "vault-namespace-binding", "Vault role the operator logs in with, and path the credentials paths "+
+		"must be within, for provider secrets in a namespace, in the form <namespace>=<role>:<path>. Can be passed multiple times e.g. "+
+		"--vault-namespace-binding team-a=dns-team-a:aws/creds/team-a. Namespaces without a binding can not fetch credentials from Vault. "+
+		"Requires --vault-addr")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -102,23 +277,86 @@ func main() {
 		LeaderElectionID:       "a3f98d6c.kuadrant.io",
 	}
 
+	defaultOptions.Cache = cache.Options{
+		DefaultTransform: controller.StripManagedFields,
+		ByObject: map[client.Object]cache.ByObject{
+			&v1.Secret{}: {Transform: controller.StripSecretData},
+		},
+	}
 	if watch := os.Getenv(watchNamespaces); watch != "" {
+		orphanRecordGC.NamespaceScoped = true
 		namespaces := strings.Split(watch, ",")
 		setupLog.Info("watching namespaces set ", watchNamespaces, namespaces)
-		cacheOpts := cache.Options{
-			DefaultNamespaces: map[string]cache.Config{},
-		}
+		defaultOptions.Cache.DefaultNamespaces = map[string]cache.Config{}
 		for _, ns := range namespaces {
-			cacheOpts.DefaultNamespaces[ns] = cache.Config{}
+			defaultOptions.Cache.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
+	switch controller.AdoptionMode(adoption) {
+	case controller.AdoptionDisabled, controller.AdoptionMatching, controller.AdoptionReplace:
+	default:
+		setupLog.Error(fmt.Errorf("unknown mode %q", adoption), "unable to configure record adoption")
+		os.Exit(1)
+	}
+
+	if orphanRecordGC.Delete && ownerIDPrefix == "" {
+		setupLog.Error(fmt.Errorf("--orphan-record-gc-delete requires --owner-id-prefix"), "unable to configure orphan record sweep")
+		os.Exit(1)
+	}
+	if !ownerIDPrefixRegexp.MatchString(ownerIDPrefix) {
+		setupLog.Error(fmt.Errorf("--owner-id-prefix must only contain lower case alphanumeric characters and '-', got '%s'", ownerIDPrefix), "unable to configure owner IDs")
+		os.Exit(1)
+	}
+
+	var readinessSecrets []client.ObjectKey
+	for _, secret := range providerReadinessSecrets {
+		namespace, name, ok := strings.Cut(secret, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("provider secret must be <namespace>/<name>, got '%s'", secret), "unable to configure provider ready check")
+			os.Exit(1)
 		}
-		defaultOptions.Cache = cacheOpts
+		readinessSecrets = append(readinessSecrets, client.ObjectKey{Namespace: namespace, Name: name})
+	}
+	if providerReadinessWindow > 0 && len(readinessSecrets) == 0 {
+		setupLog.Error(fmt.Errorf("--provider-readiness-window requires --provider-readiness-secrets"), "unable to configure provider ready check")
+		os.Exit(1)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), defaultOptions)
+	cfg := ctrl.GetConfigOrDie()
+	if heapProfileThreshold > 0 && !enablePprof {
+		setupLog.Error(fmt.Errorf("--heap-profile-threshold requires --enable-pprof"), "unable to configure profiling")
+		os.Exit(1)
+	}
+	if enablePprof {
+		clientset, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			setupLog.Error(err, "unable to configure profiling")
+			os.Exit(1)
+		}
+		defaultOptions.Metrics.ExtraHandlers = map[string]http.Handler{}
+		for path, handler := range profiling.Handlers(heapProfiler.Dir) {
+			defaultOptions.Metrics.ExtraHandlers[path] = profiling.WithAuthorization(ctrl.Log.WithName("profiling"), clientset, handler)
+		}
+		setupLog.Info("profiling endpoints enabled", "path", profiling.PathPrefix)
+	}
+
+	mgr, err := ctrl.NewManager(cfg, defaultOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if err = metrics.RegisterCacheCollector(mgr.GetCache()); err != nil {
+		setupLog.Error(err, "unable to register cache metrics")
+		os.Exit(1)
+	}
+	if heapProfileThreshold > 0 {
+		heapProfiler.Threshold = uint64(heapProfileThreshold)
+		if err = mgr.Add(&heapProfiler); err != nil {
+			setupLog.Error(err, "unable to add heap profiler")
+			os.Exit(1)
+		}
+	}
 
 	if len(providers) == 0 {
 		defaultProviders := provider.RegisteredDefaultProviders()
@@ -129,22 +367,105 @@ func main() {
 		providers = defaultProviders
 	}
 
+	if vaultConfig.Address != "" {
+		vaultConfig.Bindings = vaultBindings
+		setupLog.Info("provider credentials can be fetched from Vault", "address", vaultConfig.Address, "authPath", vaultConfig.AuthPath,
+			"namespaces", len(vaultBindings))
+		provider.ConfigureVault(vaultConfig)
+	}
+
+	provider.ConfigureRequestCosts(requestCosts)
+
 	setupLog.Info("init provider factory", "providers", providers)
-	providerFactory, err := provider.NewFactory(mgr.GetClient(), providers)
+	var providerFactory provider.Factory
+	if impersonateServiceAccount != "" {
+		setupLog.Info("provider secrets will be read impersonating service account", "serviceAccount", impersonateServiceAccount)
+		providerFactory, err = provider.NewImpersonatingFactory(mgr.GetClient(), providers, mgr.GetConfig(), impersonateServiceAccount)
+	} else {
+		providerFactory, err = provider.NewFactory(mgr.GetClient(), providers)
+	}
 	if err != nil {
 		setupLog.Error(err, "unable to create provider factory")
 		os.Exit(1)
 	}
 
+	if enableFaultInjection {
+		faultInjectionConfig.Zones = faultInjectionZones
+		setupLog.Info("fault injection enabled, DNS provider writes will be disrupted", "config", faultInjectionConfig)
+		providerFactory = provider.NewFaultInjectingFactory(providerFactory, faultInjectionConfig)
+	}
+
+	var registrySigningKey []byte
+	if registrySigningKeyFile != "" {
+		registrySigningKey, err = os.ReadFile(registrySigningKeyFile)
+		if err == nil && len(registrySigningKey) == 0 {
+			err = fmt.Errorf("registry signing key is empty")
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to read registry signing key", "file", registrySigningKeyFile)
+			os.Exit(1)
+		}
+	}
+
+	zoneLimits := map[string]controller.ZoneLimits{}
+	for zone, interval := range zoneMinValidationIntervals {
+		limits := zoneLimits[zone]
+		limits.MinValidationInterval = interval
+		zoneLimits[zone] = limits
+	}
+	for _, zone := range zonesWithoutHealthChecks {
+		zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+		limits := zoneLimits[zone]
+		limits.DisableHealthChecks = true
+		zoneLimits[zone] = limits
+	}
+	for _, zone := range zonesRejectingWildcards {
+		zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+		limits := zoneLimits[zone]
+		limits.RejectWildcards = true
+		zoneLimits[zone] = limits
+	}
+
 	if err = (&controller.DNSRecordReconciler{
-		Client:          mgr.GetClient(),
-		Scheme:          mgr.GetScheme(),
-		ProviderFactory: providerFactory,
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		ProviderFactory:          providerFactory,
+		PublishSLO:               publishSLO,
+		EnforceHostClaims:        enforceHostClaims,
+		ZoneLimits:               zoneLimits,
+		ManagedRecordTypes:       managedRecordTypes,
+		RoutingChangeDampening:   routingChangeDampening,
+		RegistrySigningKey:       registrySigningKey,
+		RegistrySigningMigration: registrySigningMigration,
+		ChurnLimits:              churnLimits,
+		Recorder:                 mgr.GetEventRecorderFor("dnsrecord-controller"),
+		VerifyZoneDelegation:     verifyZoneDelegation,
+		VerifyPropagation:        verifyPropagation,
+		ShadowMode:               shadowMode,
+		DryRun:                   dryRun,
+		ExcludedTargetCIDRs:      excludedTargetCIDRs,
+		OrphanRecordGC:           orphanRecordGC,
+		OwnerIDPrefix:            ownerIDPrefix,
+		Adoption:                 controller.AdoptionMode(adoption),
+		AdoptionNamespaces:       adoptionNamespaces,
 	}).SetupWithManager(mgr, maxRequeueTime, validFor, minRequeueTime); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DNSRecord")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		webhookRecordTypes := managedRecordTypes
+		if len(webhookRecordTypes) == 0 {
+			webhookRecordTypes = controller.DefaultManagedRecordTypes
+		}
+		if err = (&dnswebhook.DNSRecordValidator{
+			Client:             mgr.GetClient(),
+			ManagedRecordTypes: webhookRecordTypes,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DNSRecord")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -155,6 +476,22 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if providerReadinessWindow > 0 {
+		if err := mgr.AddReadyzCheck("providers", provider.ReadyzCheck(readinessSecrets, providerReadinessWindow)); err != nil {
+			setupLog.Error(err, "unable to set up provider ready check")
+			os.Exit(1)
+		}
+		// list zones with the secrets several times per window, so a single failed list does not make the replica unready
+		readinessProber := &provider.ReadinessProber{
+			Factory:  providerFactory,
+			Secrets:  readinessSecrets,
+			Interval: providerReadinessWindow / 3,
+		}
+		if err := mgr.Add(readinessProber); err != nil {
+			setupLog.Error(err, "unable to add provider readiness prober")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -183,3 +520,449 @@ func (n *stringSliceFlags) Set(s string) error {
 	}
 	return nil
 }
+
+type zoneDurationFlags map[string]time.Duration
+
+func (n *zoneDurationFlags) String() string {
+	var values []string
+	for zone, d := range *n {
+		values = append(values, zone+"="+d.String())
+	}
+	return strings.Join(values, ",")
+}
+
+func (n *zoneDurationFlags) Set(s string) error {
+	zone, value, ok := strings.Cut(s, "=")
+	if !ok || zone == "" {
+		return fmt.Errorf("expected <zone domain name>=<duration>, got '%s'", s)
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if *n == nil {
+		*n = zoneDurationFlags{}
+	}
+	(*n)[strings.ToLower(strings.TrimSuffix(zone, "."))] = d
+	return nil
+}
+
+type requestCostFlags map[string]map[string]float64
+
+func (n *requestCostFlags) String() string {
+	var values []string
+	for name, costs := range *n {
+		for operation, cost := range costs {
+			values = append(values, name+"/"+operation+"="+strconv.FormatFloat(cost, 'f', -1, 64))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+func (n *requestCostFlags) Set(s string) error {
+	key, value, _ := strings.Cut(s, "=")
+	name, operation, _ := strings.Cut(key, "/")
+	if name == "" || operation == "" || value == "" {
+		return fmt.Errorf("expected <provider>/<operation>=<cost>, got '%s'", s)
+	}
+	cost, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	if cost < 0 {
+		return fmt.Errorf("cost must not be negative, got '%s'", s)
+	}
+	if *n == nil {
+		*n = requestCostFlags{}
+	}
+	if (*n)[name] == nil {
+		(*n)[name] = map[string]float64{}
+	}
+	(*n)[name][operation] = cost
+	return nil
+}
+
+type vaultBindingFlags map[string]provider.VaultBinding
+
+func (n *vaultBindingFlags) String() string {
+	var values []string
+	for namespace, b := range *n {
+		values = append(values, namespace+"="+b.Role+":"+b.PathPrefix)
+	}
+	return strings.Join(values, ",")
+}
+
+func (n *vaultBindingFlags) Set(s string) error {
+	namespace, value, _ := strings.Cut(s, "=")
+	role, path, _ := strings.Cut(value, ":")
+	path = strings.Trim(path, "/")
+	if namespace == "" || role == "" || path == "" {
+		return fmt.Errorf("expected <namespace>=<role>:<path>, got '%s'", s)
+	}
+	if *n == nil {
+		*n = vaultBindingFlags{}
+	}
+	(*n)[namespace] = provider.VaultBinding{Role: role, PathPrefix: path}
+	return nil
+}
+
+type cidrFlags []netip.Prefix
+
+func (n *cidrFlags) String() string {
+	var values []string
+	for _, prefix := range *n {
+		values = append(values, prefix.String())
+	}
+	return strings.Join(values, ",")
+}
+
+func (n *cidrFlags) Set(s string) error {
+	for _, value := range strings.Split(s, ",") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		*n = append(*n, prefix.Masked())
+	}
+	return nil
+}
+
+// bytesFlag is a number of bytes set from a quantity, e.g. 512Mi
+type bytesFlag uint64
+
+func (n *bytesFlag) String() string {
+	return resource.NewQuantity(int64(*n), resource.BinarySI).String()
+}
+
+func (n *bytesFlag) Set(s string) error {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	if q.Sign() < 0 {
+		return fmt.Errorf("cannot be negative")
+	}
+	*n = bytesFlag(q.Value())
+	return nil
+}
+
+// validate runs the validate subcommand, validating the DNSRecords in the given manifest files without a cluster.
+// Returns the exit code, non zero if any DNSRecord is invalid.
+func validate(args []string) int {
+	var managedRecordTypes stringSliceFlags
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate [flags] <file>... (- reads from stdin)\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Var(&managedRecordTypes, "managed-record-types", fmt.Sprintf("Record types managed in DNS Provider zones. "+
+		"Can be passed multiple times or as a comma separated list. Defaults to %s", strings.Join(controller.DefaultManagedRecordTypes, ",")))
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if len(managedRecordTypes) == 0 {
+		managedRecordTypes = controller.DefaultManagedRecordTypes
+	}
+
+	exitCode := 0
+	for _, path := range fs.Args() {
+		if err := validateFile(path, managedRecordTypes); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// validateFile validates the DNSRecords in the manifest file at the given path, or stdin if path is "-"
+func validateFile(path string, managedRecordTypes []string) error {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	dnsRecords, err := validation.Decode(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var errs []error
+	for _, dnsRecord := range dnsRecords {
+		if err = validation.Validate(dnsRecord, managedRecordTypes); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s/%s: %w", path, dnsRecord.Namespace, dnsRecord.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// importZone runs the import subcommand, writing DNSRecord manifests for the records of an existing zone, read from a
+// zone file or the DNS provider, to stdout. Returns the exit code, non zero if the zone could not be read.
+func importZone(args []string) int {
+	var zoneDomainName, zoneFile, providerRef string
+	var providers, managedRecordTypes stringSliceFlags
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s import --zone <domain> --provider-ref <namespace>/<name> [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&zoneDomainName, "zone", "", "Domain name of the zone to import")
+	fs.StringVar(&zoneFile, "zone-file", "", "RFC 1035 zone file to read the zone from, - reads from stdin. "+
+		"If not set the zone is read from the DNS provider of --provider-ref, using the current kubeconfig context")
+	fs.StringVar(&providerRef, "provider-ref", "", "DNS provider secret, as <namespace>/<name>, referenced by the DNSRecords. "+
+		"DNSRecords are created in the namespace of the secret")
+	fs.Var(&providers, "provider", "DNS Provider(s) to enable when reading the zone from the DNS provider. "+
+		"Can be passed multiple times or as a comma separated list. Defaults to the default providers")
+	fs.Var(&managedRecordTypes, "managed-record-types", fmt.Sprintf("Record types managed in DNS Provider zones. "+
+		"Can be passed multiple times or as a comma separated list. Defaults to %s", strings.Join(controller.DefaultManagedRecordTypes, ",")))
+	_ = fs.Parse(args)
+	namespace, secret, ok := strings.Cut(providerRef, "/")
+	if zoneDomainName == "" || !ok || namespace == "" || secret == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if len(managedRecordTypes) == 0 {
+		managedRecordTypes = controller.DefaultManagedRecordTypes
+	}
+
+	endpoints, err := readZone(ctrl.SetupSignalHandler(), zoneDomainName, zoneFile, client.ObjectKey{Namespace: namespace, Name: secret}, providers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, dnsRecord := range zone.DNSRecords(zoneDomainName, namespace, secret, zone.Records(zoneDomainName, endpoints, managedRecordTypes)) {
+		// invalid DNSRecords are still written, to be fixed on review
+		if err = validation.Validate(dnsRecord, managedRecordTypes); err != nil {
+			fmt.Fprintf(os.Stderr, "%s/%s: %v\n", dnsRecord.Namespace, dnsRecord.Name, err)
+		}
+		manifest, err := marshalManifest(dnsRecord)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("---\n%s", manifest)
+	}
+	return 0
+}
+
+// marshalManifest returns the YAML manifest of the given object, without its status and server set metadata
+func marshalManifest(obj runtime.Object) ([]byte, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u, "status")
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	return yaml.Marshal(u)
+}
+
+// readZone returns the records of the zone with the given domain name, read from the given zone file, or from the DNS
+// provider of the given provider secret if zoneFile is empty
+func readZone(ctx context.Context, zoneDomainName, zoneFile string, providerRef client.ObjectKey, providers []string) ([]*externaldnsendpoint.Endpoint, error) {
+	if zoneFile == "" {
+		dnsProvider, err := zoneProvider(ctx, zoneDomainName, providerRef, providers)
+		if err != nil {
+			return nil, err
+		}
+		return dnsProvider.Records(ctx)
+	}
+
+	in := os.Stdin
+	if zoneFile != "-" {
+		f, err := os.Open(zoneFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	endpoints, err := zone.ParseFile(in, zoneDomainName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", zoneFile, err)
+	}
+	return endpoints, nil
+}
+
+// zoneProvider returns the DNS provider of the given provider secret for the zone with the given domain name, using the
+// current kubeconfig context to read the secret
+func zoneProvider(ctx context.Context, zoneDomainName string, providerRef client.ObjectKey, providers []string) (provider.Provider, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	if len(providers) == 0 {
+		providers = provider.RegisteredDefaultProviders()
+	}
+	providerFactory, err := provider.NewFactory(c, providers)
+	if err != nil {
+		return nil, err
+	}
+	accessor := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Namespace: providerRef.Namespace},
+		Spec:       v1alpha1.DNSRecordSpec{ProviderRef: v1alpha1.ProviderRef{Name: providerRef.Name}},
+	}
+	domainFilter := externaldnsendpoint.NewDomainFilter([]string{zoneDomainName})
+	dnsProvider, err := providerFactory.ProviderFor(ctx, accessor, provider.Config{DomainFilter: domainFilter})
+	if err != nil {
+		return nil, err
+	}
+	zones, err := dnsProvider.DNSZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zones = slices.DeleteFunc(zones, func(z provider.DNSZone) bool {
+		return !strings.EqualFold(strings.TrimSuffix(z.DNSName, "."), strings.TrimSuffix(zoneDomainName, "."))
+	})
+	if len(zones) != 1 {
+		return nil, fmt.Errorf("found %d zones %s in the DNS provider, expected 1", len(zones), zoneDomainName)
+	}
+	// records of any sub zones are excluded by reading the zone by its ID
+	return providerFactory.ProviderFor(ctx, accessor, provider.Config{
+		DomainFilter:   domainFilter,
+		ZoneTypeFilter: externaldnsprovider.NewZoneTypeFilter(""),
+		ZoneIDFilter:   externaldnsprovider.NewZoneIDFilter([]string{zones[0].ID}),
+	})
+}
+
+// diffZone runs the diff subcommand, writing the changes that publishing the DNSRecords of the cluster, or of the given
+// manifest files, would make to an existing zone, read from a zone file or the DNS provider. Returns the exit code, 0 if
+// there are no changes, 1 if there are and 2 on error.
+func diffZone(args []string) int {
+	var zoneDomainName, zoneFile, providerRef, namespace, ownerIDPrefix string
+	var manifests, providers, managedRecordTypes stringSliceFlags
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s diff --zone <domain> (--zone-file <file> | --provider-ref <namespace>/<name>) [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&zoneDomainName, "zone", "", "Domain name of the zone to diff")
+	fs.StringVar(&zoneFile, "zone-file", "", "RFC 1035 zone file to read the zone from, - reads from stdin. "+
+		"If not set the zone is read from the DNS provider of --provider-ref, using the current kubeconfig context")
+	fs.StringVar(&providerRef, "provider-ref", "", "DNS provider secret, as <namespace>/<name>, to read the zone from")
+	fs.Var(&manifests, "filename", "DNSRecord manifest file(s) to diff, - reads from stdin. "+
+		"Can be passed multiple times or as a comma separated list. If not set the DNSRecords of the cluster are diffed")
+	fs.StringVar(&namespace, "namespace", "", "Namespace of the DNSRecords of the cluster to diff. Defaults to all namespaces")
+	fs.Var(&providers, "provider", "DNS Provider(s) to enable when reading the zone from the DNS provider. "+
+		"Can be passed multiple times or as a comma separated list. Defaults to the default providers")
+	fs.Var(&managedRecordTypes, "managed-record-types", fmt.Sprintf("Record types managed in DNS Provider zones. "+
+		"Can be passed multiple times or as a comma separated list. Defaults to %s", strings.Join(controller.DefaultManagedRecordTypes, ",")))
+	fs.StringVar(&ownerIDPrefix, "owner-id-prefix", "", "Prefix of the owner IDs of the operator instances managing the zone. "+
+		"Records no longer in any DNSRecord are only listed as deleted if all their owners are owners of the DNSRecords diffed or have this prefix")
+	_ = fs.Parse(args)
+	secretNamespace, secret, _ := strings.Cut(providerRef, "/")
+	if zoneDomainName == "" || (zoneFile == "" && (secretNamespace == "" || secret == "")) || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if len(managedRecordTypes) == 0 {
+		managedRecordTypes = controller.DefaultManagedRecordTypes
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	dnsRecords, err := readDNSRecords(ctx, manifests, namespace)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	desired := common.NormalizeEndpoints(zone.Desired(zoneDomainName, dnsRecords))
+	ownerIDs := map[string]bool{}
+	for _, dnsRecord := range dnsRecords {
+		// DNSRecords without an owner ID are owned by the hash of their UID, once created
+		if dnsRecord.UID != "" {
+			ownerIDs[dnsRecord.GetUIDHash()] = true
+		}
+		if dnsRecord.Spec.OwnerID != "" {
+			ownerIDs[dnsRecord.Spec.OwnerID] = true
+		}
+		if dnsRecord.Status.OwnerID != "" {
+			ownerIDs[dnsRecord.Status.OwnerID] = true
+		}
+	}
+	isOperatorOwner := func(ownerID string) bool {
+		return ownerIDs[ownerID] || (ownerIDPrefix != "" && strings.HasPrefix(ownerID, ownerIDPrefix))
+	}
+
+	// records of zone files have no owners, so are never listed as deleted
+	var current []*externaldnsendpoint.Endpoint
+	if zoneFile != "" {
+		current, err = readZone(ctx, zoneDomainName, zoneFile, client.ObjectKey{}, nil)
+	} else {
+		// desired endpoints are adjusted to the provider, e.g. for provider specific routing properties
+		var dnsProvider provider.Provider
+		if dnsProvider, err = zoneProvider(ctx, zoneDomainName, client.ObjectKey{Namespace: secretNamespace, Name: secret}, providers); err == nil {
+			if desired, err = dnsProvider.AdjustEndpoints(desired); err == nil {
+				current, err = controller.RegistryRecords(ctx, dnsProvider, managedRecordTypes)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	changes := zone.Plan(
+		common.NormalizeEndpoints(zone.Records(zoneDomainName, current, managedRecordTypes)),
+		zone.Records(zoneDomainName, desired, managedRecordTypes),
+		isOperatorOwner,
+	)
+	if err = zone.WritePlan(os.Stdout, changes); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if changes.HasChanges() {
+		return 1
+	}
+	return 0
+}
+
+// readDNSRecords returns the DNSRecords of the given manifest files, or if none are given, the DNSRecords of the
+// cluster in the given namespace, using the current kubeconfig context. DNSRecords being deleted are excluded.
+func readDNSRecords(ctx context.Context, manifests []string, namespace string) ([]*v1alpha1.DNSRecord, error) {
+	var dnsRecords []*v1alpha1.DNSRecord
+	for _, path := range manifests {
+		in := os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			in = f
+		}
+		decoded, err := validation.Decode(in)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		dnsRecords = append(dnsRecords, decoded...)
+	}
+	if len(manifests) > 0 {
+		return dnsRecords, nil
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	dnsRecordList := &v1alpha1.DNSRecordList{}
+	if err = c.List(ctx, dnsRecordList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range dnsRecordList.Items {
+		if dnsRecordList.Items[i].DeletionTimestamp == nil {
+			dnsRecords = append(dnsRecords, &dnsRecordList.Items[i])
+		}
+	}
+	return dnsRecords, nil
+}
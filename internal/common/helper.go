@@ -1,9 +1,12 @@
 package common
 
 import (
+	"net/netip"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/rand"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
 )
 
 // RandomizeDuration randomizes duration for a given variance.
@@ -23,3 +26,38 @@ func RandomizeDuration(variance float64, duration time.Duration) time.Duration {
 		int64(lowerLimit),
 		int64(upperLimit)))
 }
+
+// NormalizeEndpoints returns copies of the given endpoints with normalized, de-duplicated targets.
+// IP address targets of A and AAAA records are converted to their canonical form and CNAME targets are lower cased,
+// duplicate targets are then removed keeping the first occurrence.
+func NormalizeEndpoints(endpoints []*externaldnsendpoint.Endpoint) []*externaldnsendpoint.Endpoint {
+	normalized := make([]*externaldnsendpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		ep = ep.DeepCopy()
+		seen := map[string]bool{}
+		targets := externaldnsendpoint.Targets{}
+		for _, target := range ep.Targets {
+			target = normalizeTarget(ep.RecordType, target)
+			if seen[target] {
+				continue
+			}
+			seen[target] = true
+			targets = append(targets, target)
+		}
+		ep.Targets = targets
+		normalized = append(normalized, ep)
+	}
+	return normalized
+}
+
+func normalizeTarget(recordType, target string) string {
+	switch recordType {
+	case externaldnsendpoint.RecordTypeA, externaldnsendpoint.RecordTypeAAAA:
+		if addr, err := netip.ParseAddr(target); err == nil {
+			return addr.String()
+		}
+	case externaldnsendpoint.RecordTypeCNAME:
+		return strings.ToLower(target)
+	}
+	return target
+}
@@ -3,8 +3,11 @@
 package common
 
 import (
+	"reflect"
 	"testing"
 	"time"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
 )
 
 func TestRandomizeDuration(t *testing.T) {
@@ -41,3 +44,81 @@ func isValidVariance(duration, randomizedDuration time.Duration, variance float6
 	return float64(randomizedDuration.Milliseconds()) >= lowerLimmit &&
 		float64(randomizedDuration.Milliseconds()) < upperLimit
 }
+
+func TestNormalizeEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []*externaldnsendpoint.Endpoint
+		want      []*externaldnsendpoint.Endpoint
+	}{
+		{
+			name: "removes duplicate A targets",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1", "127.0.0.2", "127.0.0.1"),
+			},
+			want: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1", "127.0.0.2"),
+			},
+		},
+		{
+			name: "removes AAAA targets duplicated after conversion to canonical form",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeAAAA, "2001:DB8::1", "2001:db8:0:0:0:0:0:1"),
+			},
+			want: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeAAAA, "2001:db8::1"),
+			},
+		},
+		{
+			name: "removes CNAME targets duplicated after lower casing",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeCNAME, "LB.example.com", "lb.example.com"),
+			},
+			want: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeCNAME, "lb.example.com"),
+			},
+		},
+		{
+			name: "does not change TXT targets",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeTXT, "Foo", "foo"),
+			},
+			want: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeTXT, "Foo", "foo"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeEndpoints(tt.endpoints); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzNormalizeEndpoints(f *testing.F) {
+	f.Add(externaldnsendpoint.RecordTypeA, "127.0.0.1", "127.0.0.1")
+	f.Add(externaldnsendpoint.RecordTypeAAAA, "2001:DB8::1", "2001:db8:0:0:0:0:0:1")
+	f.Add(externaldnsendpoint.RecordTypeCNAME, "LB.example.com", "lb.example.com")
+	f.Add(externaldnsendpoint.RecordTypeTXT, "foo", "FOO")
+
+	f.Fuzz(func(t *testing.T, recordType, target1, target2 string) {
+		endpoints := []*externaldnsendpoint.Endpoint{externaldnsendpoint.NewEndpoint("foo.example.com", recordType, target1, target2)}
+		original := endpoints[0].DeepCopy()
+		normalized := NormalizeEndpoints(endpoints)
+		if !reflect.DeepEqual(NormalizeEndpoints(normalized), normalized) {
+			t.Errorf("NormalizeEndpoints() is not idempotent for %v", normalized)
+		}
+		seen := map[string]bool{}
+		for _, target := range normalized[0].Targets {
+			if seen[target] {
+				t.Errorf("NormalizeEndpoints() returned duplicate target %q", target)
+			}
+			seen[target] = true
+		}
+		if !reflect.DeepEqual(endpoints[0], original) {
+			t.Errorf("NormalizeEndpoints() modified the given endpoints")
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// StripManagedFields is a cache transform dropping the managed fields of objects, which are never read by the
+// controller, reducing the memory held by the informer caches.
+func StripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+// StripSecretData is a cache transform for secrets, dropping the managed fields of all secrets and the data of those
+// that aren't DNS provider secrets. Only DNS provider secrets are ever read, while every secret in the watched
+// namespaces is cached.
+func StripSecretData(obj interface{}) (interface{}, error) {
+	if s, ok := obj.(*v1.Secret); ok && !strings.HasPrefix(string(s.Type), "kuadrant.io") {
+		s.Data = nil
+		s.StringData = nil
+	}
+	return StripManagedFields(obj)
+}
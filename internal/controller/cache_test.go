@@ -0,0 +1,53 @@
+//go:build unit
+
+package controller
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestStripSecretData(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   *v1.Secret
+		wantData bool
+	}{
+		{
+			name: "provider secret",
+			secret: &v1.Secret{
+				Type: v1alpha1.SecretTypeKuadrantAWS,
+				Data: map[string][]byte{v1alpha1.AWSAccessKeyIDKey: []byte("id")},
+			},
+			wantData: true,
+		},
+		{
+			name: "other secret",
+			secret: &v1.Secret{
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{"password": []byte("secret")},
+			},
+			wantData: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.secret.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+			obj, err := StripSecretData(tt.secret)
+			if err != nil {
+				t.Fatalf("StripSecretData() error = %v", err)
+			}
+			s := obj.(*v1.Secret)
+			if s.ManagedFields != nil {
+				t.Errorf("StripSecretData() managed fields = %v, want nil", s.ManagedFields)
+			}
+			if (s.Data != nil) != tt.wantData {
+				t.Errorf("StripSecretData() data = %v, wantData %v", s.Data, tt.wantData)
+			}
+		})
+	}
+}
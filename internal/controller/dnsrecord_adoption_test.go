@@ -0,0 +1,136 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	externaldnsplan "github.com/kuadrant/dns-operator/internal/external-dns/plan"
+	"github.com/kuadrant/dns-operator/internal/external-dns/provider/inmemory"
+	providerinmemory "github.com/kuadrant/dns-operator/internal/provider/inmemory"
+)
+
+func TestApplyChangesAdoption(t *testing.T) {
+	tests := []struct {
+		name       string
+		reconciler *DNSRecordReconciler
+		namespace  string
+		target     string
+		// unowned TXT record claiming ownership of the record, without a signature
+		unsignedOwner bool
+		wantAdopted   bool
+	}{
+		{
+			name:       "disabled",
+			reconciler: &DNSRecordReconciler{},
+			target:     "127.0.0.2",
+		},
+		{
+			name:        "replace",
+			reconciler:  &DNSRecordReconciler{Adoption: AdoptionReplace},
+			target:      "127.0.0.2",
+			wantAdopted: true,
+		},
+		{
+			name:       "matching with differing targets",
+			reconciler: &DNSRecordReconciler{Adoption: AdoptionMatching},
+			target:     "127.0.0.2",
+		},
+		{
+			name:        "matching with matching targets",
+			reconciler:  &DNSRecordReconciler{Adoption: AdoptionMatching},
+			target:      "127.0.0.1",
+			wantAdopted: true,
+		},
+		{
+			name:        "allowed namespace",
+			reconciler:  &DNSRecordReconciler{Adoption: AdoptionReplace, AdoptionNamespaces: []string{"default"}},
+			target:      "127.0.0.2",
+			wantAdopted: true,
+		},
+		{
+			name:       "namespace not allowed",
+			reconciler: &DNSRecordReconciler{Adoption: AdoptionReplace, AdoptionNamespaces: []string{"other"}},
+			target:     "127.0.0.2",
+		},
+		{
+			name:          "unsigned ownership record",
+			reconciler:    &DNSRecordReconciler{Adoption: AdoptionReplace, RegistrySigningKey: []byte("cluster-key")},
+			target:        "127.0.0.2",
+			unsignedOwner: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := &providerinmemory.InMemoryDNSProvider{
+				InMemoryProvider: inmemory.NewInMemoryProvider(ctx, inmemory.InMemoryInitZones([]string{"example.com"})),
+			}
+			// a record created outside of the operator
+			existing := []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			}
+			if tt.unsignedOwner {
+				existing = append(existing, externaldnsendpoint.NewEndpoint("kuadrant-a-foo.example.com", externaldnsendpoint.RecordTypeTXT,
+					"\"heritage=external-dns,external-dns/owner=other\""))
+			}
+			if err := p.ApplyChanges(ctx, &plan.Changes{Create: existing}); err != nil {
+				t.Fatalf("ApplyChanges() error = %v", err)
+			}
+
+			dnsRecord := &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "default",
+					Annotations: map[string]string{v1alpha1.AdoptRecordsAnnotation: "true"},
+				},
+				Spec: v1alpha1.DNSRecordSpec{
+					RootHost: "foo.example.com",
+					Endpoints: []*externaldnsendpoint.Endpoint{
+						externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, tt.target),
+					},
+				},
+				Status: v1alpha1.DNSRecordStatus{OwnerID: "owner", ZoneID: "example.com", ZoneDomainName: "example.com"},
+			}
+
+			_, err := tt.reconciler.applyChanges(ctx, dnsRecord, p, false)
+			if tt.wantAdopted && err != nil {
+				t.Fatalf("applyChanges() error = %v, want records adopted", err)
+			}
+			if !tt.wantAdopted && !errors.Is(err, externaldnsplan.ErrOwnerConflict) {
+				t.Fatalf("applyChanges() error = %v, want %v", err, externaldnsplan.ErrOwnerConflict)
+			}
+
+			registry, err := tt.reconciler.newRegistry(ctx, p, "owner", DefaultManagedRecordTypes, nil)
+			if err != nil {
+				t.Fatalf("newRegistry() error = %v", err)
+			}
+			records, err := registry.Records(ctx)
+			if err != nil {
+				t.Fatalf("Records() error = %v", err)
+			}
+			for _, record := range records {
+				if record.DNSName != "foo.example.com" {
+					continue
+				}
+				wantTarget, wantOwner := "127.0.0.1", ""
+				if tt.wantAdopted {
+					wantTarget, wantOwner = tt.target, "owner"
+				}
+				if got := record.Targets.String(); got != wantTarget {
+					t.Errorf("applyChanges() targets = %v, want %v", got, wantTarget)
+				}
+				if got := record.Labels[externaldnsendpoint.OwnerLabelKey]; got != wantOwner {
+					t.Errorf("applyChanges() owner = %q, want %q", got, wantOwner)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/pkg/diff"
+)
+
+// recordPublishedChange adds the changes just published for the given DNSRecord, and their cause, to its status,
+// keeping the most recent v1alpha1.MaxPublishedChanges. The given previous DNSRecord is the record before the reconcile,
+// the triggers of the changes are found from. It must be called before the status endpoints are updated to the
+// published endpoints.
+func (r *DNSRecordReconciler) recordPublishedChange(previous, dnsRecord *v1alpha1.DNSRecord) {
+	cause := changeCause(dnsRecord, publishTriggers(previous, dnsRecord, r.dampenedPublish(dnsRecord)))
+	if len(cause) > v1alpha1.MaxChangeCauseLength {
+		cause = cause[:v1alpha1.MaxChangeCauseLength-3] + "..."
+	}
+	change := v1alpha1.PublishedChange{
+		Time:       reconcileStart,
+		Generation: dnsRecord.Generation,
+		Cause:      cause,
+	}
+	changes := append([]v1alpha1.PublishedChange{change}, dnsRecord.Status.PublishedChanges...)
+	if len(changes) > v1alpha1.MaxPublishedChanges {
+		changes = changes[:v1alpha1.MaxPublishedChanges]
+	}
+	dnsRecord.Status.PublishedChanges = changes
+}
+
+// publishTriggers returns what, other than the spec, changed the endpoints published for the given DNSRecord since the
+// given previous DNSRecord, the record before the reconcile, was published: the target override being applied,
+// expiring or removed, the targets within the excluded CIDRs changing, the zone freeze being lifted, or the publish of a
+// dampened routing change.
+func publishTriggers(previous, dnsRecord *v1alpha1.DNSRecord, dampened bool) []string {
+	var triggers []string
+	if ready := meta.FindStatusCondition(previous.Status.Conditions, string(v1alpha1.ConditionTypeReady)); ready != nil && ready.Reason == "ZoneFrozen" {
+		triggers = append(triggers, "zone freeze lifted")
+	}
+	if dampened {
+		triggers = append(triggers, "routing change stable")
+	}
+
+	wasOverridden := meta.FindStatusCondition(previous.Status.Conditions, string(v1alpha1.ConditionTypeOverridden))
+	overridden := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeOverridden))
+	wasActive := wasOverridden != nil && wasOverridden.Status == metav1.ConditionTrue
+	switch {
+	case overridden != nil && overridden.Status == metav1.ConditionTrue && (!wasActive || wasOverridden.Message != overridden.Message):
+		triggers = append(triggers, "target override applied")
+	case overridden != nil && overridden.Reason == "OverrideExpired" && wasActive:
+		triggers = append(triggers, "target override expired")
+	case overridden == nil && wasActive:
+		triggers = append(triggers, "target override removed")
+	}
+
+	if !generationChanged(dnsRecord) && conditionMessage(previous, v1alpha1.ConditionTypeTargetsExcluded) !=
+		conditionMessage(dnsRecord, v1alpha1.ConditionTypeTargetsExcluded) {
+		triggers = append(triggers, "excluded targets changed")
+	}
+	return triggers
+}
+
+// conditionMessage returns the message of the given condition of the given DNSRecord, or an empty string if not set
+func conditionMessage(dnsRecord *v1alpha1.DNSRecord, conditionType v1alpha1.ConditionType) string {
+	if condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(conditionType)); condition != nil {
+		return condition.Message
+	}
+	return ""
+}
+
+// changeCause returns the cause of the changes published for the given DNSRecord, triggered by the given triggers.
+// That is the change cause annotation, if set on a new generation, otherwise a summary of the differences between the
+// published and spec endpoints, prefixed by the triggers. Changes of the same generation without triggers restore the
+// provider zone records to the published endpoints.
+func changeCause(dnsRecord *v1alpha1.DNSRecord, triggers []string) string {
+	var cause string
+	if generationChanged(dnsRecord) {
+		cause = dnsRecord.Annotations[v1alpha1.ChangeCauseAnnotation]
+	}
+	if cause == "" {
+		cause = summarizeChanges(dnsRecord)
+	}
+	switch {
+	case len(triggers) == 0 && !generationChanged(dnsRecord):
+		return "Provider zone records restored to the published endpoints"
+	case cause == "" && generationChanged(dnsRecord):
+		cause = fmt.Sprintf("Generation %d published", dnsRecord.Generation)
+	}
+	if len(triggers) == 0 {
+		return cause
+	}
+	if cause == "" {
+		return strings.Join(triggers, ", ")
+	}
+	return strings.Join(triggers, ", ") + ": " + cause
+}
+
+// summarizeChanges returns a summary of the differences between the published and spec endpoints of the given
+// DNSRecord, or an empty string if they do not differ
+func summarizeChanges(dnsRecord *v1alpha1.DNSRecord) string {
+	changes := diff.Endpoints(dnsRecord.Status.Endpoints, dnsRecord.Spec.Endpoints)
+	var summary []string
+	for _, ep := range changes.Create {
+		summary = append(summary, "created "+endpointName(ep))
+	}
+	for _, u := range changes.Update {
+		var fields []string
+		if u.TargetsChanged {
+			fields = append(fields, "targets")
+		}
+		if u.TTLChanged {
+			fields = append(fields, "TTL")
+		}
+		if u.ProviderSpecificChanged {
+			fields = append(fields, "provider specific")
+		}
+		if u.LabelsChanged {
+			fields = append(fields, "labels")
+		}
+		summary = append(summary, fmt.Sprintf("updated %s of %s", strings.Join(fields, ", "), endpointName(u.Desired)))
+	}
+	for _, ep := range changes.Delete {
+		summary = append(summary, "deleted "+endpointName(ep))
+	}
+	return strings.Join(summary, "; ")
+}
+
+// removeChangeCause removes the change cause annotation of the given DNSRecord, once the generation it was set on is
+// published, so it is not recorded as the cause of later generations. The removal fails if the record was updated
+// since, leaving the annotation of a newer generation in place.
+func (r *DNSRecordReconciler) removeChangeCause(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) error {
+	patch := client.MergeFromWithOptions(dnsRecord.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	delete(dnsRecord.Annotations, v1alpha1.ChangeCauseAnnotation)
+	return r.Patch(ctx, dnsRecord, patch)
+}
+
+// endpointName describes an endpoint in change causes and divergence reports
+func endpointName(ep *externaldnsendpoint.Endpoint) string {
+	name := ep.RecordType + " " + ep.DNSName
+	if ep.SetIdentifier != "" {
+		name += " (" + ep.SetIdentifier + ")"
+	}
+	return name
+}
@@ -0,0 +1,236 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestChangeCause(t *testing.T) {
+	published := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("bar.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+	}
+	spec := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 300, "127.0.0.2"),
+		externaldnsendpoint.NewEndpointWithTTL("baz.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		generation  int64
+		spec        []*externaldnsendpoint.Endpoint
+		triggers    []string
+		want        string
+	}{
+		{
+			name:       "summary of endpoint changes",
+			generation: 2,
+			want:       "created A baz.example.com; updated targets, TTL of A foo.example.com; deleted A bar.example.com",
+		},
+		{
+			name:        "change cause annotation",
+			annotations: map[string]string{v1alpha1.ChangeCauseAnnotation: "gateway default/prod address changed"},
+			generation:  2,
+			want:        "gateway default/prod address changed",
+		},
+		{
+			name:       "new generation without endpoint changes",
+			generation: 2,
+			spec:       published,
+			want:       "Generation 2 published",
+		},
+		{
+			name:        "new generation with triggers",
+			annotations: map[string]string{v1alpha1.ChangeCauseAnnotation: "gateway default/prod address changed"},
+			generation:  2,
+			triggers:    []string{"zone freeze lifted"},
+			want:        "zone freeze lifted: gateway default/prod address changed",
+		},
+		{
+			name:        "same generation",
+			annotations: map[string]string{v1alpha1.ChangeCauseAnnotation: "gateway default/prod address changed"},
+			generation:  1,
+			spec:        published,
+			want:        "Provider zone records restored to the published endpoints",
+		},
+		{
+			name:        "same generation with triggers",
+			annotations: map[string]string{v1alpha1.ChangeCauseAnnotation: "gateway default/prod address changed"},
+			generation:  1,
+			triggers:    []string{"target override applied"},
+			want:        "target override applied: created A baz.example.com; updated targets, TTL of A foo.example.com; deleted A bar.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints := spec
+			if tt.spec != nil {
+				endpoints = tt.spec
+			}
+			dnsRecord := &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations, Generation: tt.generation},
+				Spec:       v1alpha1.DNSRecordSpec{Endpoints: endpoints},
+				Status:     v1alpha1.DNSRecordStatus{Endpoints: published, ObservedGeneration: 1},
+			}
+			if got := changeCause(dnsRecord, tt.triggers); got != tt.want {
+				t.Errorf("changeCause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishTriggers(t *testing.T) {
+	condition := func(conditionType v1alpha1.ConditionType, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+		return metav1.Condition{Type: string(conditionType), Status: status, Reason: reason, Message: message}
+	}
+	overrideActive := condition(v1alpha1.ConditionTypeOverridden, metav1.ConditionTrue, "OverrideActive", "Targets of foo.example.com are overridden")
+	overrideExpired := condition(v1alpha1.ConditionTypeOverridden, metav1.ConditionFalse, "OverrideExpired", "Override expired")
+	excluded := condition(v1alpha1.ConditionTypeTargetsExcluded, metav1.ConditionTrue, "ExcludedCIDR", "Targets 10.0.0.1 are within excluded CIDRs")
+
+	tests := []struct {
+		name       string
+		previous   []metav1.Condition
+		current    []metav1.Condition
+		generation int64
+		dampened   bool
+		want       []string
+	}{
+		{
+			name:       "drift",
+			generation: 1,
+		},
+		{
+			name:       "override applied",
+			current:    []metav1.Condition{overrideActive},
+			generation: 1,
+			want:       []string{"target override applied"},
+		},
+		{
+			name:       "override still active",
+			previous:   []metav1.Condition{overrideActive},
+			current:    []metav1.Condition{overrideActive},
+			generation: 1,
+		},
+		{
+			name:       "override expired",
+			previous:   []metav1.Condition{overrideActive},
+			current:    []metav1.Condition{overrideExpired},
+			generation: 1,
+			want:       []string{"target override expired"},
+		},
+		{
+			name:       "override removed",
+			previous:   []metav1.Condition{overrideActive},
+			generation: 1,
+			want:       []string{"target override removed"},
+		},
+		{
+			name:       "excluded targets changed",
+			current:    []metav1.Condition{excluded},
+			generation: 1,
+			want:       []string{"excluded targets changed"},
+		},
+		{
+			name:       "excluded targets of a new generation",
+			current:    []metav1.Condition{excluded},
+			generation: 2,
+		},
+		{
+			name:       "zone freeze lifted and dampened routing change",
+			previous:   []metav1.Condition{condition(v1alpha1.ConditionTypeReady, metav1.ConditionFalse, "ZoneFrozen", "Changes are pending")},
+			generation: 2,
+			dampened:   true,
+			want:       []string{"zone freeze lifted", "routing change stable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Generation: tt.generation},
+				Status:     v1alpha1.DNSRecordStatus{Conditions: tt.previous, ObservedGeneration: 1},
+			}
+			current := previous.DeepCopy()
+			current.Status.Conditions = tt.current
+			if got := publishTriggers(previous, current, tt.dampened); !slices.Equal(got, tt.want) {
+				t.Errorf("publishTriggers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordPublishedChangeTruncatesCause(t *testing.T) {
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation:  1,
+			Annotations: map[string]string{v1alpha1.ChangeCauseAnnotation: strings.Repeat("x", v1alpha1.MaxChangeCauseLength+1)},
+		},
+	}
+	(&DNSRecordReconciler{}).recordPublishedChange(dnsRecord.DeepCopy(), dnsRecord)
+	if got := len(dnsRecord.Status.PublishedChanges[0].Cause); got != v1alpha1.MaxChangeCauseLength {
+		t.Errorf("len(Cause) = %d, want %d", got, v1alpha1.MaxChangeCauseLength)
+	}
+}
+
+func TestRemoveChangeCause(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{v1alpha1.ChangeCauseAnnotation: "gateway default/prod address changed"},
+		},
+	}
+	r := &DNSRecordReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(dnsRecord).Build()}
+
+	current := &v1alpha1.DNSRecord{}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(dnsRecord), current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	stale := current.DeepCopy()
+	if err := r.removeChangeCause(context.Background(), current); err != nil {
+		t.Fatalf("removeChangeCause() error = %v", err)
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(dnsRecord), current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := current.Annotations[v1alpha1.ChangeCauseAnnotation]; ok {
+		t.Errorf("removeChangeCause() kept the change cause annotation")
+	}
+
+	// the annotation of a record updated since is left in place
+	if err := r.removeChangeCause(context.Background(), stale); !apierrors.IsConflict(err) {
+		t.Errorf("removeChangeCause() of an updated record error = %v, want conflict", err)
+	}
+}
+
+func TestRecordPublishedChangeKeepsMostRecent(t *testing.T) {
+	dnsRecord := &v1alpha1.DNSRecord{}
+	for generation := int64(1); generation <= v1alpha1.MaxPublishedChanges+2; generation++ {
+		dnsRecord.Generation = generation
+		(&DNSRecordReconciler{}).recordPublishedChange(dnsRecord.DeepCopy(), dnsRecord)
+		dnsRecord.Status.ObservedGeneration = generation
+	}
+	if len(dnsRecord.Status.PublishedChanges) != v1alpha1.MaxPublishedChanges {
+		t.Fatalf("len(PublishedChanges) = %d, want %d", len(dnsRecord.Status.PublishedChanges), v1alpha1.MaxPublishedChanges)
+	}
+	if got := dnsRecord.Status.PublishedChanges[0].Generation; got != v1alpha1.MaxPublishedChanges+2 {
+		t.Errorf("newest published change generation = %d, want %d", got, v1alpha1.MaxPublishedChanges+2)
+	}
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+var ErrChurnAnomaly = errors.New("churn anomaly")
+
+// ChurnLimits configures detection of anomalous churn, a sudden spike in record deletions and target changes, in a
+// zone. Such a spike may be caused by a compromised source driving malicious DNS changes.
+type ChurnLimits struct {
+	// MaxChanges is the number of record deletions and target changes in a zone within Window above which churn is
+	// anomalous. Zero disables detection.
+	MaxChanges int
+	// Window is the period changes are counted over
+	Window time.Duration
+	// PauseWrites stops writing changes that exceed MaxChanges until they are acknowledged with the
+	// v1alpha1.AcknowledgeChurnAnnotation
+	PauseWrites bool
+}
+
+// churnTracker counts the changes written to each zone over time, and tracks the zones whose churn is anomalous
+type churnTracker struct {
+	sync.Mutex
+	changes map[string][]churnSample
+	// anomalousUntil is the time the churn anomaly of each zone clears, a window after it was last detected
+	anomalousUntil map[string]time.Time
+}
+
+type churnSample struct {
+	at    time.Time
+	count int
+}
+
+// count returns the number of changes written to the given zone within the window
+func (t *churnTracker) count(zone string, now time.Time, window time.Duration) int {
+	t.Lock()
+	defer t.Unlock()
+	if t.changes == nil {
+		return 0
+	}
+
+	total := 0
+	var samples []churnSample
+	for _, s := range t.changes[zone] {
+		if now.Sub(s.at) < window {
+			samples = append(samples, s)
+			total += s.count
+		}
+	}
+	t.changes[zone] = samples
+	return total
+}
+
+// add records count changes written to the given zone
+func (t *churnTracker) add(zone string, count int, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	if t.changes == nil {
+		t.changes = map[string][]churnSample{}
+	}
+	t.changes[zone] = append(t.changes[zone], churnSample{at: now, count: count})
+}
+
+// flagAnomaly records anomalous churn detected in the given zone, which lasts until the window has passed since
+func (t *churnTracker) flagAnomaly(zone string, now time.Time, window time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+	if t.anomalousUntil == nil {
+		t.anomalousUntil = map[string]time.Time{}
+	}
+	t.anomalousUntil[zone] = now.Add(window)
+}
+
+// anomalous returns whether anomalous churn was detected in the given zone within the window
+func (t *churnTracker) anomalous(zone string, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+	until, ok := t.anomalousUntil[zone]
+	if ok && !now.Before(until) {
+		delete(t.anomalousUntil, zone)
+		return false
+	}
+	return ok
+}
+
+// checkChurn sets the ChurnAnomaly condition if the deletions and target changes of the given changes would take the
+// zone of the given DNSRecord above the churn limit. The condition is kept until the window has passed since anomalous
+// churn was last detected in the zone, and the zone churn anomaly metric reports the same. An ErrChurnAnomaly error is
+// returned if the changes must not be written, until acknowledged, as writes are paused. Warning events are recorded
+// for the DNSRecord when anomalous churn is detected, and when its writes are paused.
+func (r *DNSRecordReconciler) checkChurn(dnsRecord *v1alpha1.DNSRecord, changes *externaldnsplan.Changes) error {
+	if r.ChurnLimits.MaxChanges == 0 {
+		return nil
+	}
+	zone := dnsRecord.Status.ZoneDomainName
+	count := churnOf(changes)
+	total := r.churn.count(zone, reconcileStart.Time, r.ChurnLimits.Window) + count
+
+	if count == 0 || total <= r.ChurnLimits.MaxChanges {
+		if !r.churn.anomalous(zone, reconcileStart.Time) {
+			metrics.ZoneChurnAnomaly.WithLabelValues(zone).Set(0)
+			meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeChurnAnomaly))
+		}
+		return nil
+	}
+
+	r.churn.flagAnomaly(zone, reconcileStart.Time, r.ChurnLimits.Window)
+	metrics.ZoneChurnAnomaly.WithLabelValues(zone).Set(1)
+	message := fmt.Sprintf("%d record deletions and target changes in zone %s within %s, limit is %d", total, zone, r.ChurnLimits.Window, r.ChurnLimits.MaxChanges)
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeChurnAnomaly), metav1.ConditionTrue, "ChurnAnomaly", message)
+	r.recordWarning(dnsRecord, "ChurnAnomaly", message)
+
+	acknowledged := dnsRecord.Annotations[v1alpha1.AcknowledgeChurnAnnotation] == strconv.FormatInt(dnsRecord.Generation, 10)
+	if r.ChurnLimits.PauseWrites && !acknowledged {
+		r.recordWarning(dnsRecord, "WritesPaused", fmt.Sprintf("Writes paused until acknowledged with the %s annotation set to generation %d: %s",
+			v1alpha1.AcknowledgeChurnAnnotation, dnsRecord.Generation, message))
+		return fmt.Errorf("%w: %s", ErrChurnAnomaly, message)
+	}
+	return nil
+}
+
+// recordWarning records a Warning event with the given reason and message for the given DNSRecord, if the reconciler
+// has a Recorder
+func (r *DNSRecordReconciler) recordWarning(dnsRecord *v1alpha1.DNSRecord, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(dnsRecord, v1.EventTypeWarning, reason, message)
+	}
+}
+
+// recordChurn records the deletions and target changes of the given changes, written to the zone of the given DNSRecord
+func (r *DNSRecordReconciler) recordChurn(dnsRecord *v1alpha1.DNSRecord, changes *externaldnsplan.Changes) {
+	count := churnOf(changes)
+	if count == 0 {
+		return
+	}
+	metrics.ZoneChurnCounter.WithLabelValues(dnsRecord.Status.ZoneDomainName).Add(float64(count))
+	if r.ChurnLimits.MaxChanges > 0 {
+		r.churn.add(dnsRecord.Status.ZoneDomainName, count, reconcileStart.Time)
+	}
+}
+
+// churnOf returns the number of record deletions and target changes in the given changes
+func churnOf(changes *externaldnsplan.Changes) int {
+	count := len(changes.Delete)
+	oldTargets := map[externaldnsendpoint.EndpointKey]externaldnsendpoint.Targets{}
+	for _, ep := range changes.UpdateOld {
+		oldTargets[ep.Key()] = ep.Targets
+	}
+	for _, ep := range changes.UpdateNew {
+		if targets, ok := oldTargets[ep.Key()]; ok && !targets.Same(ep.Targets) {
+			count++
+		}
+	}
+	return count
+}
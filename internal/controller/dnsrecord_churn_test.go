@@ -0,0 +1,177 @@
+//go:build unit
+
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+func TestChurnOf(t *testing.T) {
+	changes := &externaldnsplan.Changes{
+		Create: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("new.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+		},
+		Delete: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("old.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+		},
+		UpdateOld: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1").WithSetIdentifier("eu"),
+		},
+		UpdateNew: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.2"),
+			externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1").WithSetIdentifier("eu").
+				WithProviderSpecific("aws/weight", "10"),
+		},
+	}
+	// the deletion and the target change of foo count, the creation and the routing change of bar do not
+	if got := churnOf(changes); got != 2 {
+		t.Errorf("churnOf() = %d, want 2", got)
+	}
+}
+
+func TestCheckChurn(t *testing.T) {
+	const zone = "churn.example.com"
+	deletes := func(n int) *externaldnsplan.Changes {
+		changes := &externaldnsplan.Changes{}
+		for i := 0; i < n; i++ {
+			changes.Delete = append(changes.Delete,
+				externaldnsendpoint.NewEndpoint(strconv.Itoa(i)+"."+zone, externaldnsendpoint.RecordTypeA, "127.0.0.1"))
+		}
+		return changes
+	}
+	newRecord := func(name string) *v1alpha1.DNSRecord {
+		return &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 1},
+			Status:     v1alpha1.DNSRecordStatus{ZoneDomainName: zone},
+		}
+	}
+	hasAnomaly := func(dnsRecord *v1alpha1.DNSRecord) bool {
+		return meta.IsStatusConditionTrue(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeChurnAnomaly))
+	}
+	gauge := func() float64 {
+		return testutil.ToFloat64(metrics.ZoneChurnAnomaly.WithLabelValues(zone))
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &DNSRecordReconciler{ChurnLimits: ChurnLimits{MaxChanges: 3, Window: time.Minute, PauseWrites: true}, Recorder: recorder}
+	// events returns the reasons of the events recorded since last called
+	events := func() []string {
+		var reasons []string
+		for len(recorder.Events) > 0 {
+			reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+		}
+		return reasons
+	}
+	start := time.Now()
+	reconcileStart = metav1.NewTime(start)
+
+	// changes within the limit are written and recorded
+	foo := newRecord("foo")
+	if err := r.checkChurn(foo, deletes(2)); err != nil {
+		t.Fatalf("checkChurn() within the limit error = %v", err)
+	}
+	r.recordChurn(foo, deletes(2))
+	if hasAnomaly(foo) || gauge() != 0 {
+		t.Errorf("checkChurn() within the limit set the anomaly, condition %v gauge %v", hasAnomaly(foo), gauge())
+	}
+	if got := events(); len(got) != 0 {
+		t.Errorf("checkChurn() within the limit recorded events %v", got)
+	}
+
+	// changes taking the zone above the limit are paused until acknowledged
+	bar := newRecord("bar")
+	if err := r.checkChurn(bar, deletes(2)); !errors.Is(err, ErrChurnAnomaly) {
+		t.Errorf("checkChurn() above the limit error = %v, want %v", err, ErrChurnAnomaly)
+	}
+	if !hasAnomaly(bar) || gauge() != 1 {
+		t.Errorf("checkChurn() above the limit did not set the anomaly, condition %v gauge %v", hasAnomaly(bar), gauge())
+	}
+	if got := events(); !reflect.DeepEqual(got, []string{"ChurnAnomaly", "WritesPaused"}) {
+		t.Errorf("checkChurn() above the limit recorded events %v, want ChurnAnomaly and WritesPaused", got)
+	}
+	bar.Annotations = map[string]string{v1alpha1.AcknowledgeChurnAnnotation: "1"}
+	if err := r.checkChurn(bar, deletes(2)); err != nil {
+		t.Errorf("checkChurn() of acknowledged changes error = %v", err)
+	}
+	if got := events(); !reflect.DeepEqual(got, []string{"ChurnAnomaly"}) {
+		t.Errorf("checkChurn() of acknowledged changes recorded events %v, want ChurnAnomaly", got)
+	}
+
+	// records without churn of their own do not clear the anomaly of the zone within the window
+	baz := newRecord("baz")
+	reconcileStart = metav1.NewTime(start.Add(30 * time.Second))
+	if err := r.checkChurn(baz, deletes(0)); err != nil {
+		t.Errorf("checkChurn() without changes error = %v", err)
+	}
+	if err := r.checkChurn(bar, deletes(0)); err != nil {
+		t.Errorf("checkChurn() without changes error = %v", err)
+	}
+	if hasAnomaly(baz) || !hasAnomaly(bar) || gauge() != 1 {
+		t.Errorf("checkChurn() within the window cleared the anomaly, condition %v gauge %v", hasAnomaly(bar), gauge())
+	}
+
+	// the anomaly clears once the window has passed since it was detected
+	reconcileStart = metav1.NewTime(start.Add(2 * time.Minute))
+	if err := r.checkChurn(bar, deletes(0)); err != nil {
+		t.Errorf("checkChurn() without changes error = %v", err)
+	}
+	if hasAnomaly(bar) || gauge() != 0 {
+		t.Errorf("checkChurn() past the window kept the anomaly, condition %v gauge %v", hasAnomaly(bar), gauge())
+	}
+
+	// disabled detection never pauses changes
+	r.ChurnLimits.MaxChanges = 0
+	if err := r.checkChurn(newRecord("qux"), deletes(10)); err != nil {
+		t.Errorf("checkChurn() with detection disabled error = %v", err)
+	}
+}
+
+func TestRecordChurn(t *testing.T) {
+	const zone = "record-churn.example.com"
+	dnsRecord := &v1alpha1.DNSRecord{Status: v1alpha1.DNSRecordStatus{ZoneDomainName: zone}}
+	changes := &externaldnsplan.Changes{
+		Delete: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("foo."+zone, externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+		},
+	}
+	start := time.Now()
+	reconcileStart = metav1.NewTime(start)
+
+	// churn is counted by the metric whether or not detection is enabled, and only tracked when it is
+	r := &DNSRecordReconciler{}
+	r.recordChurn(dnsRecord, changes)
+	if got := r.churn.count(zone, start, time.Minute); got != 0 {
+		t.Errorf("recordChurn() with detection disabled tracked %d changes", got)
+	}
+
+	r.ChurnLimits = ChurnLimits{MaxChanges: 10, Window: time.Minute}
+	r.recordChurn(dnsRecord, changes)
+	r.recordChurn(dnsRecord, &externaldnsplan.Changes{})
+	if got := r.churn.count(zone, start, time.Minute); got != 1 {
+		t.Errorf("recordChurn() tracked %d changes, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.ZoneChurnCounter.WithLabelValues(zone)); got != 2 {
+		t.Errorf("recordChurn() counted %v changes, want 2", got)
+	}
+
+	// changes age out of the window
+	if got := r.churn.count(zone, start.Add(2*time.Minute), time.Minute); got != 0 {
+		t.Errorf("count() past the window = %d, want 0", got)
+	}
+}
@@ -20,7 +20,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -31,11 +34,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
 	externaldnsprovider "sigs.k8s.io/external-dns/provider"
@@ -46,13 +51,14 @@ import (
 	externaldnsregistry "github.com/kuadrant/dns-operator/internal/external-dns/registry"
 	"github.com/kuadrant/dns-operator/internal/metrics"
 	"github.com/kuadrant/dns-operator/internal/provider"
+	"github.com/kuadrant/dns-operator/pkg/validation"
 )
 
 const (
 	DNSRecordFinalizer        = "kuadrant.io/dns-record"
 	validationRequeueVariance = 0.5
 
-	txtRegistryPrefix              = "kuadrant-"
+	txtRegistryPrefix              = v1alpha1.RegistryTXTPrefix
 	txtRegistrySuffix              = ""
 	txtRegistryWildcardReplacement = "wildcard"
 	txtRegistryEncryptEnabled      = false
@@ -60,6 +66,9 @@ const (
 	txtRegistryCacheInterval       = time.Duration(0)
 )
 
+// DefaultManagedRecordTypes are the record types managed in provider zones when none are configured
+var DefaultManagedRecordTypes = []string{externaldnsendpoint.RecordTypeA, externaldnsendpoint.RecordTypeAAAA, externaldnsendpoint.RecordTypeCNAME}
+
 var (
 	defaultRequeueTime          time.Duration
 	defaultValidationRequeue    time.Duration
@@ -68,16 +77,99 @@ var (
 	reconcileStart              metav1.Time
 )
 
+// ZoneLimits caps non-essential provider operations, to limit provider costs, and restricts the records published in a
+// zone
+type ZoneLimits struct {
+	// MinValidationInterval is the minimum time between validations of a record in the provider
+	MinValidationInterval time.Duration
+	// DisableHealthChecks removes, and prevents the creation of, provider health checks
+	DisableHealthChecks bool
+	// RejectWildcards fails records with wildcard endpoints, rather than publishing them
+	RejectWildcards bool
+}
+
+// AdoptionMode is how records annotated with the AdoptRecordsAnnotation take ownership of unowned records in their zone
+type AdoptionMode string
+
+const (
+	// AdoptionDisabled ignores the AdoptRecordsAnnotation
+	AdoptionDisabled AdoptionMode = ""
+	// AdoptionMatching adopts unowned records whose targets already match the endpoints of the record
+	AdoptionMatching AdoptionMode = "matching"
+	// AdoptionReplace adopts unowned records, replacing their targets with the endpoints of the record
+	AdoptionReplace AdoptionMode = "replace"
+)
+
 // DNSRecordReconciler reconciles a DNSRecord object
 type DNSRecordReconciler struct {
 	client.Client
 	Scheme          *runtime.Scheme
 	ProviderFactory provider.Factory
+	// EnforceHostClaims rejects records with endpoints for a hostname already claimed, in the same zone, by an older
+	// record in another namespace.
+	EnforceHostClaims bool
+	// PublishSLO is the time a spec change is expected to be validated in the provider within.
+	// The PublishSLOExceeded condition is set on records that exceed it. Zero disables the check.
+	PublishSLO time.Duration
+	// ZoneLimits are the limits applied to records in each zone, keyed by zone domain name
+	ZoneLimits map[string]ZoneLimits
+	// ManagedRecordTypes are the record types managed in provider zones, records of any other type are never changed.
+	// Defaults to DefaultManagedRecordTypes.
+	ManagedRecordTypes []string
+	// RoutingChangeDampening is the time a change to only the weight or geo of endpoints must be stable for before
+	// it is published. Zero publishes routing changes immediately.
+	RoutingChangeDampening time.Duration
+	// RegistrySigningKey, if set, is used to sign the registry TXT records holding ownership of records. Records whose
+	// ownership TXT record has no valid signature are considered unowned.
+	RegistrySigningKey []byte
+	// RegistrySigningMigration accepts unsigned ownership TXT records of the owner of each record, replacing them with
+	// signed ones, so records published before RegistrySigningKey was set are not lost.
+	RegistrySigningMigration bool
+	// ChurnLimits configures detection of anomalous churn of records in each zone
+	ChurnLimits ChurnLimits
+	// Recorder, if set, records events of the records, e.g. anomalous churn in their zone
+	Recorder record.EventRecorder
+	// VerifyZoneDelegation gates publishing on the NS delegation of the zone resolving, from the public internet, to
+	// the name servers of the zone in the provider.
+	VerifyZoneDelegation bool
+	// VerifyPropagation queries the authoritative name servers of the zone, once records are published, reporting
+	// whether they serve the endpoints of each record with the Propagated condition.
+	VerifyPropagation bool
+	// ShadowMode compares the endpoints of records with the records in their provider zone, reporting divergence with
+	// the ShadowDiverged condition, instead of publishing them. Nothing is written to, or deleted from, the provider.
+	ShadowMode bool
+	// DryRun applies the changes of records to their providers in dry run mode, validating them as far as each provider
+	// allows and reporting the outcome with the DryRun condition, instead of publishing them.
+	DryRun bool
+	// ExcludedTargetCIDRs are the CIDRs, e.g. of internal only addresses, whose addresses are never published as
+	// targets of A or AAAA endpoints. Endpoints left without targets are not published.
+	ExcludedTargetCIDRs []netip.Prefix
+	// OrphanRecordGC configures the periodic sweep of provider zones for records whose owners are no longer the owner
+	// of any record
+	OrphanRecordGC OrphanRecordGC
+	// OwnerIDPrefix is prefixed to the owner IDs assigned to records without a spec.ownerID. It identifies the records
+	// published by this cluster, across rebuilds, as the ownership scope of the orphan record sweep.
+	OwnerIDPrefix string
+	// Adoption is how records annotated with the AdoptRecordsAnnotation take ownership of unowned records in their
+	// zone. Records are never adopted by default.
+	Adoption AdoptionMode
+	// AdoptionNamespaces are the namespaces of the records allowed to adopt unowned records, all when empty
+	AdoptionNamespaces []string
+
+	// routingChanges holds the pending routing change, if any, of each record keyed by UID
+	routingChanges sync.Map
+	// publishedSpecs holds the spec, as written, of the last published generation of each record keyed by UID
+	publishedSpecs sync.Map
+	// churn counts the changes written to each zone
+	churn churnTracker
+	// orphanSightings counts the consecutive sweeps owners have been found orphaned in
+	orphanSightings orphanSightings
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnsrecords,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnsrecords/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnsrecords/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Keep a reference to the initial logger(baseLogger) so we can update it throughout the reconcile
@@ -108,7 +200,35 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	if dnsRecord.DeletionTimestamp != nil && !dnsRecord.DeletionTimestamp.IsZero() {
 		logger.Info("Deleting DNSRecord")
-		if dnsRecord.HasDNSZoneAssigned() {
+		r.routingChanges.Delete(dnsRecord.UID)
+		r.publishedSpecs.Delete(dnsRecord.UID)
+		metrics.ShadowDivergence.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		metrics.PublishDeadlineExceeded.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		metrics.PublishDuration.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		metrics.PropagationDuration.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		metrics.NameServerPropagationDuration.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		metrics.DriftCorrections.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		if r.ShadowMode {
+			logger.Info("shadow mode, skipping zone cleanup")
+		} else if r.DryRun {
+			logger.Info("dry run mode, keeping finalizer until the zone is cleaned up out of dry run mode")
+			return r.reconcileDryRunDelete(ctx, previous, dnsRecord)
+		} else if dnsRecord.Spec.DeletionPolicy == v1alpha1.DeletionPolicyRetain {
+			logger.Info("deletion policy is Retain, skipping zone cleanup")
+			if r.OrphanRecordGC.Delete && dnsRecord.HasDNSZoneAssigned() {
+				dnsProvider, err := r.getDNSProvider(ctx, dnsRecord)
+				if err != nil {
+					logger.Error(err, "Failed to load DNS Provider")
+					setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+						"DNSProviderError", fmt.Sprintf("The dns provider could not be loaded: %v", err))
+					return r.updateStatus(ctx, previous, dnsRecord, false, err)
+				}
+				if err = r.markRecordsRetained(ctx, dnsRecord, dnsProvider); err != nil {
+					logger.Error(err, "Failed to mark records retained")
+					return ctrl.Result{}, err
+				}
+			}
+		} else if dnsRecord.HasDNSZoneAssigned() {
 			// Create a dns provider with config calculated for the current dns record status (Last successful)
 			dnsProvider, err := r.getDNSProvider(ctx, dnsRecord)
 			if err != nil {
@@ -119,6 +239,15 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				return r.updateStatus(ctx, previous, dnsRecord, false, err)
 			}
 
+			// Neither records nor health checks are deleted from frozen zones
+			if zone := dnsRecord.Status.ZoneDomainName; provider.ZoneFrozen(dnsProvider, zone) {
+				logger.Info("Not deleting record, zone is frozen")
+				err = fmt.Errorf("%w: writes to zone %s are stopped", provider.ErrZoneFrozen, zone)
+				setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+					"ZoneFrozen", fmt.Sprintf("Deletion is pending while writes to the zone are stopped: %v", err))
+				return r.updateStatus(ctx, previous, dnsRecord, false, err)
+			}
+
 			if err = r.ReconcileHealthChecks(ctx, dnsRecord); client.IgnoreNotFound(err) != nil {
 				return ctrl.Result{}, err
 			}
@@ -159,7 +288,19 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{RequeueAfter: randomizedValidationRequeue}, nil
 	}
 
+	// Track when the current spec change was first observed so we can measure the time taken to publish it
+	if generationChanged(dnsRecord) && dnsRecord.Status.SpecChangedAt == nil {
+		specChangedAt := reconcileStart
+		if dnsRecord.Status.ObservedGeneration == 0 {
+			specChangedAt = dnsRecord.CreationTimestamp
+		}
+		dnsRecord.Status.SpecChangedAt = &specChangedAt
+	}
+
 	err = dnsRecord.Validate()
+	if err == nil {
+		err = validation.ValidateRecordTypes(dnsRecord, r.managedRecordTypes())
+	}
 	if err != nil {
 		logger.Error(err, "Failed to validate record")
 		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
@@ -167,12 +308,15 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return r.updateStatus(ctx, previous, dnsRecord, false, err)
 	}
 
+	// Endpoints inherit the provider specific properties of the record, as with target overrides only in memory
+	dnsRecord.ApplyProviderSpecific()
+
 	//Ensure an Owner ID has been assigned to the record (OwnerID set in the status)
 	if !dnsRecord.HasOwnerIDAssigned() {
 		if dnsRecord.Spec.OwnerID != "" {
 			dnsRecord.Status.OwnerID = dnsRecord.Spec.OwnerID
 		} else {
-			dnsRecord.Status.OwnerID = dnsRecord.GetUIDHash()
+			dnsRecord.Status.OwnerID = r.OwnerIDPrefix + dnsRecord.GetUIDHash()
 		}
 		//Update logger and context so it includes updated owner metadata
 		ctx, logger = r.setLogger(ctx, baseLogger, dnsRecord)
@@ -205,6 +349,60 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		ctx, logger = r.setLogger(ctx, baseLogger, dnsRecord)
 	}
 
+	if r.ZoneLimits[dnsRecord.Status.ZoneDomainName].RejectWildcards {
+		if err = rejectWildcards(dnsRecord); err != nil {
+			logger.Error(err, "Wildcard rejected")
+			setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+				"WildcardRejected", err.Error())
+			return r.updateStatus(ctx, previous, dnsRecord, false, err)
+		}
+	}
+
+	// Publish the break-glass target override in place of the spec targets until it expires, before checking the
+	// endpoints for collisions so the targets that are published are checked
+	overrideExpiresAt, err := applyOverride(dnsRecord)
+	if err != nil {
+		logger.Error(err, "Failed to apply target override")
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+			"InvalidOverride", err.Error())
+		return r.updateStatus(ctx, previous, dnsRecord, false, err)
+	}
+
+	// Ensure no other record with the same owner is publishing different targets for any of our endpoints
+	if err = r.checkEndpointCollisions(ctx, dnsRecord); err != nil {
+		logger.Error(err, "Endpoint collision detected")
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+			"EndpointCollision", err.Error())
+		return r.updateStatus(ctx, previous, dnsRecord, false, err)
+	}
+
+	// Ensure none of our endpoint hostnames are claimed by a record in another namespace
+	if r.EnforceHostClaims {
+		if err = r.checkHostClaims(ctx, dnsRecord); err != nil {
+			logger.Error(err, "Hostname claimed by another namespace")
+			setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+				"HostClaimed", err.Error())
+			return r.updateStatus(ctx, previous, dnsRecord, false, err)
+		}
+	}
+
+	// Coalesce rapidly changing weights and geos, publishing them only once stable
+	if wait := r.dampenRoutingChange(previous, dnsRecord); wait > 0 {
+		logger.Info("Routing change not yet stable, delaying publish", "wait", wait)
+		if !equality.Semantic.DeepEqual(previous.Status, dnsRecord.Status) {
+			if err = r.Status().Update(ctx, dnsRecord); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{Requeue: true}, nil
+				}
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	// Never publish targets within the excluded CIDRs, e.g. internal only addresses
+	r.excludeTargets(dnsRecord)
+
 	// Create a dns provider for the current record, must have an owner and zone assigned or will throw an error
 	dnsProvider, err := r.getDNSProvider(ctx, dnsRecord)
 	if err != nil {
@@ -213,8 +411,33 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return r.updateStatus(ctx, previous, dnsRecord, false, err)
 	}
 
+	if r.ShadowMode {
+		return r.reconcileShadow(ctx, previous, dnsRecord, dnsProvider)
+	}
+	if r.DryRun {
+		return r.reconcileDryRun(ctx, previous, dnsRecord, dnsProvider)
+	}
+
 	// Publish the record
 	hadChanges, err := r.publishRecord(ctx, dnsRecord, dnsProvider)
+	if errors.Is(err, provider.ErrZoneFrozen) {
+		logger.Info("Not publishing record, zone is frozen")
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+			"ZoneFrozen", fmt.Sprintf("Changes are pending while writes to the zone are stopped: %v", err))
+		return r.updateStatus(ctx, previous, dnsRecord, hadChanges, err)
+	}
+	if errors.Is(err, ErrZoneNotDelegated) {
+		logger.Info("Not publishing record, zone is not delegated to the provider")
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+			"ZoneNotDelegated", fmt.Sprintf("Changes are pending until the zone delegation resolves: %v", err))
+		return r.updateStatus(ctx, previous, dnsRecord, hadChanges, err)
+	}
+	if errors.Is(err, ErrChurnAnomaly) {
+		logger.Info("Not publishing record, zone churn is anomalous")
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+			"ChurnAnomaly", fmt.Sprintf("Changes are paused until acknowledged with the %s annotation: %v", v1alpha1.AcknowledgeChurnAnnotation, err))
+		return r.updateStatus(ctx, previous, dnsRecord, hadChanges, err)
+	}
 	if err != nil {
 		logger.Error(err, "Failed to publish record")
 		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
@@ -226,7 +449,11 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
-	return r.updateStatus(ctx, previous, dnsRecord, hadChanges, nil)
+	result, err := r.updateStatus(ctx, previous, dnsRecord, hadChanges, nil)
+	if err == nil && overrideExpiresAt != nil {
+		result = requeueBefore(result, *overrideExpiresAt)
+	}
+	return result, err
 }
 
 // setLogger Updates the given Logger with record/zone metadata from the given DNSRecord.
@@ -247,6 +474,9 @@ func (r *DNSRecordReconciler) updateStatus(ctx context.Context, previous, curren
 	// failure
 	if specErr != nil {
 		logger.Error(specErr, "Error reconciling DNS Record")
+		r.setPublishSLOCondition(current)
+		setPublishDeadlineCondition(current)
+		setPublishStatus(current, false)
 		var updateError error
 		if !equality.Semantic.DeepEqual(previous.Status, current.Status) {
 			if updateError = r.Status().Update(ctx, current); updateError != nil && apierrors.IsConflict(updateError) {
@@ -269,7 +499,11 @@ func (r *DNSRecordReconciler) updateStatus(ctx context.Context, previous, curren
 			current.Status.WriteCounter++
 			metrics.WriteCounter.WithLabelValues(current.Name, current.Namespace).Inc()
 			logger.V(1).Info("Changes needed on the same generation of record")
+		} else if current.Status.SpecChangedAt != nil {
+			metrics.PublishDuration.WithLabelValues(current.Name, current.Namespace).
+				Observe(reconcileStart.Sub(current.Status.SpecChangedAt.Time).Seconds())
 		}
+		r.recordPublishedChange(previous, current)
 		requeueTime = randomizedValidationRequeue
 		setDNSRecordCondition(current, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse, "AwaitingValidation", "Awaiting validation")
 	} else {
@@ -281,9 +515,28 @@ func (r *DNSRecordReconciler) updateStatus(ctx context.Context, previous, curren
 			// uses current.Status.ValidFor as the last requeue duration. Double it.
 			requeueTime = exponentialRequeueTime(current.Status.ValidFor)
 		}
+		if current.Status.SpecChangedAt != nil {
+			metrics.PropagationDuration.WithLabelValues(current.Name, current.Namespace).
+				Observe(reconcileStart.Sub(current.Status.SpecChangedAt.Time).Seconds())
+			current.Status.SpecChangedAt = nil
+		}
 		setDNSRecordCondition(current, string(v1alpha1.ConditionTypeReady), metav1.ConditionTrue, "ProviderSuccess", "Provider ensured the dns record")
 	}
 
+	// keep validating the record frequently until the authoritative name servers serve its endpoints
+	if !r.setPropagatedCondition(ctx, current, hadChanges) && !hadChanges && requeueTime > defaultValidationRequeue {
+		requeueTime = defaultValidationRequeue
+	}
+
+	if interval := current.Spec.ResyncInterval; interval != nil && interval.Duration > 0 && requeueTime > interval.Duration {
+		requeueTime = interval.Duration
+	}
+
+	if limits, ok := r.ZoneLimits[current.Status.ZoneDomainName]; ok && requeueTime < limits.MinValidationInterval {
+		logger.V(1).Info("Limiting validation frequency for zone", "minValidationInterval", limits.MinValidationInterval)
+		requeueTime = limits.MinValidationInterval
+	}
+
 	// valid for is always a requeue time
 	current.Status.ValidFor = requeueTime.String()
 
@@ -294,8 +547,16 @@ func (r *DNSRecordReconciler) updateStatus(ctx context.Context, previous, curren
 		logger.V(1).Info("Resetting write counter on the generation change")
 	}
 
+	r.setPublishSLOCondition(current)
+	setPublishDeadlineCondition(current)
+	setPublishStatus(current, true)
+
+	_, hasChangeCause := current.Annotations[v1alpha1.ChangeCauseAnnotation]
+	changeCausePublished := hasChangeCause && generationChanged(current)
+
 	current.Status.ObservedGeneration = current.Generation
 	current.Status.Endpoints = current.Spec.Endpoints
+	r.publishedSpecs.Store(current.UID, previous.Spec.DeepCopy())
 	current.Status.QueuedAt = reconcileStart
 
 	// update the record after setting the status
@@ -308,6 +569,14 @@ func (r *DNSRecordReconciler) updateStatus(ctx context.Context, previous, curren
 			return ctrl.Result{}, updateError
 		}
 	}
+	if changeCausePublished {
+		if updateError := r.removeChangeCause(ctx, current); updateError != nil {
+			if apierrors.IsConflict(updateError) {
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, updateError
+		}
+	}
 	logger.V(1).Info(fmt.Sprintf("Requeue in %s", requeueTime.String()))
 	return ctrl.Result{RequeueAfter: requeueTime}, nil
 }
@@ -318,6 +587,12 @@ func (r *DNSRecordReconciler) SetupWithManager(mgr ctrl.Manager, maxRequeue, val
 	validFor = validForDuration
 	defaultValidationRequeue = minRequeue
 
+	if r.OrphanRecordGC.Interval > 0 {
+		if err := mgr.Add(manager.RunnableFunc(r.collectOrphanRecords)); err != nil {
+			return err
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.DNSRecord{}).
 		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
@@ -431,6 +706,72 @@ func setDNSRecordCondition(dnsRecord *v1alpha1.DNSRecord, conditionType string,
 	meta.SetStatusCondition(&dnsRecord.Status.Conditions, cond)
 }
 
+// managedRecordTypes returns the record types managed in provider zones
+func (r *DNSRecordReconciler) managedRecordTypes() []string {
+	if len(r.ManagedRecordTypes) == 0 {
+		return DefaultManagedRecordTypes
+	}
+	return r.ManagedRecordTypes
+}
+
+// checkEndpointCollisions returns an error if another DNSRecord with the same owner and zone defines an endpoint
+// with the same dnsName and setIdentifier as the given DNSRecord but with different targets.
+// Both records would otherwise be considered the owner of the same record set and would overwrite each other's changes
+// in the provider. The oldest record is allowed to publish its endpoints, all newer records are rejected.
+func (r *DNSRecordReconciler) checkEndpointCollisions(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) error {
+	records := &v1alpha1.DNSRecordList{}
+	if err := r.Client.List(ctx, records); err != nil {
+		return err
+	}
+	for i := range records.Items {
+		other := &records.Items[i]
+		if other.UID == dnsRecord.UID || other.DeletionTimestamp != nil ||
+			other.Status.OwnerID != dnsRecord.Status.OwnerID || other.Status.ZoneID != dnsRecord.Status.ZoneID {
+			continue
+		}
+		if !olderThan(other, dnsRecord) {
+			continue
+		}
+		if err := validation.EndpointCollision(dnsRecord, other, dnsRecord.Status.OwnerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkHostClaims returns an error if any endpoint hostname of the given DNSRecord is claimed by an older DNSRecord in
+// another namespace publishing to the same zone.
+// The first record to claim a hostname in a zone owns it, preventing hostname squatting in zones shared across namespaces.
+func (r *DNSRecordReconciler) checkHostClaims(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) error {
+	records := &v1alpha1.DNSRecordList{}
+	if err := r.Client.List(ctx, records); err != nil {
+		return err
+	}
+	for i := range records.Items {
+		other := &records.Items[i]
+		if other.Namespace == dnsRecord.Namespace || other.DeletionTimestamp != nil ||
+			other.Status.ZoneID != dnsRecord.Status.ZoneID || !olderThan(other, dnsRecord) {
+			continue
+		}
+		for _, ep := range dnsRecord.Spec.Endpoints {
+			for _, otherEp := range other.Spec.Endpoints {
+				if strings.EqualFold(ep.DNSName, otherEp.DNSName) {
+					return fmt.Errorf("hostname %s is claimed by DNSRecord %s/%s", ep.DNSName, other.Namespace, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// olderThan returns true if record a was created before record b. Records created at the same time are ordered by namespace and name.
+func olderThan(a, b *v1alpha1.DNSRecord) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return client.ObjectKeyFromObject(a).String() < client.ObjectKeyFromObject(b).String()
+}
+
 // getDNSProvider returns a Provider configured for the given DNSRecord
 // If no zone/id/domain has been assigned to the given record, an error is thrown.
 // If no owner has been assigned to the given record, an error is thrown.
@@ -450,6 +791,7 @@ func (r *DNSRecordReconciler) getDNSProvider(ctx context.Context, dnsRecord *v1a
 		DomainFilter:   externaldnsendpoint.NewDomainFilter([]string{dnsRecord.Status.ZoneDomainName}),
 		ZoneTypeFilter: externaldnsprovider.NewZoneTypeFilter(""),
 		ZoneIDFilter:   externaldnsprovider.NewZoneIDFilter([]string{dnsRecord.Status.ZoneID}),
+		DryRun:         r.DryRun,
 	}
 	return r.ProviderFactory.ProviderFor(ctx, dnsRecord, providerConfig)
 }
@@ -460,12 +802,10 @@ func (r *DNSRecordReconciler) applyChanges(ctx context.Context, dnsRecord *v1alp
 	logger := log.FromContext(ctx)
 	rootDomainName := dnsRecord.Spec.RootHost
 	zoneDomainFilter := externaldnsendpoint.NewDomainFilter([]string{dnsRecord.Status.ZoneDomainName})
-	managedDNSRecordTypes := []string{externaldnsendpoint.RecordTypeA, externaldnsendpoint.RecordTypeAAAA, externaldnsendpoint.RecordTypeCNAME}
+	managedDNSRecordTypes := r.managedRecordTypes()
 	var excludeDNSRecordTypes []string
 
-	registry, err := externaldnsregistry.NewTXTRegistry(ctx, dnsProvider, txtRegistryPrefix, txtRegistrySuffix,
-		dnsRecord.Status.OwnerID, txtRegistryCacheInterval, txtRegistryWildcardReplacement, managedDNSRecordTypes,
-		excludeDNSRecordTypes, txtRegistryEncryptEnabled, []byte(txtRegistryEncryptAESKey))
+	registry, err := r.newRegistry(ctx, dnsProvider, dnsRecord.Status.OwnerID, managedDNSRecordTypes, excludeDNSRecordTypes)
 	if err != nil {
 		return false, err
 	}
@@ -488,13 +828,13 @@ func (r *DNSRecordReconciler) applyChanges(ctx context.Context, dnsRecord *v1alp
 	}
 
 	//specEndpoints = Records that this DNSRecord expects to exist
-	specEndpoints, err := registry.AdjustEndpoints(dnsRecord.Spec.Endpoints)
+	specEndpoints, err := registry.AdjustEndpoints(common.NormalizeEndpoints(dnsRecord.Spec.Endpoints))
 	if err != nil {
 		return false, fmt.Errorf("adjusting specEndpoints: %w", err)
 	}
 
 	//statusEndpoints = Records that were created/updated by this DNSRecord last
-	statusEndpoints, err := registry.AdjustEndpoints(dnsRecord.Status.Endpoints)
+	statusEndpoints, err := registry.AdjustEndpoints(common.NormalizeEndpoints(dnsRecord.Status.Endpoints))
 	if err != nil {
 		return false, fmt.Errorf("adjusting statusEndpoints: %w", err)
 	}
@@ -507,16 +847,99 @@ func (r *DNSRecordReconciler) applyChanges(ctx context.Context, dnsRecord *v1alp
 		externaldnsendpoint.MatchAllDomainFilters{&zoneDomainFilter}, managedDNSRecordTypes, excludeDNSRecordTypes,
 		registry.OwnerID(), &rootDomainName,
 	)
+	if !isDelete {
+		plan.AdoptUnowned = r.adoptUnowned(ctx, dnsRecord, registry, specEndpoints)
+		plan.AdoptMatchingOnly = r.Adoption == AdoptionMatching
+	}
 
 	plan = plan.Calculate()
 	if err = plan.Error(); err != nil {
 		return false, err
 	}
 	dnsRecord.Status.DomainOwners = plan.Owners
+	if err = r.checkChurn(dnsRecord, plan.Changes); err != nil {
+		return false, err
+	}
+	if r.VerifyZoneDelegation && !isDelete && plan.Changes.HasChanges() {
+		if err = checkZoneDelegation(ctx, dnsRecord.Status.ZoneDomainName, zoneEndpoints); err != nil {
+			return false, err
+		}
+	}
 	if plan.Changes.HasChanges() {
 		logger.Info("Applying changes")
-		err = registry.ApplyChanges(ctx, plan.Changes)
-		return true, err
+		if err = registry.ApplyChanges(ctx, plan.Changes); err != nil {
+			return true, err
+		}
+		if !r.DryRun {
+			r.recordChurn(dnsRecord, plan.Changes)
+			if !isDelete && !generationChanged(dnsRecord) && zoneDrifted(zoneEndpoints, statusEndpoints, registry.OwnerID()) {
+				metrics.DriftCorrections.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Inc()
+				logger.Info("Corrected records in the provider zone that drifted from the published endpoints")
+			}
+		}
 	}
-	return false, nil
+	dnsRecord.Status.OwnedRecords = ownedRecords(registry.OwnedRecords(specEndpoints))
+	return plan.Changes.HasChanges(), nil
+}
+
+// adoptUnowned returns whether the record may take ownership of the unowned records it desires in the zone. Records
+// whose ownership is claimed by TXT records ignored for lacking a valid signature are never adopted, as they may be
+// owned by another record.
+func (r *DNSRecordReconciler) adoptUnowned(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, registry *externaldnsregistry.TXTRegistry, specEndpoints []*externaldnsendpoint.Endpoint) bool {
+	if r.Adoption == AdoptionDisabled || dnsRecord.Annotations[v1alpha1.AdoptRecordsAnnotation] != "true" {
+		return false
+	}
+	if len(r.AdoptionNamespaces) > 0 && !slices.Contains(r.AdoptionNamespaces, dnsRecord.Namespace) {
+		return false
+	}
+	for _, ep := range specEndpoints {
+		if registry.HasUnverifiedOwner(ep.DNSName) {
+			log.FromContext(ctx).Info("Not adopting records whose ownership records have no valid signature", "dnsName", ep.DNSName)
+			return false
+		}
+	}
+	return true
+}
+
+// newRegistry returns the TXT registry holding ownership of the records of the given owner in the provider zone
+func (r *DNSRecordReconciler) newRegistry(ctx context.Context, dnsProvider provider.Provider, ownerID string, managedDNSRecordTypes, excludeDNSRecordTypes []string) (*externaldnsregistry.TXTRegistry, error) {
+	registry, err := externaldnsregistry.NewTXTRegistry(ctx, dnsProvider, txtRegistryPrefix, txtRegistrySuffix,
+		ownerID, txtRegistryCacheInterval, txtRegistryWildcardReplacement, managedDNSRecordTypes,
+		excludeDNSRecordTypes, txtRegistryEncryptEnabled, []byte(txtRegistryEncryptAESKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(r.RegistrySigningKey) > 0 {
+		registry = registry.WithSigningKey(r.RegistrySigningKey)
+		if r.RegistrySigningMigration {
+			registry = registry.WithUnsignedMigration()
+		}
+	}
+	return registry, nil
+}
+
+// RegistryRecords returns the records of the zone of the given provider read through the TXT registry, i.e. labelled
+// with the owners held in their registry TXT records, for tooling reading zones outside of the controller
+func RegistryRecords(ctx context.Context, dnsProvider provider.Provider, managedDNSRecordTypes []string) ([]*externaldnsendpoint.Endpoint, error) {
+	// reading records is independent of the owner of the registry
+	registry, err := externaldnsregistry.NewTXTRegistry(ctx, dnsProvider, txtRegistryPrefix, txtRegistrySuffix,
+		"registry-records", txtRegistryCacheInterval, txtRegistryWildcardReplacement, managedDNSRecordTypes,
+		nil, txtRegistryEncryptEnabled, []byte(txtRegistryEncryptAESKey))
+	if err != nil {
+		return nil, err
+	}
+	return registry.Records(ctx)
+}
+
+// ownedRecords converts the given endpoint keys to owned records, returning nil if there are none.
+func ownedRecords(keys []externaldnsendpoint.EndpointKey) []v1alpha1.OwnedRecord {
+	var owned []v1alpha1.OwnedRecord
+	for _, key := range keys {
+		owned = append(owned, v1alpha1.OwnedRecord{
+			DNSName:       key.DNSName,
+			RecordType:    key.RecordType,
+			SetIdentifier: key.SetIdentifier,
+		})
+	}
+	return owned
 }
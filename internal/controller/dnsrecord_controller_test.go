@@ -31,8 +31,10 @@ import (
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
 
 	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/provider"
 	"github.com/kuadrant/dns-operator/pkg/builder"
 )
 
@@ -158,6 +160,56 @@ var _ = Describe("DNSRecordReconciler", func() {
 			Expect(err).To(MatchError(ContainSubstring("Only HTTP or HTTPS protocols are allowed")))
 			Expect(err).To(MatchError(ContainSubstring("Failure threshold must be greater than 0")))
 		})
+
+		It("prevents creation of records with a resync interval below the minimum", func(ctx SpecContext) {
+			dnsRecord = &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar.example.com",
+					Namespace: testNamespace,
+				},
+				Spec: v1alpha1.DNSRecordSpec{
+					RootHost: "bar.example.com",
+					ProviderRef: v1alpha1.ProviderRef{
+						Name: dnsProviderSecret.Name,
+					},
+					Endpoints:      getTestEndpoints("bar.example.com", "127.0.0.1"),
+					ResyncInterval: &metav1.Duration{Duration: time.Second},
+				},
+			}
+			err := k8sClient.Create(ctx, dnsRecord)
+			Expect(err).To(MatchError(ContainSubstring("resyncInterval must be at least 30s")))
+		})
+
+		It("should not publish records of unmanaged record types", func(ctx SpecContext) {
+			dnsRecord = &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo.example.com",
+					Namespace: testNamespace,
+				},
+				Spec: v1alpha1.DNSRecordSpec{
+					RootHost: "foo.example.com",
+					ProviderRef: v1alpha1.ProviderRef{
+						Name: dnsProviderSecret.Name,
+					},
+					Endpoints: []*externaldnsendpoint.Endpoint{
+						externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeMX, "10 mail.example.com"),
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+			Eventually(func(g Gomega) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(dnsRecord.Status.Conditions).To(
+					ContainElement(MatchFields(IgnoreExtras, Fields{
+						"Type":    Equal(string(v1alpha1.ConditionTypeReady)),
+						"Status":  Equal(metav1.ConditionFalse),
+						"Reason":  Equal("ValidationError"),
+						"Message": ContainSubstring("record type MX is not one of the managed record types"),
+					})),
+				)
+			}, TestTimeoutMedium, time.Second).Should(Succeed())
+		})
 	})
 
 	It("handles records with similar root hosts", func(ctx SpecContext) {
@@ -273,6 +325,69 @@ var _ = Describe("DNSRecordReconciler", func() {
 		}, 5*time.Second, time.Second, ctx).Should(Succeed())
 	})
 
+	It("retains the provider records of a record with the Retain deletion policy", func(ctx SpecContext) {
+		dnsRecord = &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "retained.example.com",
+				Namespace: testNamespace,
+			},
+			Spec: v1alpha1.DNSRecordSpec{
+				OwnerID:  "retainedowner",
+				RootHost: "retained.example.com",
+				ProviderRef: v1alpha1.ProviderRef{
+					Name: dnsProviderSecret.Name,
+				},
+				Endpoints:      getTestEndpoints("retained.example.com", "127.0.0.1"),
+				DeletionPolicy: v1alpha1.DeletionPolicyRetain,
+			},
+		}
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status": Equal(metav1.ConditionTrue),
+				})),
+			)
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+
+		Expect(k8sClient.Delete(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega, ctx context.Context) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).To(MatchError(ContainSubstring("not found")))
+		}, 5*time.Second, time.Second, ctx).Should(Succeed())
+
+		By("creating a record for the same host with another owner")
+		dnsRecord2 = &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "retained-2.example.com",
+				Namespace: testNamespace,
+			},
+			Spec: v1alpha1.DNSRecordSpec{
+				OwnerID:  "otherowner",
+				RootHost: "retained.example.com",
+				ProviderRef: v1alpha1.ProviderRef{
+					Name: dnsProviderSecret.Name,
+				},
+				Endpoints: getTestEndpoints("retained.example.com", "127.0.0.1"),
+			},
+		}
+		Expect(k8sClient.Create(ctx, dnsRecord2)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord2), dnsRecord2)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord2.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status": Equal(metav1.ConditionTrue),
+				})),
+			)
+			g.Expect(dnsRecord2.Status.DomainOwners).To(ConsistOf("retainedowner", "otherowner"))
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+	})
+
 	It("should have ready condition with status true", func() {
 		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
 		Eventually(func(g Gomega) {
@@ -295,6 +410,74 @@ var _ = Describe("DNSRecordReconciler", func() {
 		}, TestTimeoutMedium, time.Second).Should(Succeed())
 	})
 
+	It("should clear the spec changed time once the record is validated", func() {
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":               Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status":             Equal(metav1.ConditionTrue),
+					"ObservedGeneration": Equal(dnsRecord.Generation),
+				})),
+			)
+			g.Expect(dnsRecord.Status.SpecChangedAt).To(BeNil())
+			g.Expect(dnsRecord.Status.Conditions).ToNot(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type": Equal(string(v1alpha1.ConditionTypePublishSLOExceeded)),
+				})),
+			)
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+	})
+
+	It("should correct records that drifted in the provider zone", func(ctx SpecContext) {
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status": Equal(metav1.ConditionTrue),
+				})),
+			)
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+
+		By("editing the record in the provider zone")
+		providerFactory, err := provider.NewFactory(k8sClient, []string{"inmemory"})
+		Expect(err).NotTo(HaveOccurred())
+		dnsProvider, err := providerFactory.ProviderFor(ctx, dnsRecord, provider.Config{
+			DomainFilter: externaldnsendpoint.NewDomainFilter([]string{testZoneDomainName}),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		zoneEndpoints, err := dnsProvider.Records(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		changes := &externaldnsplan.Changes{}
+		for _, ep := range zoneEndpoints {
+			if ep.DNSName == "foo.example.com" && ep.RecordType == externaldnsendpoint.RecordTypeA {
+				edited := ep.DeepCopy()
+				edited.Targets = externaldnsendpoint.Targets{"127.0.0.9"}
+				changes.UpdateOld = append(changes.UpdateOld, ep)
+				changes.UpdateNew = append(changes.UpdateNew, edited)
+			}
+		}
+		Expect(changes.UpdateNew).To(HaveLen(1))
+		Expect(dnsProvider.ApplyChanges(ctx, changes)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.WriteCounter).To(BeNumerically(">", int64(0)))
+			zoneEndpoints, err := dnsProvider.Records(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(zoneEndpoints).To(ContainElement(PointTo(MatchFields(IgnoreExtras, Fields{
+				"DNSName": Equal("foo.example.com"),
+				"Targets": ConsistOf("127.0.0.1"),
+			}))))
+		}, TestTimeoutLong, time.Second).Should(Succeed())
+	})
+
 	It("should use dnsrecord UID for ownerID if none set in spec and not allow it to be updated after", func() {
 		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
 		Eventually(func(g Gomega) {
@@ -512,6 +695,164 @@ var _ = Describe("DNSRecordReconciler", func() {
 		}, TestTimeoutLong, time.Second).Should(Succeed())
 	})
 
+	It("should reject a record colliding with an older record with the same owner", func() {
+		dnsRecord = &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-record-1",
+				Namespace: testNamespace,
+			},
+			Spec: v1alpha1.DNSRecordSpec{
+				OwnerID:  "owner1",
+				RootHost: "foo.example.com",
+				ProviderRef: v1alpha1.ProviderRef{
+					Name: dnsProviderSecret.Name,
+				},
+				Endpoints: getDefaultTestEndpoints(),
+			},
+		}
+		dnsRecord2 = &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-record-2",
+				Namespace: testNamespace,
+			},
+			Spec: v1alpha1.DNSRecordSpec{
+				OwnerID:  "owner1",
+				RootHost: "foo.example.com",
+				ProviderRef: v1alpha1.ProviderRef{
+					Name: dnsProviderSecret.Name,
+				},
+				Endpoints: getTestEndpoints("foo.example.com", "127.0.0.2"),
+			},
+		}
+
+		By("creating dnsrecord " + dnsRecord.Name + " with endpoint dnsName: `foo.example.com` and target: `127.0.0.1`")
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status": Equal(metav1.ConditionTrue),
+				})),
+			)
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+
+		By("creating dnsrecord " + dnsRecord2.Name + " with the same owner, endpoint dnsName: `foo.example.com` and target: `127.0.0.2`")
+		Expect(k8sClient.Create(ctx, dnsRecord2)).To(Succeed())
+
+		By("checking dnsrecord " + dnsRecord2.Name + " is rejected and " + dnsRecord.Name + " remains ready")
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord2), dnsRecord2)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord2.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":    Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status":  Equal(metav1.ConditionFalse),
+					"Reason":  Equal("EndpointCollision"),
+					"Message": ContainSubstring("collides with DNSRecord " + testNamespace + "/" + dnsRecord.Name),
+				})),
+			)
+			g.Expect(dnsRecord2.Status.WriteCounter).To(BeZero())
+
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status": Equal(metav1.ConditionTrue),
+				})),
+			)
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+	})
+
+	It("should not write to a frozen zone until it is unfrozen", func() {
+		By("freezing zone " + testZoneDomainName)
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsProviderSecret), dnsProviderSecret)).To(Succeed())
+			dnsProviderSecret.SetAnnotations(map[string]string{v1alpha1.FreezeZonesAnnotation: testZoneDomainName})
+			g.Expect(k8sClient.Update(ctx, dnsProviderSecret)).To(Succeed())
+		}, TestTimeoutShort, time.Second).Should(Succeed())
+
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":    Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status":  Equal(metav1.ConditionFalse),
+					"Reason":  Equal("ZoneFrozen"),
+					"Message": Equal("Changes are pending while writes to the zone are stopped: zone frozen: writes to zone example.com are stopped"),
+				})),
+			)
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+
+		By("unfreezing zone " + testZoneDomainName)
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsProviderSecret), dnsProviderSecret)).To(Succeed())
+			dnsProviderSecret.SetAnnotations(nil)
+			g.Expect(k8sClient.Update(ctx, dnsProviderSecret)).To(Succeed())
+		}, TestTimeoutShort, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeReady)),
+					"Status": Equal(metav1.ConditionTrue),
+				})),
+			)
+		}, TestTimeoutLong, time.Second).Should(Succeed())
+	})
+
+	It("should publish a target override until it expires", func() {
+		expiresAt := time.Now().Add(10 * time.Second).UTC().Format(time.RFC3339)
+		dnsRecord.SetAnnotations(map[string]string{
+			v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": ["127.0.0.2"]}`,
+			v1alpha1.OverrideExpiresAnnotation: expiresAt,
+		})
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":    Equal(string(v1alpha1.ConditionTypeOverridden)),
+					"Status":  Equal(metav1.ConditionTrue),
+					"Reason":  Equal("OverrideActive"),
+					"Message": Equal("Targets of foo.example.com are overridden until " + expiresAt),
+				})),
+			)
+			g.Expect(dnsRecord.Status.Endpoints).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"DNSName": Equal("foo.example.com"),
+					"Targets": ConsistOf("127.0.0.2"),
+				})),
+			))
+			g.Expect(dnsRecord.Spec.Endpoints[0].Targets).To(ConsistOf("127.0.0.1"))
+		}, TestTimeoutMedium, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dnsRecord.Status.Conditions).To(
+				ContainElement(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(string(v1alpha1.ConditionTypeOverridden)),
+					"Status": Equal(metav1.ConditionFalse),
+					"Reason": Equal("OverrideExpired"),
+				})),
+			)
+			g.Expect(dnsRecord.Status.Endpoints).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"DNSName": Equal("foo.example.com"),
+					"Targets": ConsistOf("127.0.0.1"),
+				})),
+			))
+		}, TestTimeoutLong, time.Second).Should(Succeed())
+	})
+
 	It("should not allow second record to change the type", func() {
 		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
 		Eventually(func(g Gomega) {
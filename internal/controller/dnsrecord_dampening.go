@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/pkg/diff"
+)
+
+// routingChange is the generation of a record with only routing changes and the time it was first seen
+type routingChange struct {
+	generation int64
+	seenAt     time.Time
+}
+
+// dampenRoutingChange returns the time to wait before publishing the current generation of the given DNSRecord.
+// Generations changing only the weight or geo of the endpoints of the spec last published are not published until they
+// have been stable for the RoutingChangeDampening period, coalescing rapid oscillations into a single provider write.
+// All other changes are published immediately. The spec endpoints are compared as written, from the given previous
+// DNSRecord, as the published endpoints of the status include the target override and excluded targets.
+func (r *DNSRecordReconciler) dampenRoutingChange(previous, dnsRecord *v1alpha1.DNSRecord) time.Duration {
+	published, ok := r.publishedSpecs.Load(dnsRecord.UID)
+	if r.RoutingChangeDampening == 0 || !generationChanged(dnsRecord) || !ok ||
+		!onlyRoutingChanged(specEndpoints(published.(*v1alpha1.DNSRecordSpec)), specEndpoints(&previous.Spec)) {
+		r.routingChanges.Delete(dnsRecord.UID)
+		return 0
+	}
+
+	change := routingChange{generation: dnsRecord.Generation, seenAt: reconcileStart.Time}
+	if previous, ok := r.routingChanges.Load(dnsRecord.UID); ok && previous.(routingChange).generation == dnsRecord.Generation {
+		change = previous.(routingChange)
+	}
+	r.routingChanges.Store(dnsRecord.UID, change)
+
+	// the stable change is kept until the generation is published, for its publish to be attributed to the dampening
+	if wait := change.seenAt.Add(r.RoutingChangeDampening).Sub(reconcileStart.Time); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// dampenedPublish returns true if the current generation of the given DNSRecord is a routing change published once
+// stable
+func (r *DNSRecordReconciler) dampenedPublish(dnsRecord *v1alpha1.DNSRecord) bool {
+	change, ok := r.routingChanges.Load(dnsRecord.UID)
+	return ok && change.(routingChange).generation == dnsRecord.Generation
+}
+
+// specEndpoints returns the endpoints of the given spec with the provider specific properties of the spec applied
+func specEndpoints(spec *v1alpha1.DNSRecordSpec) []*externaldnsendpoint.Endpoint {
+	dnsRecord := &v1alpha1.DNSRecord{Spec: *spec.DeepCopy()}
+	dnsRecord.ApplyProviderSpecific()
+	return dnsRecord.Spec.Endpoints
+}
+
+// onlyRoutingChanged returns true if the desired endpoints differ from the current endpoints only in their weight and
+// geo provider specific properties
+func onlyRoutingChanged(current, desired []*externaldnsendpoint.Endpoint) bool {
+	changes := diff.Endpoints(current, desired)
+	if len(changes.Create) > 0 || len(changes.Delete) > 0 || len(changes.Update) == 0 {
+		return false
+	}
+	for _, update := range changes.Update {
+		if !update.OnlyProviderSpecificChanged() {
+			return false
+		}
+		withoutRoutingChanges := diff.Endpoints(
+			[]*externaldnsendpoint.Endpoint{withoutRouting(update.Current)},
+			[]*externaldnsendpoint.Endpoint{withoutRouting(update.Desired)})
+		if withoutRoutingChanges.HasChanges() {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutRouting returns a copy of the given endpoint without its weight and geo provider specific properties
+func withoutRouting(ep *externaldnsendpoint.Endpoint) *externaldnsendpoint.Endpoint {
+	ep = ep.DeepCopy()
+	ep.ProviderSpecific = slices.DeleteFunc(ep.ProviderSpecific, func(property externaldnsendpoint.ProviderSpecificProperty) bool {
+		return isRoutingProperty(property.Name)
+	})
+	return ep
+}
+
+// isRoutingProperty returns true if the named provider specific property sets the weight or geo of an endpoint, either
+// provider agnostic, e.g. weight, or namespaced to a provider, e.g. aws/weight or aws/geolocation-country-code
+func isRoutingProperty(name string) bool {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name == v1alpha1.ProviderSpecificWeight || name == v1alpha1.ProviderSpecificGeoCode ||
+		strings.HasPrefix(name, "geolocation-")
+}
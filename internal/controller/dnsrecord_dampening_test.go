@@ -0,0 +1,121 @@
+//go:build unit
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestOnlyRoutingChanged(t *testing.T) {
+	weighted := func(name, weight string) *externaldnsendpoint.Endpoint {
+		return externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeCNAME, "lb.example.org").
+			WithSetIdentifier("eu").WithProviderSpecific(name, weight)
+	}
+	tests := []struct {
+		name    string
+		current *externaldnsendpoint.Endpoint
+		desired *externaldnsendpoint.Endpoint
+		want    bool
+	}{
+		{
+			name:    "weight changed",
+			current: weighted(v1alpha1.ProviderSpecificWeight, "100"),
+			desired: weighted(v1alpha1.ProviderSpecificWeight, "200"),
+			want:    true,
+		},
+		{
+			name:    "namespaced weight changed",
+			current: weighted("aws/weight", "100"),
+			desired: weighted("aws/weight", "200"),
+			want:    true,
+		},
+		{
+			name:    "namespaced geo changed",
+			current: weighted("aws/geolocation-country-code", "IE"),
+			desired: weighted("aws/geolocation-country-code", "FR"),
+			want:    true,
+		},
+		{
+			name:    "other provider specific property changed",
+			current: weighted("aws/evaluate-target-health", "true"),
+			desired: weighted("aws/evaluate-target-health", "false"),
+			want:    false,
+		},
+		{
+			name:    "targets changed",
+			current: weighted(v1alpha1.ProviderSpecificWeight, "100"),
+			desired: externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeCNAME, "lb.example.net").
+				WithSetIdentifier("eu").WithProviderSpecific(v1alpha1.ProviderSpecificWeight, "200"),
+			want: false,
+		},
+		{
+			name:    "unchanged",
+			current: weighted(v1alpha1.ProviderSpecificWeight, "100"),
+			desired: weighted(v1alpha1.ProviderSpecificWeight, "100"),
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := onlyRoutingChanged([]*externaldnsendpoint.Endpoint{tt.current}, []*externaldnsendpoint.Endpoint{tt.desired})
+			if got != tt.want {
+				t.Errorf("onlyRoutingChanged() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDampenRoutingChange(t *testing.T) {
+	start := time.Now()
+	publishedSpec := v1alpha1.DNSRecordSpec{
+		RootHost: "foo.example.com",
+		Endpoints: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1").
+				WithSetIdentifier("eu").WithProviderSpecific(v1alpha1.ProviderSpecificWeight, "100"),
+		},
+	}
+	previous := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("foo"), Generation: 2},
+		Spec:       *publishedSpec.DeepCopy(),
+		Status:     v1alpha1.DNSRecordStatus{ObservedGeneration: 1},
+	}
+	previous.Spec.Endpoints[0].ProviderSpecific = externaldnsendpoint.ProviderSpecific{{Name: v1alpha1.ProviderSpecificWeight, Value: "200"}}
+	r := &DNSRecordReconciler{RoutingChangeDampening: time.Minute}
+	dnsRecord := previous.DeepCopy()
+	// the published endpoints of the status, and the endpoints being published, differ from the spec by the override
+	dnsRecord.Spec.Endpoints[0].Targets = externaldnsendpoint.Targets{"172.32.200.1"}
+	dnsRecord.Status.Endpoints = []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "172.32.200.1").
+			WithSetIdentifier("eu").WithProviderSpecific(v1alpha1.ProviderSpecificWeight, "100"),
+	}
+
+	reconcileStart = metav1.NewTime(start)
+	if wait := r.dampenRoutingChange(previous, dnsRecord); wait != 0 {
+		t.Errorf("dampenRoutingChange() = %s without a published spec, want 0", wait)
+	}
+
+	r.publishedSpecs.Store(previous.UID, &publishedSpec)
+	if wait := r.dampenRoutingChange(previous, dnsRecord); wait != time.Minute {
+		t.Errorf("dampenRoutingChange() = %s, want %s", wait, time.Minute)
+	}
+	reconcileStart = metav1.NewTime(start.Add(2 * time.Minute))
+	if wait := r.dampenRoutingChange(previous, dnsRecord); wait != 0 {
+		t.Errorf("dampenRoutingChange() = %s once stable, want 0", wait)
+	}
+	if !r.dampenedPublish(dnsRecord) {
+		t.Errorf("dampenedPublish() = false, want the stable routing change attributed to the dampening")
+	}
+
+	previous.Spec.Endpoints[0].Targets = externaldnsendpoint.Targets{"127.0.0.2"}
+	previous.Generation, dnsRecord.Generation = 3, 3
+	if wait := r.dampenRoutingChange(previous, dnsRecord); wait != 0 {
+		t.Errorf("dampenRoutingChange() = %s for a target change, want 0", wait)
+	}
+}
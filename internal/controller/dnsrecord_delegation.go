@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+var ErrZoneNotDelegated = errors.New("zone not delegated")
+
+// lookupNS resolves the NS records of a zone as delegated from the public internet, by querying the authoritative name
+// servers of its parent zone rather than trusting the cluster resolver, which may serve split horizon or stub zones
+var lookupNS = lookupDelegation
+
+// lookupParentNS resolves the NS records of the parent zone of a zone
+var lookupParentNS = net.DefaultResolver.LookupNS
+
+// queryDelegation queries the given name server, without recursion, for the NS records of the given zone. The NS
+// records are answered by a name server authoritative for the zone, or referred to by one of its parent zone.
+var queryDelegation = func(ctx context.Context, nameServer, zoneDomainName string) ([]*net.NS, error) {
+	address := net.JoinHostPort(nameServer, "53")
+	response, err := exchange(ctx, "udp", address, zoneDomainName, dnsmessage.TypeNS)
+	if err == nil && response.Truncated {
+		response, err = exchange(ctx, "tcp", address, zoneDomainName, dnsmessage.TypeNS)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+	default:
+		return nil, fmt.Errorf("name server %s answered %s", nameServer, response.RCode)
+	}
+
+	var nss []*net.NS
+	for _, record := range append(response.Answers, response.Authorities...) {
+		if ns, ok := record.Body.(*dnsmessage.NSResource); ok && normalizeNS(record.Header.Name.String()) == normalizeNS(zoneDomainName) {
+			nss = append(nss, &net.NS{Host: ns.NS.String()})
+		}
+	}
+	return nss, nil
+}
+
+// lookupDelegation resolves the NS records of the given zone from the authoritative name servers of its parent zone,
+// the closest enclosing domain with NS records, returning those of the first name server answering
+func lookupDelegation(ctx context.Context, zoneDomainName string) ([]*net.NS, error) {
+	var parentNSs []*net.NS
+	parent := strings.TrimSuffix(zoneDomainName, ".")
+	for len(parentNSs) == 0 {
+		var ok bool
+		if _, parent, ok = strings.Cut(parent, "."); !ok || parent == "" {
+			return nil, fmt.Errorf("no parent zone of zone %s resolved", zoneDomainName)
+		}
+		nss, err := lookupParentNS(ctx, parent)
+		var dnsErr *net.DNSError
+		if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+			return nil, fmt.Errorf("unable to resolve the name servers of parent zone %s: %w", parent, err)
+		}
+		parentNSs = nss
+	}
+
+	var errs []error
+	for _, parentNS := range parentNSs {
+		nss, err := queryDelegation(ctx, normalizeNS(parentNS.Host), zoneDomainName)
+		if err == nil {
+			return nss, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// checkZoneDelegation returns an ErrZoneNotDelegated error if the NS delegation of the given zone does not resolve,
+// or resolves to none of the name servers of the zone in the provider, given its records.
+// Records published to a zone that is not delegated to the provider are not resolvable by anyone.
+func checkZoneDelegation(ctx context.Context, zoneDomainName string, zoneEndpoints []*externaldnsendpoint.Endpoint) error {
+	nss, err := lookupNS(ctx, zoneDomainName)
+	if err != nil {
+		return fmt.Errorf("%w: unable to resolve NS records of zone %s: %v", ErrZoneNotDelegated, zoneDomainName, err)
+	}
+	if len(nss) == 0 {
+		return fmt.Errorf("%w: no NS records resolved for zone %s", ErrZoneNotDelegated, zoneDomainName)
+	}
+
+	var providerNSs []string
+	for _, ep := range zoneEndpoints {
+		if ep.RecordType == externaldnsendpoint.RecordTypeNS && strings.EqualFold(ep.DNSName, zoneDomainName) {
+			for _, target := range ep.Targets {
+				providerNSs = append(providerNSs, normalizeNS(target))
+			}
+		}
+	}
+	// not all providers return the name servers of the zone, the delegation resolving is all that can be checked
+	if len(providerNSs) == 0 {
+		return nil
+	}
+
+	var resolved []string
+	for _, ns := range nss {
+		if slices.Contains(providerNSs, normalizeNS(ns.Host)) {
+			return nil
+		}
+		resolved = append(resolved, normalizeNS(ns.Host))
+	}
+	return fmt.Errorf("%w: zone %s resolves to name servers %v, not the provider name servers %v", ErrZoneNotDelegated, zoneDomainName, resolved, providerNSs)
+}
+
+func normalizeNS(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestCheckZoneDelegation(t *testing.T) {
+	providerNS := externaldnsendpoint.NewEndpoint("example.com", externaldnsendpoint.RecordTypeNS, "ns-1.awsdns-01.org.", "ns-2.awsdns-02.com.")
+
+	tests := []struct {
+		name          string
+		resolved      []*net.NS
+		resolveErr    error
+		zoneEndpoints []*externaldnsendpoint.Endpoint
+		wantErr       bool
+	}{
+		{
+			name:          "delegated to provider name servers",
+			resolved:      []*net.NS{{Host: "NS-2.awsdns-02.com."}},
+			zoneEndpoints: []*externaldnsendpoint.Endpoint{providerNS},
+		},
+		{
+			name:          "delegated to other name servers",
+			resolved:      []*net.NS{{Host: "ns1.example.net."}},
+			zoneEndpoints: []*externaldnsendpoint.Endpoint{providerNS},
+			wantErr:       true,
+		},
+		{
+			name:          "delegation does not resolve",
+			resolveErr:    &net.DNSError{Err: "no such host", IsNotFound: true},
+			zoneEndpoints: []*externaldnsendpoint.Endpoint{providerNS},
+			wantErr:       true,
+		},
+		{
+			name:     "provider name servers unknown",
+			resolved: []*net.NS{{Host: "ns1.example.net."}},
+		},
+	}
+
+	defer func(lookup func(context.Context, string) ([]*net.NS, error)) { lookupNS = lookup }(lookupNS)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupNS = func(_ context.Context, _ string) ([]*net.NS, error) {
+				return tt.resolved, tt.resolveErr
+			}
+			err := checkZoneDelegation(context.Background(), "example.com", tt.zoneEndpoints)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkZoneDelegation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrZoneNotDelegated) {
+				t.Errorf("checkZoneDelegation() error = %v, want ErrZoneNotDelegated", err)
+			}
+		})
+	}
+}
+
+func TestLookupDelegation(t *testing.T) {
+	defer func(lookup func(context.Context, string) ([]*net.NS, error)) { lookupParentNS = lookup }(lookupParentNS)
+	defer func(query func(context.Context, string, string) ([]*net.NS, error)) {
+		queryDelegation = query
+	}(queryDelegation)
+
+	// dev.example.com is not a zone of its own, so the delegation of app.dev.example.com is held by example.com
+	lookupParentNS = func(_ context.Context, name string) ([]*net.NS, error) {
+		switch name {
+		case "example.com":
+			return []*net.NS{{Host: "ns1.example.net."}, {Host: "ns2.example.net."}}, nil
+		case "com":
+			return []*net.NS{{Host: "a.gtld-servers.net."}}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	var queried []string
+	queryDelegation = func(_ context.Context, nameServer, zoneDomainName string) ([]*net.NS, error) {
+		queried = append(queried, nameServer)
+		if nameServer == "ns1.example.net" {
+			return nil, errors.New("i/o timeout")
+		}
+		return []*net.NS{{Host: "ns-1.awsdns-01.org."}}, nil
+	}
+
+	nss, err := lookupDelegation(context.Background(), "app.dev.example.com")
+	if err != nil {
+		t.Fatalf("lookupDelegation() error = %v", err)
+	}
+	if len(nss) != 1 || nss[0].Host != "ns-1.awsdns-01.org." {
+		t.Errorf("lookupDelegation() = %v", nss)
+	}
+	if !slices.Equal(queried, []string{"ns1.example.net", "ns2.example.net"}) {
+		t.Errorf("lookupDelegation() queried %v, want the parent zone name servers in turn", queried)
+	}
+
+	lookupParentNS = func(_ context.Context, name string) ([]*net.NS, error) {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+	}
+	if _, err = lookupDelegation(context.Background(), "example.com"); err == nil {
+		t.Errorf("lookupDelegation() with the parent zone unresolved error = nil")
+	}
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	ownerplan "github.com/kuadrant/dns-operator/internal/external-dns/plan"
+)
+
+// zoneDrifted returns true if the records owned by the given owner in the provider zone differ from the given
+// published endpoints, e.g. because they were edited in the provider console. Only the records of the published
+// endpoints are compared, as other records of the owner may belong to other DNSRecords with the same owner.
+// The published endpoints are those last written to the zone, with any target override and exclusion applied, so
+// changes to the desired endpoints that were never published are not drift. Records shared with other owners hold the
+// targets of all their owners, so they have only drifted if targets of the given owner are missing.
+func zoneDrifted(zoneEndpoints, publishedEndpoints []*externaldnsendpoint.Endpoint, ownerID string) bool {
+	zoneByKey := map[externaldnsendpoint.EndpointKey]*externaldnsendpoint.Endpoint{}
+	coOwned := map[externaldnsendpoint.EndpointKey]bool{}
+	for _, ep := range zoneEndpoints {
+		owners := strings.Split(ep.Labels[externaldnsendpoint.OwnerLabelKey], ownerplan.OwnerLabelDeliminator)
+		if slices.Contains(owners, ownerID) {
+			zoneByKey[ep.Key()] = ep
+			coOwned[ep.Key()] = len(owners) > 1
+		}
+	}
+	for _, ep := range publishedEndpoints {
+		zoneEp, ok := zoneByKey[ep.Key()]
+		switch {
+		case !ok:
+			return true
+		case coOwned[ep.Key()]:
+			if slices.ContainsFunc(ep.Targets, func(target string) bool { return !slices.Contains(zoneEp.Targets, target) }) {
+				return true
+			}
+		case !zoneEp.Targets.Same(ep.Targets) || (ep.RecordTTL.IsConfigured() && zoneEp.RecordTTL != ep.RecordTTL):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+//go:build unit
+
+package controller
+
+import (
+	"testing"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestZoneDrifted(t *testing.T) {
+	owned := func(ep *externaldnsendpoint.Endpoint, owner string) *externaldnsendpoint.Endpoint {
+		ep.Labels[externaldnsendpoint.OwnerLabelKey] = owner
+		return ep
+	}
+	published := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"),
+	}
+
+	tests := []struct {
+		name string
+		zone []*externaldnsendpoint.Endpoint
+		want bool
+	}{
+		{
+			name: "zone holds the published endpoints",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"), "owner"),
+				owned(externaldnsendpoint.NewEndpointWithTTL("www.example.com", externaldnsendpoint.RecordTypeCNAME, 300, "foo.example.com"), "owner"),
+				owned(externaldnsendpoint.NewEndpoint("other.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.2"), "owner"),
+			},
+			want: false,
+		},
+		{
+			name: "targets edited in the zone",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.9"), "owner"),
+				owned(externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"), "owner"),
+			},
+			want: true,
+		},
+		{
+			name: "TTL edited in the zone",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 300, "127.0.0.1"), "owner"),
+				owned(externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"), "owner"),
+			},
+			want: true,
+		},
+		{
+			name: "record deleted from the zone",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"), "owner"),
+			},
+			want: true,
+		},
+		{
+			name: "record taken over by another owner",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"), "other"),
+				owned(externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"), "owner"),
+			},
+			want: true,
+		},
+		{
+			name: "record shared with another owner holds the published targets",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 300, "127.0.0.1", "127.0.0.2"), "other&&owner"),
+				owned(externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"), "owner"),
+			},
+			want: false,
+		},
+		{
+			name: "record shared with another owner is missing published targets",
+			zone: []*externaldnsendpoint.Endpoint{
+				owned(externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.2"), "owner&&other"),
+				owned(externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"), "owner"),
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zoneDrifted(tt.zone, published, "owner"); got != tt.want {
+				t.Errorf("zoneDrifted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
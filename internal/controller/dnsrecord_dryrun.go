@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/provider"
+)
+
+// dryRunLimits states what a dry run does not prove, reported with the changes the provider would accept
+const dryRunLimits = "dry run validates the changes without writing them, so failures only found when writing, e.g. quotas and rate limits, are not detected"
+
+// reconcileDryRun applies the changes of the given DNSRecord to its provider in dry run mode, validating them as far as
+// the provider allows without writing to the zone, and checks whether the provider credentials are allowed to write
+// to the zone where the provider supports it. Whether the changes would be accepted is reported with the DryRun
+// condition.
+func (r *DNSRecordReconciler) reconcileDryRun(ctx context.Context, previous, dnsRecord *v1alpha1.DNSRecord, dnsProvider provider.Provider) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	hadChanges, err := r.applyChanges(ctx, dnsRecord, dnsProvider, false)
+	if err != nil {
+		logger.Info("Provider would reject the changes", "error", err)
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeDryRun), metav1.ConditionFalse,
+			"ChangesRejected", fmt.Sprintf("The DNS provider would reject the changes: %v", provider.SanitizeError(err)))
+	} else {
+		allowed, reason, err := provider.CheckWritePermission(ctx, dnsProvider, dnsRecord.Status.ZoneID)
+		var permission string
+		switch {
+		case errors.Is(err, provider.ErrPermissionCheckUnsupported):
+			permission = "the permissions of the provider credentials are not checked as the provider does not support it"
+		case err != nil:
+			permission = fmt.Sprintf("the permissions of the provider credentials could not be checked: %v", provider.SanitizeError(err))
+		case allowed:
+			permission = "the provider credentials are allowed to write to the zone"
+		}
+
+		switch {
+		case err == nil && !allowed:
+			logger.Info("Provider credentials are not allowed to write to the zone", "reason", reason)
+			setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeDryRun), metav1.ConditionFalse,
+				"PermissionDenied", fmt.Sprintf("The DNS provider credentials are not allowed to write to the zone: %s", reason))
+		case hadChanges:
+			setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeDryRun), metav1.ConditionTrue,
+				"ChangesAccepted", fmt.Sprintf("The DNS provider would accept the changes, %s; %s", permission, dryRunLimits))
+		default:
+			setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeDryRun), metav1.ConditionTrue,
+				"NoChanges", fmt.Sprintf("The provider zone holds the records of all endpoints, %s", permission))
+		}
+	}
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+		"DryRun", "Not publishing, the operator is running in dry run mode")
+	// nothing is written in a dry run, so the generation is not observed and publishing it not yet timed
+	dnsRecord.Status.SpecChangedAt = previous.Status.SpecChangedAt
+	dnsRecord.Status.QueuedAt = reconcileStart
+
+	if !equality.Semantic.DeepEqual(previous.Status, dnsRecord.Status) {
+		if err = r.Status().Update(ctx, dnsRecord); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+}
+
+// reconcileDryRunDelete keeps the finalizer of the given deleted DNSRecord while in dry run mode, as its records can not
+// be deleted from the zone, so they are deleted once the operator runs out of dry run mode instead of being leaked.
+func (r *DNSRecordReconciler) reconcileDryRunDelete(ctx context.Context, previous, dnsRecord *v1alpha1.DNSRecord) (ctrl.Result, error) {
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+		"DryRun", "Deletion of the records from the zone is pending until the operator runs out of dry run mode")
+	if !equality.Semantic.DeepEqual(previous.Status, dnsRecord.Status) {
+		if err := r.Status().Update(ctx, dnsRecord); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+}
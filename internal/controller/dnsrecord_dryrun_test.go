@@ -0,0 +1,118 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/external-dns/provider/inmemory"
+	providerinmemory "github.com/kuadrant/dns-operator/internal/provider/inmemory"
+)
+
+func TestReconcileDryRun(t *testing.T) {
+	ctx := context.Background()
+	p := &providerinmemory.InMemoryDNSProvider{
+		InMemoryProvider: inmemory.NewInMemoryProvider(ctx, inmemory.InMemoryInitZones([]string{"example.com"}),
+			inmemory.InMemoryWithDryRun(true)),
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	previous := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Generation: 1},
+		Spec: v1alpha1.DNSRecordSpec{
+			RootHost: "foo.example.com",
+			Endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+		},
+		Status: v1alpha1.DNSRecordStatus{OwnerID: "owner", ZoneID: "example.com", ZoneDomainName: "example.com"},
+	}
+	r := &DNSRecordReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(previous).WithStatusSubresource(previous).Build(),
+		DryRun: true,
+	}
+	dnsRecord := previous.DeepCopy()
+	// as set by Reconcile on observing the new generation
+	specChangedAt := metav1.Now()
+	dnsRecord.Status.SpecChangedAt = &specChangedAt
+
+	if _, err := r.reconcileDryRun(ctx, previous, dnsRecord, p); err != nil {
+		t.Fatalf("reconcileDryRun() error = %v", err)
+	}
+	condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeDryRun))
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != "ChangesAccepted" {
+		t.Errorf("reconcileDryRun() DryRun condition = %v", condition)
+	}
+	if !strings.Contains(condition.Message, "not checked") || !strings.Contains(condition.Message, "quotas") {
+		t.Errorf("reconcileDryRun() DryRun condition message %q does not state the limits of the dry run", condition.Message)
+	}
+	condition = meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeReady))
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "DryRun" {
+		t.Errorf("reconcileDryRun() Ready condition = %v", condition)
+	}
+	if dnsRecord.Status.ObservedGeneration != 0 || dnsRecord.Status.SpecChangedAt != nil {
+		t.Errorf("reconcileDryRun() observed generation %d, spec changed at %v, want neither set",
+			dnsRecord.Status.ObservedGeneration, dnsRecord.Status.SpecChangedAt)
+	}
+
+	records, err := p.Records(ctx)
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("reconcileDryRun() wrote records %v to the zone", records)
+	}
+}
+
+func TestReconcileDryRunDeleteKeepsFinalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	now := metav1.Now()
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "default",
+			Generation:        1,
+			DeletionTimestamp: &now,
+			Finalizers:        []string{DNSRecordFinalizer},
+		},
+		Spec:   v1alpha1.DNSRecordSpec{RootHost: "foo.example.com"},
+		Status: v1alpha1.DNSRecordStatus{OwnerID: "owner", ZoneID: "example.com", ZoneDomainName: "example.com"},
+	}
+	r := &DNSRecordReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(dnsRecord).WithStatusSubresource(dnsRecord).Build(),
+		DryRun: true,
+	}
+
+	key := client.ObjectKeyFromObject(dnsRecord)
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	current := &v1alpha1.DNSRecord{}
+	if err := r.Get(context.Background(), key, current); err != nil {
+		t.Fatalf("Get() error = %v, want the deleted record kept", err)
+	}
+	if !controllerutil.ContainsFinalizer(current, DNSRecordFinalizer) {
+		t.Errorf("Reconcile() removed the finalizer in dry run mode")
+	}
+	condition := meta.FindStatusCondition(current.Status.Conditions, string(v1alpha1.ConditionTypeReady))
+	if condition == nil || condition.Reason != "DryRun" {
+		t.Errorf("Reconcile() Ready condition = %v", condition)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+	"github.com/kuadrant/dns-operator/internal/provider"
+	_ "github.com/kuadrant/dns-operator/internal/provider/inmemory"
+)
+
+func TestReconcileDeleteFrozenZone(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "inmemory-credentials",
+			Namespace:   "default",
+			Annotations: map[string]string{v1alpha1.FreezeZonesAnnotation: "frozen.example.com"},
+		},
+		Type: v1alpha1.SecretTypeKuadrantInmemory,
+		Data: map[string][]byte{v1alpha1.InmemInitZonesKey: []byte("frozen.example.com")},
+	}
+	now := metav1.Now()
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "default",
+			Generation:        1,
+			DeletionTimestamp: &now,
+			Finalizers:        []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			RootHost:    "foo.frozen.example.com",
+			ProviderRef: v1alpha1.ProviderRef{Name: secret.Name},
+			Endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.frozen.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+		},
+		Status: v1alpha1.DNSRecordStatus{OwnerID: "owner", ZoneID: "frozen.example.com", ZoneDomainName: "frozen.example.com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, dnsRecord).WithStatusSubresource(dnsRecord).Build()
+	providerFactory, err := provider.NewFactory(c, []string{"inmemory"})
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	r := &DNSRecordReconciler{Client: c, ProviderFactory: providerFactory}
+	healthCheckDeletes := func() float64 {
+		return testutil.ToFloat64(metrics.ProviderRequestCounter.WithLabelValues("inmemory", "frozen.example.com", "DeleteHealthCheck"))
+	}
+	deletesBefore := healthCheckDeletes()
+
+	key := client.ObjectKeyFromObject(dnsRecord)
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if !result.Requeue {
+		t.Errorf("Reconcile() did not requeue the deletion while the zone is frozen")
+	}
+	if got := healthCheckDeletes(); got != deletesBefore {
+		t.Errorf("Reconcile() deleted %v health checks in a frozen zone", got-deletesBefore)
+	}
+	current := &v1alpha1.DNSRecord{}
+	if err = c.Get(ctx, key, current); err != nil {
+		t.Fatalf("Get() error = %v, want the deleted record kept", err)
+	}
+	if !controllerutil.ContainsFinalizer(current, DNSRecordFinalizer) {
+		t.Errorf("Reconcile() removed the finalizer while the zone is frozen")
+	}
+	condition := meta.FindStatusCondition(current.Status.Conditions, string(v1alpha1.ConditionTypeReady))
+	if condition == nil || condition.Reason != "ZoneFrozen" {
+		t.Errorf("Reconcile() Ready condition = %v", condition)
+	}
+}
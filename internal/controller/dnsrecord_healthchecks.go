@@ -15,6 +15,12 @@ import (
 	"github.com/kuadrant/dns-operator/internal/provider"
 )
 
+// endpointHealthCheckResult is the result of reconciling the health check of a target of an endpoint
+type endpointHealthCheckResult struct {
+	provider.HealthCheckResult
+	endpoint *externaldns.Endpoint
+}
+
 // healthChecksConfig represents the user configuration for the health checks
 type healthChecksConfig struct {
 	Endpoint         string
@@ -24,7 +30,7 @@ type healthChecksConfig struct {
 }
 
 func (r *DNSRecordReconciler) ReconcileHealthChecks(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) error {
-	var results []provider.HealthCheckResult
+	var results []endpointHealthCheckResult
 	var err error
 
 	dnsProvider, err := r.getDNSProvider(ctx, dnsRecord)
@@ -35,13 +41,16 @@ func (r *DNSRecordReconciler) ReconcileHealthChecks(ctx context.Context, dnsReco
 	healthCheckReconciler := dnsProvider.HealthCheckReconciler()
 
 	// Get the configuration for the health checks. If no configuration is
-	// set, ensure that the health checks are deleted
+	// set, or health checks are disabled for the zone, ensure that the health checks are deleted
 	config := getHealthChecksConfig(dnsRecord)
+	if r.ZoneLimits[dnsRecord.Status.ZoneDomainName].DisableHealthChecks {
+		config = nil
+	}
 
 	for _, dnsEndpoint := range dnsRecord.Spec.Endpoints {
 		addresses := provider.GetExternalAddresses(dnsEndpoint, dnsRecord)
 		for _, address := range addresses {
-			probeStatus := r.getProbeStatus(address, dnsRecord)
+			probeStatus := r.getProbeStatus(dnsEndpoint, address, dnsRecord)
 
 			// no config means delete the health checks
 			if config == nil {
@@ -50,7 +59,7 @@ func (r *DNSRecordReconciler) ReconcileHealthChecks(ctx context.Context, dnsReco
 					return err
 				}
 
-				results = append(results, result)
+				results = append(results, endpointHealthCheckResult{result, dnsEndpoint})
 				continue
 			}
 
@@ -71,7 +80,7 @@ func (r *DNSRecordReconciler) ReconcileHealthChecks(ctx context.Context, dnsReco
 			}
 
 			result := healthCheckReconciler.Reconcile(ctx, spec, dnsEndpoint, probeStatus, address)
-			results = append(results, result)
+			results = append(results, endpointHealthCheckResult{result, dnsEndpoint})
 		}
 	}
 
@@ -79,12 +88,18 @@ func (r *DNSRecordReconciler) ReconcileHealthChecks(ctx context.Context, dnsReco
 	return result
 }
 
-func (r *DNSRecordReconciler) getProbeStatus(address string, dnsRecord *v1alpha1.DNSRecord) *v1alpha1.HealthCheckStatusProbe {
+// getProbeStatus returns the status of the probe for the given address of the given endpoint.
+// Probes recorded without an endpoint are matched on address only.
+func (r *DNSRecordReconciler) getProbeStatus(endpoint *externaldns.Endpoint, address string, dnsRecord *v1alpha1.DNSRecord) *v1alpha1.HealthCheckStatusProbe {
 	if dnsRecord.Status.HealthCheck == nil || dnsRecord.Status.HealthCheck.Probes == nil {
 		return nil
 	}
 	for _, probeStatus := range dnsRecord.Status.HealthCheck.Probes {
-		if probeStatus.IPAddress == address {
+		if probeStatus.IPAddress != address {
+			continue
+		}
+		if probeStatus.DNSName == "" ||
+			(probeStatus.DNSName == endpoint.DNSName && probeStatus.SetIdentifier == endpoint.SetIdentifier) {
 			return &probeStatus
 		}
 	}
@@ -92,7 +107,7 @@ func (r *DNSRecordReconciler) getProbeStatus(address string, dnsRecord *v1alpha1
 	return nil
 }
 
-func (r *DNSRecordReconciler) reconcileHealthCheckStatus(results []provider.HealthCheckResult, dnsRecord *v1alpha1.DNSRecord) error {
+func (r *DNSRecordReconciler) reconcileHealthCheckStatus(results []endpointHealthCheckResult, dnsRecord *v1alpha1.DNSRecord) error {
 	var previousCondition *metav1.Condition
 	probesCondition := &metav1.Condition{
 		Reason: "AllProbesSynced",
@@ -126,11 +141,13 @@ func (r *DNSRecordReconciler) reconcileHealthCheckStatus(results []provider.Heal
 		}
 
 		dnsRecord.Status.HealthCheck.Probes = append(dnsRecord.Status.HealthCheck.Probes, v1alpha1.HealthCheckStatusProbe{
-			ID:         result.ID,
-			IPAddress:  result.IPAddress,
-			Host:       result.Host,
-			Synced:     status,
-			Conditions: []metav1.Condition{result.Condition},
+			ID:            result.ID,
+			IPAddress:     result.IPAddress,
+			Host:          result.Host,
+			DNSName:       result.endpoint.DNSName,
+			SetIdentifier: result.endpoint.SetIdentifier,
+			Synced:        status,
+			Conditions:    []metav1.Condition{result.Condition},
 		})
 	}
 
@@ -0,0 +1,89 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestCheckHostClaims(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	created := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	newRecord := func(namespace, name, zoneID, dnsName string, createdAt metav1.Time) *v1alpha1.DNSRecord {
+		return &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: createdAt},
+			Spec: v1alpha1.DNSRecordSpec{
+				RootHost: dnsName,
+				Endpoints: []*externaldnsendpoint.Endpoint{
+					externaldnsendpoint.NewEndpoint(dnsName, externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+				},
+			},
+			Status: v1alpha1.DNSRecordStatus{ZoneID: zoneID},
+		}
+	}
+	dnsRecord := newRecord("team-b", "foo", "example.com", "foo.example.com", created)
+
+	tests := []struct {
+		name    string
+		other   *v1alpha1.DNSRecord
+		wantErr bool
+	}{
+		{
+			name:    "claimed by an older record in another namespace",
+			other:   newRecord("team-a", "foo", "example.com", "FOO.example.com", metav1.NewTime(created.Add(-time.Minute))),
+			wantErr: true,
+		},
+		{
+			name:  "older record in the same namespace",
+			other: newRecord("team-b", "bar", "example.com", "foo.example.com", metav1.NewTime(created.Add(-time.Minute))),
+		},
+		{
+			name:  "newer record in another namespace",
+			other: newRecord("team-a", "foo", "example.com", "foo.example.com", metav1.NewTime(created.Add(time.Minute))),
+		},
+		{
+			name:  "older record in another zone",
+			other: newRecord("team-a", "foo", "other.example.com", "foo.example.com", metav1.NewTime(created.Add(-time.Minute))),
+		},
+		{
+			name:  "older record for another hostname",
+			other: newRecord("team-a", "foo", "example.com", "bar.example.com", metav1.NewTime(created.Add(-time.Minute))),
+		},
+		{
+			name: "older record being deleted",
+			other: func() *v1alpha1.DNSRecord {
+				other := newRecord("team-a", "foo", "example.com", "foo.example.com", metav1.NewTime(created.Add(-time.Minute)))
+				other.DeletionTimestamp = &created
+				other.Finalizers = []string{DNSRecordFinalizer}
+				return other
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &DNSRecordReconciler{
+				Client:            fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.other).Build(),
+				EnforceHostClaims: true,
+			}
+			err := r.checkHostClaims(context.Background(), dnsRecord)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkHostClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && err.Error() != "hostname foo.example.com is claimed by DNSRecord team-a/foo" {
+				t.Errorf("checkHostClaims() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	ownerplan "github.com/kuadrant/dns-operator/internal/external-dns/plan"
+	externaldnsregistry "github.com/kuadrant/dns-operator/internal/external-dns/registry"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+	"github.com/kuadrant/dns-operator/internal/provider"
+)
+
+// orphanGracePeriodSweeps is the number of consecutive sweeps an owner must be found orphaned in before its records are
+// deleted
+const orphanGracePeriodSweeps = 3
+
+// OrphanRecordGC configures the periodic sweep of provider zones for orphaned records, records whose owners are no
+// longer the owner of any DNSRecord, e.g. records left behind when a cluster is rebuilt.
+type OrphanRecordGC struct {
+	// Interval is the time between sweeps. Zero disables the sweep.
+	Interval time.Duration
+	// Delete deletes orphaned records from the provider, they are only reported otherwise. Only the records whose
+	// owners are all within the ownership scope of this controller, i.e. start with DNSRecordReconciler.OwnerIDPrefix,
+	// are deleted, once found orphaned in orphanGracePeriodSweeps consecutive sweeps. Records retained by the deletion
+	// policy of their DNSRecord are never deleted.
+	Delete bool
+	// NamespaceScoped is set when DNSRecords are only watched in some namespaces. Sweeps are skipped, the owners of
+	// DNSRecords in other namespaces are unknown.
+	NamespaceScoped bool
+}
+
+// orphanSightings counts the consecutive sweeps each owner has been found orphaned in, keyed by swept zone and owner
+type orphanSightings struct {
+	mu    sync.Mutex
+	count map[string]int
+}
+
+// observe records the owners found orphaned in a sweep of the zone with the given key and returns the number of consecutive sweeps
+// each has been found orphaned in. Owners no longer orphaned are forgotten.
+func (s *orphanSightings) observe(zoneKey string, owners []string) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count == nil {
+		s.count = map[string]int{}
+	}
+	seen := map[string]int{}
+	for _, owner := range owners {
+		seen[owner] = s.count[zoneKey+"\x00"+owner] + 1
+	}
+	for key := range s.count {
+		if strings.HasPrefix(key, zoneKey+"\x00") {
+			delete(s.count, key)
+		}
+	}
+	for owner, count := range seen {
+		s.count[zoneKey+"\x00"+owner] = count
+	}
+	return seen
+}
+
+// ownerInScope returns true if every owner ID of the given owner label value is within the ownership scope of this
+// controller, i.e. was assigned by a controller with the same owner ID prefix. The records of such owners can only
+// have been published by this controller, or by the controller of the same cluster before it was rebuilt.
+func (r *DNSRecordReconciler) ownerInScope(owner string) bool {
+	if r.OwnerIDPrefix == "" || owner == "" {
+		return false
+	}
+	for _, id := range strings.Split(owner, ownerplan.OwnerLabelDeliminator) {
+		if !strings.HasPrefix(id, r.OwnerIDPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// markRecordsRetained marks the records of the owner of the given DNSRecord, being deleted with the Retain deletion
+// policy, as retained in their registry TXT records, so the sweep never deletes them once orphaned. The mark is kept
+// in the provider zone so it survives the loss of the cluster. Only records within the ownership scope are marked.
+func (r *DNSRecordReconciler) markRecordsRetained(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, dnsProvider provider.Provider) error {
+	ownerID := dnsRecord.Status.OwnerID
+	if !r.OrphanRecordGC.Delete || !r.ownerInScope(ownerID) {
+		return nil
+	}
+	registry, err := r.newRegistry(ctx, dnsProvider, ownerID, r.managedRecordTypes(), nil)
+	if err != nil {
+		return err
+	}
+	zoneEndpoints, err := registry.Records(ctx)
+	if err != nil {
+		return err
+	}
+	changes := &externaldnsplan.Changes{}
+	for _, ep := range zoneEndpoints {
+		owners := strings.Split(ep.Labels[externaldnsendpoint.OwnerLabelKey], ownerplan.OwnerLabelDeliminator)
+		if !slices.Contains(owners, ownerID) || ep.Labels[externaldnsregistry.RetainedLabelKey] == "true" {
+			continue
+		}
+		retained := ep.DeepCopy()
+		retained.Labels[externaldnsregistry.RetainedLabelKey] = "true"
+		changes.UpdateOld = append(changes.UpdateOld, ep)
+		changes.UpdateNew = append(changes.UpdateNew, retained)
+	}
+	if !changes.HasChanges() {
+		return nil
+	}
+	if err = registry.ApplyChanges(ctx, changes); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Marked records retained", "records", endpointNames(changes.UpdateNew))
+	return nil
+}
+
+// collectOrphanRecords sweeps the provider zones of all DNSRecords for orphaned records every OrphanRecordGC.Interval
+// until the given context is done.
+func (r *DNSRecordReconciler) collectOrphanRecords(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("orphan_record_gc")
+	ctx = log.IntoContext(ctx, logger)
+
+	ticker := time.NewTicker(r.OrphanRecordGC.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.sweepOrphanRecords(ctx); err != nil {
+				logger.Error(err, "Failed to sweep orphaned records")
+			}
+		}
+	}
+}
+
+// sweepOrphanRecords sweeps the provider zone of each DNSRecord, once per provider secret, for orphaned records.
+// Zones no DNSRecord is assigned to are never swept.
+func (r *DNSRecordReconciler) sweepOrphanRecords(ctx context.Context) error {
+	if r.OrphanRecordGC.NamespaceScoped {
+		log.FromContext(ctx).V(1).Info("DNSRecords are only watched in some namespaces, skipping orphaned records sweep")
+		return nil
+	}
+	dnsRecords, err := r.listAllDNSRecords(ctx)
+	if err != nil {
+		return err
+	}
+
+	swept := sets.New[string]()
+	var errs []error
+	for i := range dnsRecords.Items {
+		dnsRecord := &dnsRecords.Items[i]
+		if !dnsRecord.HasOwnerIDAssigned() || !dnsRecord.HasDNSZoneAssigned() {
+			continue
+		}
+		zoneKey := strings.Join([]string{dnsRecord.Namespace, dnsRecord.Spec.ProviderRef.Name, dnsRecord.Status.ZoneID}, "/")
+		if swept.Has(zoneKey) {
+			continue
+		}
+		swept.Insert(zoneKey)
+
+		dnsProvider, err := r.getDNSProvider(ctx, dnsRecord)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", dnsRecord.Status.ZoneDomainName, err))
+			continue
+		}
+		if err = r.sweepZoneOrphanRecords(ctx, dnsProvider, zoneKey, dnsRecord.Status.ZoneDomainName); err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", dnsRecord.Status.ZoneDomainName, provider.SanitizeError(err)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sweepZoneOrphanRecords reports, and if enabled deletes, the orphaned records in the given provider zone, identified
+// across sweeps by the given key. Records with any owner that is still the owner of a DNSRecord are left to their
+// remaining owners.
+func (r *DNSRecordReconciler) sweepZoneOrphanRecords(ctx context.Context, dnsProvider provider.Provider, zoneKey, zoneDomainName string) error {
+	logger := log.FromContext(ctx).WithValues("zone", zoneDomainName)
+
+	// any owner ID reads the ownership of all records in the zone
+	registry, err := r.newRegistry(ctx, dnsProvider, "orphan-record-gc", r.managedRecordTypes(), nil)
+	if err != nil {
+		return err
+	}
+	zoneEndpoints, err := registry.Records(ctx)
+	if err != nil {
+		return err
+	}
+
+	// owners are listed after reading the zone, so records published since by new DNSRecords are never orphaned
+	owners, err := r.recordOwners(ctx)
+	if err != nil {
+		return err
+	}
+	orphans := orphanedRecords(zoneEndpoints, owners)
+	sightings := r.orphanSightings.observe(zoneKey, maps.Keys(orphans))
+
+	count := 0
+	for _, endpoints := range orphans {
+		count += len(endpoints)
+	}
+	metrics.OrphanedRecords.WithLabelValues(zoneDomainName).Set(float64(count))
+	if count == 0 {
+		return nil
+	}
+
+	if !r.OrphanRecordGC.Delete || r.ShadowMode {
+		for owner, endpoints := range orphans {
+			logger.Info("Found orphaned records", "owner", owner, "records", endpointNames(endpoints))
+		}
+		return nil
+	}
+
+	var errs []error
+	for owner, endpoints := range orphans {
+		if slices.ContainsFunc(endpoints, func(ep *externaldnsendpoint.Endpoint) bool {
+			return ep.Labels[externaldnsregistry.RetainedLabelKey] == "true"
+		}) {
+			logger.Info("Found orphaned records, not deleting records retained by the deletion policy of their owner", "owner", owner,
+				"records", endpointNames(endpoints))
+			continue
+		}
+		if !r.ownerInScope(owner) {
+			logger.Info("Found orphaned records, not deleting records of an owner outside the ownership scope of this controller", "owner", owner,
+				"records", endpointNames(endpoints))
+			continue
+		}
+		if sightings[owner] < orphanGracePeriodSweeps {
+			logger.Info("Found orphaned records, deleting once still orphaned after the grace period", "owner", owner,
+				"records", endpointNames(endpoints), "sweeps", sightings[owner], "gracePeriodSweeps", orphanGracePeriodSweeps)
+			continue
+		}
+		ownerRegistry, err := r.newRegistry(ctx, dnsProvider, owner, r.managedRecordTypes(), nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changes := &externaldnsplan.Changes{Delete: endpoints}
+		if err = ownerRegistry.ApplyChanges(ctx, changes); err != nil {
+			errs = append(errs, fmt.Errorf("deleting records of owner %s: %w", owner, err))
+			continue
+		}
+		metrics.ZoneChurnCounter.WithLabelValues(zoneDomainName).Add(float64(churnOf(changes)))
+		logger.Info("Deleted orphaned records", "owner", owner, "records", endpointNames(endpoints))
+		count -= len(endpoints)
+	}
+	metrics.OrphanedRecords.WithLabelValues(zoneDomainName).Set(float64(count))
+	return errors.Join(errs...)
+}
+
+// listAllDNSRecords returns all DNSRecords, or an error if the list is partial
+func (r *DNSRecordReconciler) listAllDNSRecords(ctx context.Context) (*v1alpha1.DNSRecordList, error) {
+	dnsRecords := &v1alpha1.DNSRecordList{}
+	if err := r.List(ctx, dnsRecords); err != nil {
+		return nil, err
+	}
+	if dnsRecords.Continue != "" {
+		return nil, errors.New("list of DNSRecords is partial")
+	}
+	return dnsRecords, nil
+}
+
+// recordOwners returns the owner IDs of all DNSRecords, including those of DNSRecords yet to be assigned one
+func (r *DNSRecordReconciler) recordOwners(ctx context.Context) (sets.Set[string], error) {
+	dnsRecords, err := r.listAllDNSRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owners := sets.New[string]()
+	for i := range dnsRecords.Items {
+		dnsRecord := &dnsRecords.Items[i]
+		owners.Insert(dnsRecord.Status.OwnerID, dnsRecord.Spec.OwnerID, dnsRecord.GetUIDHash(), r.OwnerIDPrefix+dnsRecord.GetUIDHash())
+	}
+	owners.Delete("")
+	return owners, nil
+}
+
+// orphanedRecords returns the given zone endpoints with an owner, none of whose owners are in the given owners, keyed
+// by the value of their owner label
+func orphanedRecords(zoneEndpoints []*externaldnsendpoint.Endpoint, owners sets.Set[string]) map[string][]*externaldnsendpoint.Endpoint {
+	orphans := map[string][]*externaldnsendpoint.Endpoint{}
+	for _, ep := range zoneEndpoints {
+		owner := ep.Labels[externaldnsendpoint.OwnerLabelKey]
+		if owner == "" || slices.ContainsFunc(strings.Split(owner, ownerplan.OwnerLabelDeliminator), owners.Has) {
+			continue
+		}
+		orphans[owner] = append(orphans[owner], ep)
+	}
+	return orphans
+}
+
+// endpointNames returns the sorted names of the given endpoints, with their record type and set identifier
+func endpointNames(endpoints []*externaldnsendpoint.Endpoint) []string {
+	names := make([]string, 0, len(endpoints))
+	for _, ep := range endpoints {
+		names = append(names, endpointName(ep))
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,221 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/external-dns/provider/inmemory"
+	externaldnsregistry "github.com/kuadrant/dns-operator/internal/external-dns/registry"
+	"github.com/kuadrant/dns-operator/internal/provider"
+	providerinmemory "github.com/kuadrant/dns-operator/internal/provider/inmemory"
+)
+
+func TestSweepZoneOrphanRecords(t *testing.T) {
+	ctx := context.Background()
+	p := &providerinmemory.InMemoryDNSProvider{
+		InMemoryProvider: inmemory.NewInMemoryProvider(ctx, inmemory.InMemoryInitZones([]string{"example.com"})),
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default"},
+		Status:     v1alpha1.DNSRecordStatus{OwnerID: "cluster-a-live"},
+	}
+	r := &DNSRecordReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(dnsRecord).Build(),
+		OwnerIDPrefix: "cluster-a-",
+	}
+
+	published := map[string][]*externaldnsendpoint.Endpoint{
+		"cluster-a-live":                 {externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1")},
+		"cluster-a-live&&cluster-a-dead": {externaldnsendpoint.NewEndpoint("shared.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1")},
+		// e.g. the owner of a record published by this cluster before it was rebuilt
+		"cluster-a-dead": {
+			externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			externaldnsendpoint.NewEndpoint("baz.example.com", externaldnsendpoint.RecordTypeCNAME, "bar.example.com"),
+		},
+		// e.g. the owner of a record of another cluster sharing the zone
+		"cluster-b-live": {externaldnsendpoint.NewEndpoint("other.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1")},
+		// e.g. a record shared with another cluster
+		"cluster-a-dead&&cluster-b-live": {externaldnsendpoint.NewEndpoint("multi.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1")},
+		// e.g. the spec.ownerID of a record
+		"dead": {externaldnsendpoint.NewEndpoint("spec.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1")},
+	}
+	for owner, endpoints := range published {
+		registry, err := r.newRegistry(ctx, p, owner, DefaultManagedRecordTypes, nil)
+		if err != nil {
+			t.Fatalf("newRegistry() error = %v", err)
+		}
+		if err = registry.ApplyChanges(ctx, &plan.Changes{Create: endpoints}); err != nil {
+			t.Fatalf("ApplyChanges() error = %v", err)
+		}
+	}
+	err := p.ApplyChanges(ctx, &plan.Changes{Create: []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("unowned.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+	}})
+	if err != nil {
+		t.Fatalf("ApplyChanges() error = %v", err)
+	}
+
+	zoneRecords := func() []string {
+		records, err := p.Records(ctx)
+		if err != nil {
+			t.Fatalf("Records() error = %v", err)
+		}
+		return endpointNames(records)
+	}
+	before := zoneRecords()
+
+	sweep := func() {
+		t.Helper()
+		if err := r.sweepZoneOrphanRecords(ctx, p, "default/creds/example.com", "example.com"); err != nil {
+			t.Fatalf("sweepZoneOrphanRecords() error = %v", err)
+		}
+	}
+
+	sweep()
+	if got := zoneRecords(); !reflect.DeepEqual(got, before) {
+		t.Errorf("sweepZoneOrphanRecords() changed the zone without deletion enabled, got = %v, want %v", got, before)
+	}
+
+	r.OrphanRecordGC.Delete = true
+
+	// the first sweep found the owners orphaned already, the records are deleted once still orphaned after the grace period
+	for i := 1; i < orphanGracePeriodSweeps-1; i++ {
+		sweep()
+	}
+	if got := zoneRecords(); !reflect.DeepEqual(got, before) {
+		t.Errorf("sweepZoneOrphanRecords() deleted records within the grace period, got = %v, want %v", got, before)
+	}
+
+	sweep()
+	want := []string{
+		"A foo.example.com",
+		"A multi.example.com",
+		"A other.example.com",
+		"A shared.example.com",
+		"A spec.example.com",
+		"A unowned.example.com",
+		"TXT kuadrant-a-foo.example.com",
+		"TXT kuadrant-a-multi.example.com",
+		"TXT kuadrant-a-other.example.com",
+		"TXT kuadrant-a-shared.example.com",
+		"TXT kuadrant-a-spec.example.com",
+	}
+	if got := zoneRecords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sweepZoneOrphanRecords() zone records = %v, want %v", got, want)
+	}
+}
+
+func TestSweepOrphanRecordsNamespaceScoped(t *testing.T) {
+	// any list, or provider, would fail without a client
+	r := &DNSRecordReconciler{OrphanRecordGC: OrphanRecordGC{NamespaceScoped: true}}
+	if err := r.sweepOrphanRecords(context.Background()); err != nil {
+		t.Errorf("sweepOrphanRecords() error = %v, want sweep skipped", err)
+	}
+}
+
+func TestSweepZoneOrphanRecordsRetained(t *testing.T) {
+	ctx := context.Background()
+	p := &providerinmemory.InMemoryDNSProvider{
+		InMemoryProvider: inmemory.NewInMemoryProvider(ctx, inmemory.InMemoryInitZones([]string{"example.com"})),
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "retained",
+			Namespace:         "default",
+			Finalizers:        []string{DNSRecordFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: v1alpha1.DNSRecordSpec{DeletionPolicy: v1alpha1.DeletionPolicyRetain},
+		Status: v1alpha1.DNSRecordStatus{
+			OwnerID:        "cluster-a-retained",
+			ZoneID:         "example.com",
+			ZoneDomainName: "example.com",
+		},
+	}
+	r := &DNSRecordReconciler{
+		Client:          fake.NewClientBuilder().WithScheme(scheme).WithObjects(dnsRecord).Build(),
+		ProviderFactory: staticProviderFactory{p},
+		OrphanRecordGC:  OrphanRecordGC{Delete: true},
+		OwnerIDPrefix:   "cluster-a-",
+	}
+
+	registry, err := r.newRegistry(ctx, p, "cluster-a-retained", DefaultManagedRecordTypes, nil)
+	if err != nil {
+		t.Fatalf("newRegistry() error = %v", err)
+	}
+	err = registry.ApplyChanges(ctx, &plan.Changes{Create: []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+	}})
+	if err != nil {
+		t.Fatalf("ApplyChanges() error = %v", err)
+	}
+
+	// deleting the DNSRecord retains its records in the zone, marked retained, and removes the DNSRecord
+	if _, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err = r.Get(ctx, client.ObjectKeyFromObject(dnsRecord), &v1alpha1.DNSRecord{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Get() error = %v, want DNSRecord deleted", err)
+	}
+	zoneEndpoints, err := registry.Records(ctx)
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	for _, ep := range zoneEndpoints {
+		if ep.Labels[externaldnsregistry.RetainedLabelKey] != "true" {
+			t.Errorf("Reconcile() did not mark record %s retained, labels = %v", endpointName(ep), ep.Labels)
+		}
+	}
+
+	for i := 0; i < orphanGracePeriodSweeps+1; i++ {
+		if err = r.sweepZoneOrphanRecords(ctx, p, "default/creds/example.com", "example.com"); err != nil {
+			t.Fatalf("sweepZoneOrphanRecords() error = %v", err)
+		}
+	}
+	records, err := p.Records(ctx)
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	want := []string{"A foo.example.com", "TXT kuadrant-a-foo.example.com"}
+	if got := endpointNames(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("sweepZoneOrphanRecords() zone records = %v, want retained records %v", got, want)
+	}
+}
+
+// staticProviderFactory returns the same provider for every DNSRecord
+type staticProviderFactory struct {
+	provider.Provider
+}
+
+func (f staticProviderFactory) ProviderFor(context.Context, v1alpha1.ProviderAccessor, provider.Config) (provider.Provider, error) {
+	return f.Provider, nil
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// targetOverride is the break-glass override of the targets of a DNSRecord
+type targetOverride struct {
+	targets   map[string][]string
+	expiresAt time.Time
+}
+
+// overrideFor returns the target override annotated on the given DNSRecord, or nil if it has none
+func overrideFor(dnsRecord *v1alpha1.DNSRecord) (*targetOverride, error) {
+	value, ok := dnsRecord.Annotations[v1alpha1.OverrideTargetsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	override := &targetOverride{}
+	if err := json.Unmarshal([]byte(value), &override.targets); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", v1alpha1.OverrideTargetsAnnotation, err)
+	}
+	expires, ok := dnsRecord.Annotations[v1alpha1.OverrideExpiresAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("the %s annotation requires the %s annotation", v1alpha1.OverrideTargetsAnnotation, v1alpha1.OverrideExpiresAnnotation)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, expires)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", v1alpha1.OverrideExpiresAnnotation, err)
+	}
+	override.expiresAt = expiresAt
+	return override, nil
+}
+
+// applyOverride replaces the targets of the endpoints of the given DNSRecord with those of its target override until
+// the override expires, and sets the Overridden condition. The spec is only changed in memory, so the overridden targets
+// are published and recorded in the status endpoints while the desired state of the record is left untouched, and the
+// spec targets are published again once the override expires or is removed.
+// It returns the time the override expires, or nil if no override is active.
+func applyOverride(dnsRecord *v1alpha1.DNSRecord) (*time.Time, error) {
+	override, err := overrideFor(dnsRecord)
+	if err != nil || override == nil {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeOverridden))
+		return nil, err
+	}
+	if !reconcileStart.Time.Before(override.expiresAt) {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeOverridden), metav1.ConditionFalse,
+			"OverrideExpired", fmt.Sprintf("Override expired at %s", override.expiresAt.Format(time.RFC3339)))
+		return nil, nil
+	}
+
+	hosts := make([]string, 0, len(override.targets))
+	for dnsName, targets := range override.targets {
+		found := false
+		for _, ep := range dnsRecord.Spec.Endpoints {
+			if ep.DNSName == dnsName {
+				ep.Targets = slices.Clone(targets)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid override, no endpoint defined for host %s", dnsName)
+		}
+		hosts = append(hosts, dnsName)
+	}
+	if err = dnsRecord.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid override: %w", err)
+	}
+
+	slices.Sort(hosts)
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeOverridden), metav1.ConditionTrue,
+		"OverrideActive", fmt.Sprintf("Targets of %s are overridden until %s", strings.Join(hosts, ", "), override.expiresAt.Format(time.RFC3339)))
+	return &override.expiresAt, nil
+}
+
+// requeueBefore ensures the given result requeues no later than the given time
+func requeueBefore(result ctrl.Result, t time.Time) ctrl.Result {
+	if wait := t.Sub(reconcileStart.Time); result.RequeueAfter == 0 || wait < result.RequeueAfter {
+		result.RequeueAfter = max(wait, time.Second)
+	}
+	return result
+}
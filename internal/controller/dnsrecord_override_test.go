@@ -0,0 +1,121 @@
+//go:build unit
+
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestApplyOverride(t *testing.T) {
+	reconcileStart = metav1.Now()
+	expires := reconcileStart.Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantTargets []string
+		wantErr     string
+	}{
+		{
+			name:        "no override",
+			wantTargets: []string{"127.0.0.1"},
+		},
+		{
+			name: "active override",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": ["172.32.200.1"]}`,
+				v1alpha1.OverrideExpiresAnnotation: expires,
+			},
+			wantTargets: []string{"172.32.200.1"},
+		},
+		{
+			name: "expired override",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": ["172.32.200.1"]}`,
+				v1alpha1.OverrideExpiresAnnotation: reconcileStart.Add(-time.Hour).Format(time.RFC3339),
+			},
+			wantTargets: []string{"127.0.0.1"},
+		},
+		{
+			name: "invalid JSON",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": "172.32.200.1"}`,
+				v1alpha1.OverrideExpiresAnnotation: expires,
+			},
+			wantErr: "invalid kuadrant.io/override-targets annotation",
+		},
+		{
+			name: "missing expiry",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": ["172.32.200.1"]}`,
+			},
+			wantErr: "the kuadrant.io/override-targets annotation requires the kuadrant.io/override-expires annotation",
+		},
+		{
+			name: "invalid expiry",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": ["172.32.200.1"]}`,
+				v1alpha1.OverrideExpiresAnnotation: "in an hour",
+			},
+			wantErr: "invalid kuadrant.io/override-expires annotation",
+		},
+		{
+			name: "unknown dnsName",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"bar.example.com": ["172.32.200.1"]}`,
+				v1alpha1.OverrideExpiresAnnotation: expires,
+			},
+			wantErr: "invalid override, no endpoint defined for host bar.example.com",
+		},
+		{
+			name: "invalid targets",
+			annotations: map[string]string{
+				v1alpha1.OverrideTargetsAnnotation: `{"foo.example.com": ["lb.example.org"]}`,
+				v1alpha1.OverrideExpiresAnnotation: expires,
+			},
+			wantErr: "invalid override: invalid target lb.example.org for A endpoint foo.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dnsRecord := &v1alpha1.DNSRecord{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Annotations: tt.annotations},
+				Spec: v1alpha1.DNSRecordSpec{
+					RootHost: "foo.example.com",
+					Endpoints: []*externaldnsendpoint.Endpoint{
+						externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+					},
+				},
+			}
+
+			expiresAt, err := applyOverride(dnsRecord)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("applyOverride() error = %v, want %s", err, tt.wantErr)
+				}
+				if meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeOverridden)) != nil {
+					t.Errorf("applyOverride() set the Overridden condition of an invalid override")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyOverride() error = %v", err)
+			}
+			if got := dnsRecord.Spec.Endpoints[0].Targets; !got.Same(tt.wantTargets) {
+				t.Errorf("applyOverride() targets = %v, want %v", got, tt.wantTargets)
+			}
+			condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeOverridden))
+			if active := condition != nil && condition.Status == metav1.ConditionTrue; active != (expiresAt != nil) {
+				t.Errorf("applyOverride() Overridden condition = %v, expires at %v", condition, expiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+const (
+	// nameServerQueryTimeout is the time a query of an authoritative name server is given to complete
+	nameServerQueryTimeout = 5 * time.Second
+	// propagationCheckTimeout is the time all queries of a propagation check are given to complete, bounding the time
+	// the check adds to a reconcile however many endpoints and name servers are queried
+	propagationCheckTimeout = 10 * time.Second
+	// propagationCheckConcurrency is the number of queries of a propagation check made at once
+	propagationCheckConcurrency = 16
+)
+
+// propagatedRecordTypes are the record types, of endpoints, whose propagation to the authoritative name servers is verified
+var propagatedRecordTypes = map[string]dnsmessage.Type{
+	externaldnsendpoint.RecordTypeA:     dnsmessage.TypeA,
+	externaldnsendpoint.RecordTypeAAAA:  dnsmessage.TypeAAAA,
+	externaldnsendpoint.RecordTypeCNAME: dnsmessage.TypeCNAME,
+	externaldnsendpoint.RecordTypeTXT:   dnsmessage.TypeTXT,
+}
+
+// queryNameServer queries the given name server, without recursion, for the records of the given name and type and
+// returns their values. A name without records of the type has no values.
+var queryNameServer = func(ctx context.Context, nameServer, name string, recordType dnsmessage.Type) ([]string, error) {
+	address := net.JoinHostPort(nameServer, "53")
+	response, err := exchange(ctx, "udp", address, name, recordType)
+	if err == nil && response.Truncated {
+		response, err = exchange(ctx, "tcp", address, name, recordType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch response.RCode {
+	case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+	default:
+		return nil, fmt.Errorf("name server %s answered %s", nameServer, response.RCode)
+	}
+
+	var values []string
+	for _, answer := range response.Answers {
+		if answer.Header.Type != recordType {
+			continue
+		}
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			values = append(values, netip.AddrFrom4(body.A).String())
+		case *dnsmessage.AAAAResource:
+			values = append(values, netip.AddrFrom16(body.AAAA).String())
+		case *dnsmessage.CNAMEResource:
+			values = append(values, body.CNAME.String())
+		case *dnsmessage.TXTResource:
+			values = append(values, strings.Join(body.TXT, ""))
+		}
+	}
+	return values, nil
+}
+
+// exchange sends a query for the records of the given name and type to the given address and returns the response
+func exchange(ctx context.Context, network, address, name string, recordType dnsmessage.Type) (*dnsmessage.Message, error) {
+	queryName, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Intn(1 << 16))},
+		Questions: []dnsmessage.Question{{Name: queryName, Type: recordType, Class: dnsmessage.ClassINET}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, nameServerQueryTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	// messages sent over tcp are prefixed with their length
+	stream := network == "tcp"
+	if stream {
+		packet = append(binary.BigEndian.AppendUint16(nil, uint16(len(packet))), packet...)
+	}
+	if _, err = conn.Write(packet); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1<<16)
+	var n int
+	if stream {
+		if _, err = io.ReadFull(conn, buf[:2]); err != nil {
+			return nil, err
+		}
+		n, err = io.ReadFull(conn, buf[:binary.BigEndian.Uint16(buf[:2])])
+	} else {
+		n, err = conn.Read(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dnsmessage.Message{}
+	if err = response.Unpack(buf[:n]); err != nil {
+		return nil, err
+	}
+	if response.ID != query.ID {
+		return nil, errors.New("response does not match the query")
+	}
+	return response, nil
+}
+
+// checkPropagation returns an error if any authoritative name server of the given zone, as delegated from the public
+// internet, does not serve at least one of the targets of each of the given endpoints. Weighted and geo endpoints are
+// only served one at a time, so the targets of all endpoints with the same name and type are accepted.
+// The name servers are queried concurrently, all queries sharing the propagationCheckTimeout deadline.
+func checkPropagation(ctx context.Context, zoneDomainName string, endpoints []*externaldnsendpoint.Endpoint) error {
+	nss, err := lookupNS(ctx, zoneDomainName)
+	if err != nil {
+		return fmt.Errorf("unable to resolve the name servers of zone %s: %v", zoneDomainName, err)
+	}
+	if len(nss) == 0 {
+		return fmt.Errorf("no name servers resolved for zone %s", zoneDomainName)
+	}
+
+	type recordKey struct {
+		name       string
+		recordType string
+	}
+	var keys []recordKey
+	targets := map[recordKey][]string{}
+	for _, ep := range endpoints {
+		if _, ok := propagatedRecordTypes[ep.RecordType]; !ok || len(ep.Targets) == 0 {
+			continue
+		}
+		// CNAMEs at the zone apex are published by some providers as records of other types, e.g. route53 aliases
+		if ep.RecordType == externaldnsendpoint.RecordTypeCNAME && strings.EqualFold(ep.DNSName, zoneDomainName) {
+			continue
+		}
+		key := recordKey{name: strings.ToLower(ep.DNSName), recordType: ep.RecordType}
+		if _, ok := targets[key]; !ok {
+			keys = append(keys, key)
+		}
+		for _, target := range ep.Targets {
+			targets[key] = append(targets[key], normalizeTarget(target))
+		}
+	}
+
+	// all name servers are queried for all records concurrently, the first failure cancelling the queries left
+	ctx, cancel := context.WithTimeout(ctx, propagationCheckTimeout)
+	defer cancel()
+	var mu sync.Mutex
+	var firstErr error
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	sem := make(chan struct{}, propagationCheckConcurrency)
+	var wg sync.WaitGroup
+	for _, ns := range nss {
+		nameServer := normalizeNS(ns.Host)
+		for _, key := range keys {
+			wg.Add(1)
+			go func(nameServer string, key recordKey) {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					fail(fmt.Errorf("unable to query name server %s for %s record %s: %v", nameServer, key.recordType, key.name, ctx.Err()))
+					return
+				}
+				values, err := queryNameServer(ctx, nameServer, key.name, propagatedRecordTypes[key.recordType])
+				if err != nil {
+					fail(fmt.Errorf("unable to query name server %s for %s record %s: %v", nameServer, key.recordType, key.name, err))
+					return
+				}
+				if !slices.ContainsFunc(values, func(value string) bool {
+					return slices.Contains(targets[key], normalizeTarget(value))
+				}) {
+					fail(fmt.Errorf("name server %s does not serve %s record %s", nameServer, key.recordType, key.name))
+				}
+			}(nameServer, key)
+		}
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func normalizeTarget(target string) string {
+	return strings.ToLower(strings.TrimSuffix(target, "."))
+}
+
+// setPropagatedCondition sets the Propagated condition, if propagation is verified, to whether the authoritative name
+// servers of the zone serve the published endpoints of the given DNSRecord, with the time taken since they were
+// published. Returns false while the endpoints are not yet served.
+// Freshly published changes are never served yet, they are verified by the validation of the record that follows.
+func (r *DNSRecordReconciler) setPropagatedCondition(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, hadChanges bool) bool {
+	if !r.VerifyPropagation {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePropagated))
+		return true
+	}
+	if hadChanges {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypePropagated), metav1.ConditionFalse,
+			"AwaitingPropagation", "Changes are published, awaiting the authoritative name servers to serve them")
+		return false
+	}
+	condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePropagated))
+	if condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == dnsRecord.Generation {
+		return true
+	}
+
+	var sincePublished string
+	var elapsed time.Duration
+	if len(dnsRecord.Status.PublishedChanges) > 0 {
+		elapsed = reconcileStart.Sub(dnsRecord.Status.PublishedChanges[0].Time.Time)
+		sincePublished = fmt.Sprintf(", %s after the changes were published", elapsed.Round(time.Second))
+	}
+
+	if err := checkPropagation(ctx, dnsRecord.Status.ZoneDomainName, dnsRecord.Spec.Endpoints); err != nil {
+		log.FromContext(ctx).V(1).Info("Endpoints not yet served by the authoritative name servers", "reason", err.Error())
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypePropagated), metav1.ConditionFalse,
+			"NotPropagated", err.Error()+sincePublished)
+		return false
+	}
+	if sincePublished != "" {
+		metrics.NameServerPropagationDuration.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Observe(elapsed.Seconds())
+	}
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypePropagated), metav1.ConditionTrue,
+		"Propagated", "Endpoints are served by all authoritative name servers of the zone"+sincePublished)
+	return true
+}
@@ -0,0 +1,193 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestCheckPropagation(t *testing.T) {
+	endpoints := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1").WithSetIdentifier("eu"),
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.2").WithSetIdentifier("us"),
+		externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"),
+		externaldnsendpoint.NewEndpoint("example.com", externaldnsendpoint.RecordTypeCNAME, "lb.example.net"),
+		externaldnsendpoint.NewEndpoint("baz.example.com", externaldnsendpoint.RecordTypeNS, "ns1.example.net"),
+	}
+
+	tests := []struct {
+		name    string
+		served  map[string]map[string][]string
+		wantErr bool
+	}{
+		{
+			name: "served by all name servers",
+			served: map[string]map[string][]string{
+				"ns1.example.net": {"foo.example.com": {"127.0.0.2"}, "bar.example.com": {"foo.example.com."}},
+				"ns2.example.net": {"foo.example.com": {"127.0.0.1"}, "bar.example.com": {"FOO.example.com."}},
+			},
+		},
+		{
+			name: "not served by a name server",
+			served: map[string]map[string][]string{
+				"ns1.example.net": {"foo.example.com": {"127.0.0.2"}, "bar.example.com": {"foo.example.com."}},
+				"ns2.example.net": {"foo.example.com": {"127.0.0.1"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "previous target served",
+			served: map[string]map[string][]string{
+				"ns1.example.net": {"foo.example.com": {"127.0.0.3"}, "bar.example.com": {"foo.example.com."}},
+				"ns2.example.net": {"foo.example.com": {"127.0.0.1"}, "bar.example.com": {"foo.example.com."}},
+			},
+			wantErr: true,
+		},
+	}
+
+	defer func(lookup func(context.Context, string) ([]*net.NS, error)) { lookupNS = lookup }(lookupNS)
+	lookupNS = func(_ context.Context, _ string) ([]*net.NS, error) {
+		return []*net.NS{{Host: "ns1.example.net."}, {Host: "ns2.example.net."}}, nil
+	}
+	defer func(query func(context.Context, string, string, dnsmessage.Type) ([]string, error)) {
+		queryNameServer = query
+	}(queryNameServer)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryNameServer = func(_ context.Context, nameServer, name string, _ dnsmessage.Type) ([]string, error) {
+				return tt.served[nameServer][name], nil
+			}
+			err := checkPropagation(context.Background(), "example.com", endpoints)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkPropagation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPropagationConcurrent(t *testing.T) {
+	endpoints := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+		externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+	}
+
+	defer func(lookup func(context.Context, string) ([]*net.NS, error)) { lookupNS = lookup }(lookupNS)
+	lookupNS = func(_ context.Context, _ string) ([]*net.NS, error) {
+		return []*net.NS{{Host: "ns1.example.net."}, {Host: "ns2.example.net."}}, nil
+	}
+	defer func(query func(context.Context, string, string, dnsmessage.Type) ([]string, error)) {
+		queryNameServer = query
+	}(queryNameServer)
+
+	// every query waits for all the others to start, so the check only passes if they are made concurrently
+	var started sync.WaitGroup
+	started.Add(4)
+	allStarted := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(allStarted)
+	}()
+	queryNameServer = func(_ context.Context, _, _ string, _ dnsmessage.Type) ([]string, error) {
+		started.Done()
+		select {
+		case <-allStarted:
+			return []string{"127.0.0.1"}, nil
+		case <-time.After(time.Second):
+			return nil, errors.New("queries made sequentially")
+		}
+	}
+	if err := checkPropagation(context.Background(), "example.com", endpoints); err != nil {
+		t.Errorf("checkPropagation() error = %v", err)
+	}
+
+	// a failure cancels the queries left, and is the error reported
+	queryNameServer = func(ctx context.Context, nameServer, _ string, _ dnsmessage.Type) ([]string, error) {
+		if nameServer == "ns2.example.net" {
+			return nil, errors.New("refused")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if err := checkPropagation(context.Background(), "example.com", endpoints); err == nil || !strings.Contains(err.Error(), "refused") {
+		t.Errorf("checkPropagation() error = %v, want the refused query", err)
+	}
+}
+
+func TestSetPropagatedCondition(t *testing.T) {
+	defer func(lookup func(context.Context, string) ([]*net.NS, error)) { lookupNS = lookup }(lookupNS)
+	lookupNS = func(_ context.Context, _ string) ([]*net.NS, error) {
+		return []*net.NS{{Host: "ns1.example.net."}}, nil
+	}
+	defer func(query func(context.Context, string, string, dnsmessage.Type) ([]string, error)) {
+		queryNameServer = query
+	}(queryNameServer)
+	var served []string
+	queryNameServer = func(_ context.Context, _, _ string, _ dnsmessage.Type) ([]string, error) {
+		return served, nil
+	}
+
+	reconcileStart = metav1.Now()
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Generation: 1},
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+		},
+		Status: v1alpha1.DNSRecordStatus{
+			ZoneDomainName: "example.com",
+			PublishedChanges: []v1alpha1.PublishedChange{
+				{Time: metav1.NewTime(reconcileStart.Add(-time.Minute)), Generation: 1},
+			},
+		},
+	}
+	r := &DNSRecordReconciler{VerifyPropagation: true}
+
+	assertCondition := func(wantPropagated, propagated bool, wantStatus metav1.ConditionStatus, wantReason string) {
+		t.Helper()
+		if propagated != wantPropagated {
+			t.Errorf("setPropagatedCondition() = %v, want %v", propagated, wantPropagated)
+		}
+		condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePropagated))
+		if condition == nil || condition.Status != wantStatus || condition.Reason != wantReason {
+			t.Errorf("setPropagatedCondition() Propagated condition = %v, want %s %s", condition, wantStatus, wantReason)
+		}
+	}
+
+	served = []string{"127.0.0.1"}
+	assertCondition(false, r.setPropagatedCondition(context.Background(), dnsRecord, true), metav1.ConditionFalse, "AwaitingPropagation")
+
+	served = nil
+	assertCondition(false, r.setPropagatedCondition(context.Background(), dnsRecord, false), metav1.ConditionFalse, "NotPropagated")
+
+	served = []string{"127.0.0.1"}
+	assertCondition(true, r.setPropagatedCondition(context.Background(), dnsRecord, false), metav1.ConditionTrue, "Propagated")
+	if message := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePropagated)).Message; message !=
+		"Endpoints are served by all authoritative name servers of the zone, 1m0s after the changes were published" {
+		t.Errorf("setPropagatedCondition() Propagated condition message = %s", message)
+	}
+
+	// propagated endpoints of the same generation are not queried again
+	served = nil
+	assertCondition(true, r.setPropagatedCondition(context.Background(), dnsRecord, false), metav1.ConditionTrue, "Propagated")
+
+	r.VerifyPropagation = false
+	if !r.setPropagatedCondition(context.Background(), dnsRecord, false) ||
+		meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePropagated)) != nil {
+		t.Errorf("setPropagatedCondition() did not remove the Propagated condition when propagation is not verified")
+	}
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+// providerErrorReasons are the reasons of the Ready condition caused by failures of the provider
+var providerErrorReasons = []string{"ProviderError", "DNSProviderError"}
+
+// setPublishStatus records whether the endpoints of the current generation of the given DNSRecord are published,
+// setting the Published, Stale and ProviderError conditions from it and the Ready condition.
+// published is true if the provider zone was found to hold, or was updated with, the endpoints of the spec.
+func setPublishStatus(dnsRecord *v1alpha1.DNSRecord, published bool) {
+	if published {
+		dnsRecord.Status.LastAppliedGeneration = dnsRecord.Generation
+		dnsRecord.Status.EndpointsCount = len(dnsRecord.Spec.Endpoints)
+	}
+
+	applied := dnsRecord.Status.LastAppliedGeneration
+	if applied == dnsRecord.Generation {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypePublished), metav1.ConditionTrue,
+			"Published", fmt.Sprintf("Endpoints of generation %d are published to the provider", applied))
+	} else {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypePublished), metav1.ConditionFalse,
+			"NotPublished", fmt.Sprintf("Endpoints of generation %d are not published to the provider", dnsRecord.Generation))
+	}
+
+	if applied != 0 && applied != dnsRecord.Generation {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeStale), metav1.ConditionTrue,
+			"OutdatedGeneration", fmt.Sprintf("The provider holds the endpoints of generation %d", applied))
+	} else {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeStale))
+	}
+
+	ready := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeReady))
+	if ready != nil && ready.Status == metav1.ConditionFalse && slices.Contains(providerErrorReasons, ready.Reason) {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeProviderError), metav1.ConditionTrue,
+			ready.Reason, ready.Message)
+	} else {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeProviderError))
+	}
+}
+
+// setPublishSLOCondition sets the PublishSLOExceeded condition if the pending spec change has not been validated
+// in the provider within the publish SLO. The condition is removed once the change is validated.
+func (r *DNSRecordReconciler) setPublishSLOCondition(dnsRecord *v1alpha1.DNSRecord) {
+	setPublishLimitCondition(dnsRecord, v1alpha1.ConditionTypePublishSLOExceeded, "publish SLO", r.PublishSLO)
+}
+
+// setPublishDeadlineCondition sets the PublishDeadlineExceeded condition and metric if the pending spec change has not
+// been validated in the provider within the record's publish deadline. The condition is removed once the change is
+// validated. The metric is only exported for records with a publish deadline.
+func setPublishDeadlineCondition(dnsRecord *v1alpha1.DNSRecord) {
+	var deadline time.Duration
+	if dnsRecord.Spec.PublishDeadline != nil {
+		deadline = dnsRecord.Spec.PublishDeadline.Duration
+	}
+	exceeded := setPublishLimitCondition(dnsRecord, v1alpha1.ConditionTypePublishDeadlineExceeded, "publish deadline", deadline)
+	if deadline == 0 {
+		metrics.PublishDeadlineExceeded.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		return
+	}
+	value := 0.0
+	if exceeded {
+		value = 1
+	}
+	metrics.PublishDeadlineExceeded.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Set(value)
+}
+
+// setPublishLimitCondition sets the condition of the given type if the pending spec change has not been validated in
+// the provider within the given limit, returning whether the condition is set. The condition is removed once the change
+// is validated, and when the limit is zero.
+func setPublishLimitCondition(dnsRecord *v1alpha1.DNSRecord, conditionType v1alpha1.ConditionType, limitName string, limit time.Duration) bool {
+	if limit == 0 || dnsRecord.Status.SpecChangedAt == nil {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(conditionType))
+		return false
+	}
+	if elapsed := reconcileStart.Sub(dnsRecord.Status.SpecChangedAt.Time); elapsed > limit {
+		setDNSRecordCondition(dnsRecord, string(conditionType), metav1.ConditionTrue,
+			string(conditionType), fmt.Sprintf("spec change has not been validated in the provider after %s, %s is %s", elapsed.Round(time.Second), limitName, limit))
+	}
+	return meta.IsStatusConditionTrue(dnsRecord.Status.Conditions, string(conditionType))
+}
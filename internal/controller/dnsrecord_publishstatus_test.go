@@ -0,0 +1,175 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+func TestSetPublishStatus(t *testing.T) {
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec: v1alpha1.DNSRecordSpec{Endpoints: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.2"),
+		}},
+	}
+
+	// first generation fails to publish
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse, "ProviderError", "The DNS provider failed to ensure the record: boom")
+	setPublishStatus(dnsRecord, false)
+	if !meta.IsStatusConditionFalse(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePublished)) {
+		t.Errorf("setPublishStatus() expected Published condition to be false")
+	}
+	if meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeStale)) != nil {
+		t.Errorf("setPublishStatus() expected no Stale condition before any generation is published")
+	}
+	condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeProviderError))
+	if condition == nil || condition.Message != "The DNS provider failed to ensure the record: boom" {
+		t.Errorf("setPublishStatus() ProviderError condition = %v", condition)
+	}
+
+	// first generation is published
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse, "AwaitingValidation", "Awaiting validation")
+	setPublishStatus(dnsRecord, true)
+	if dnsRecord.Status.LastAppliedGeneration != 1 || dnsRecord.Status.EndpointsCount != 2 {
+		t.Errorf("setPublishStatus() lastAppliedGeneration = %d, endpointsCount = %d, want 1 and 2",
+			dnsRecord.Status.LastAppliedGeneration, dnsRecord.Status.EndpointsCount)
+	}
+	if !meta.IsStatusConditionTrue(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePublished)) {
+		t.Errorf("setPublishStatus() expected Published condition to be true")
+	}
+	if meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeProviderError)) != nil {
+		t.Errorf("setPublishStatus() expected ProviderError condition to be removed")
+	}
+
+	// second generation fails to publish, the provider still holds the first
+	dnsRecord.Generation = 2
+	dnsRecord.Spec.Endpoints = dnsRecord.Spec.Endpoints[:1]
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse, "ValidationError", "validation of DNSRecord failed")
+	setPublishStatus(dnsRecord, false)
+	if dnsRecord.Status.LastAppliedGeneration != 1 || dnsRecord.Status.EndpointsCount != 2 {
+		t.Errorf("setPublishStatus() lastAppliedGeneration = %d, endpointsCount = %d, want 1 and 2",
+			dnsRecord.Status.LastAppliedGeneration, dnsRecord.Status.EndpointsCount)
+	}
+	if !meta.IsStatusConditionFalse(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePublished)) {
+		t.Errorf("setPublishStatus() expected Published condition to be false")
+	}
+	if !meta.IsStatusConditionTrue(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeStale)) {
+		t.Errorf("setPublishStatus() expected Stale condition to be true")
+	}
+	if meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeProviderError)) != nil {
+		t.Errorf("setPublishStatus() expected no ProviderError condition for a validation error")
+	}
+}
+
+func TestSetPublishDeadlineCondition(t *testing.T) {
+	start := time.Now()
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "deadline", Namespace: "test"},
+		Status:     v1alpha1.DNSRecordStatus{SpecChangedAt: &metav1.Time{Time: start}},
+	}
+	exceeded := func() bool {
+		return meta.IsStatusConditionTrue(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePublishDeadlineExceeded))
+	}
+	gauge := func() float64 {
+		return testutil.ToFloat64(metrics.PublishDeadlineExceeded.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace))
+	}
+
+	// no publish deadline, no metric is exported
+	reconcileStart = metav1.NewTime(start.Add(time.Hour))
+	setPublishDeadlineCondition(dnsRecord)
+	if exceeded() {
+		t.Errorf("setPublishDeadlineCondition() set the condition without a publish deadline")
+	}
+	if metrics.PublishDeadlineExceeded.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace) {
+		t.Errorf("setPublishDeadlineCondition() exported the metric without a publish deadline")
+	}
+
+	// change pending within the publish deadline
+	dnsRecord.Spec.PublishDeadline = &metav1.Duration{Duration: time.Minute}
+	reconcileStart = metav1.NewTime(start.Add(30 * time.Second))
+	setPublishDeadlineCondition(dnsRecord)
+	if exceeded() || gauge() != 0 {
+		t.Errorf("setPublishDeadlineCondition() exceeded = %t, metric = %v within the publish deadline", exceeded(), gauge())
+	}
+
+	// change pending past the publish deadline
+	reconcileStart = metav1.NewTime(start.Add(2 * time.Minute))
+	setPublishDeadlineCondition(dnsRecord)
+	condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypePublishDeadlineExceeded))
+	if condition == nil || condition.Message != "spec change has not been validated in the provider after 2m0s, publish deadline is 1m0s" {
+		t.Errorf("setPublishDeadlineCondition() condition = %v", condition)
+	}
+	if gauge() != 1 {
+		t.Errorf("setPublishDeadlineCondition() metric = %v, want 1", gauge())
+	}
+
+	// change validated
+	dnsRecord.Status.SpecChangedAt = nil
+	setPublishDeadlineCondition(dnsRecord)
+	if exceeded() || gauge() != 0 {
+		t.Errorf("setPublishDeadlineCondition() exceeded = %t, metric = %v after validation", exceeded(), gauge())
+	}
+
+	// publish deadline removed
+	dnsRecord.Spec.PublishDeadline = nil
+	setPublishDeadlineCondition(dnsRecord)
+	if metrics.PublishDeadlineExceeded.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace) {
+		t.Errorf("setPublishDeadlineCondition() kept the metric after the publish deadline was removed")
+	}
+}
+
+func TestReconcileDeleteRemovesDurationMetrics(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	now := metav1.Now()
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "durations",
+			Namespace:         "test",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{RootHost: "foo.example.com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dnsRecord).WithStatusSubresource(dnsRecord).Build()
+	r := &DNSRecordReconciler{Client: c, ShadowMode: true}
+	metrics.PublishDuration.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Observe(1)
+	metrics.PropagationDuration.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Observe(2)
+	metrics.NameServerPropagationDuration.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Observe(3)
+	metrics.DriftCorrections.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Inc()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if metrics.PublishDuration.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace) {
+		t.Errorf("Reconcile() kept the publish duration of the deleted record")
+	}
+	if metrics.PropagationDuration.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace) {
+		t.Errorf("Reconcile() kept the propagation duration of the deleted record")
+	}
+	if metrics.NameServerPropagationDuration.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace) {
+		t.Errorf("Reconcile() kept the name server propagation duration of the deleted record")
+	}
+	if metrics.DriftCorrections.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace) {
+		t.Errorf("Reconcile() kept the drift corrections of the deleted record")
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/common"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+	"github.com/kuadrant/dns-operator/internal/provider"
+	"github.com/kuadrant/dns-operator/pkg/diff"
+)
+
+// reconcileShadow compares the endpoints the given DNSRecord would publish with the records currently in its provider
+// zone, without writing to the provider, and reports any divergence with the ShadowDiverged condition.
+func (r *DNSRecordReconciler) reconcileShadow(ctx context.Context, previous, dnsRecord *v1alpha1.DNSRecord, dnsProvider provider.Provider) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	divergence, err := shadowDiff(ctx, dnsRecord, dnsProvider)
+	if err != nil {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+			"ProviderError", fmt.Sprintf("The DNS provider failed to list the zone records: %v", provider.SanitizeError(err)))
+		return r.updateStatus(ctx, previous, dnsRecord, false, err)
+	}
+
+	metrics.ShadowDivergence.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Set(float64(len(divergence)))
+	if len(divergence) > 0 {
+		logger.Info("Record diverges from the provider zone", "divergence", divergence)
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeShadowDiverged), metav1.ConditionTrue,
+			"Diverged", strings.Join(divergence, "; "))
+	} else {
+		setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeShadowDiverged), metav1.ConditionFalse,
+			"InParity", "The provider zone holds the records of all endpoints")
+	}
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeReady), metav1.ConditionFalse,
+		"ShadowMode", "Not publishing, the operator is running in shadow mode")
+	// nothing is written in shadow mode, so the generation is not observed and publishing it not yet timed
+	dnsRecord.Status.SpecChangedAt = previous.Status.SpecChangedAt
+	dnsRecord.Status.QueuedAt = reconcileStart
+
+	if !equality.Semantic.DeepEqual(previous.Status, dnsRecord.Status) {
+		if err = r.Status().Update(ctx, dnsRecord); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+}
+
+// shadowDiff returns a description of each endpoint of the given DNSRecord that is missing from, or differs to, the
+// records in its provider zone. Zone records are compared regardless of the owner that maintains them.
+func shadowDiff(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, dnsProvider provider.Provider) ([]string, error) {
+	zoneEndpoints, err := dnsProvider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	specEndpoints, err := dnsProvider.AdjustEndpoints(common.NormalizeEndpoints(dnsRecord.Spec.Endpoints))
+	if err != nil {
+		return nil, fmt.Errorf("adjusting specEndpoints: %w", err)
+	}
+
+	// Zone records not in the spec belong to other hosts, so deletions are not divergence
+	changes := diff.Endpoints(zoneEndpoints, specEndpoints)
+	var divergence []string
+	for _, ep := range changes.Create {
+		divergence = append(divergence, endpointName(ep)+" is missing")
+	}
+	for _, u := range changes.Update {
+		switch {
+		case u.TargetsChanged:
+			divergence = append(divergence, fmt.Sprintf("%s targets %v differ from %v", endpointName(u.Desired), u.Desired.Targets, u.Current.Targets))
+		case u.TTLChanged && u.Desired.RecordTTL.IsConfigured():
+			divergence = append(divergence, fmt.Sprintf("%s TTL %d differs from %d", endpointName(u.Desired), u.Desired.RecordTTL, u.Current.RecordTTL))
+		}
+	}
+	slices.Sort(divergence)
+	return divergence, nil
+}
@@ -0,0 +1,102 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/external-dns/provider/inmemory"
+	providerinmemory "github.com/kuadrant/dns-operator/internal/provider/inmemory"
+)
+
+func TestShadowDiff(t *testing.T) {
+	ctx := context.Background()
+	p := &providerinmemory.InMemoryDNSProvider{
+		InMemoryProvider: inmemory.NewInMemoryProvider(ctx, inmemory.InMemoryInitZones([]string{"example.com"})),
+	}
+	err := p.ApplyChanges(ctx, &plan.Changes{Create: []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("bar.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("baz.example.com", externaldnsendpoint.RecordTypeA, 300, "127.0.0.1"),
+	}})
+	if err != nil {
+		t.Fatalf("ApplyChanges() error = %v", err)
+	}
+
+	dnsRecord := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{
+		RootHost: "foo.example.com",
+		Endpoints: []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpointWithTTL("foo.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+			externaldnsendpoint.NewEndpointWithTTL("bar.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.2"),
+			externaldnsendpoint.NewEndpointWithTTL("baz.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+			externaldnsendpoint.NewEndpointWithTTL("qux.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		},
+	}}
+
+	got, err := shadowDiff(ctx, dnsRecord, p)
+	if err != nil {
+		t.Fatalf("shadowDiff() error = %v", err)
+	}
+	want := []string{
+		"A bar.example.com targets 127.0.0.2 differ from 127.0.0.1",
+		"A baz.example.com TTL 60 differs from 300",
+		"A qux.example.com is missing",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shadowDiff() got = %v, want %v", got, want)
+	}
+}
+
+func TestReconcileShadowDoesNotObserveGeneration(t *testing.T) {
+	ctx := context.Background()
+	p := &providerinmemory.InMemoryDNSProvider{
+		InMemoryProvider: inmemory.NewInMemoryProvider(ctx, inmemory.InMemoryInitZones([]string{"example.com"})),
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	previous := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Generation: 1},
+		Spec: v1alpha1.DNSRecordSpec{
+			RootHost: "foo.example.com",
+			Endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+		},
+		Status: v1alpha1.DNSRecordStatus{OwnerID: "owner", ZoneID: "example.com", ZoneDomainName: "example.com"},
+	}
+	r := &DNSRecordReconciler{
+		Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(previous).WithStatusSubresource(previous).Build(),
+		ShadowMode: true,
+	}
+	dnsRecord := previous.DeepCopy()
+	// as set by Reconcile on observing the new generation
+	specChangedAt := metav1.Now()
+	dnsRecord.Status.SpecChangedAt = &specChangedAt
+
+	if _, err := r.reconcileShadow(ctx, previous, dnsRecord, p); err != nil {
+		t.Fatalf("reconcileShadow() error = %v", err)
+	}
+	condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeShadowDiverged))
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("reconcileShadow() ShadowDiverged condition = %v", condition)
+	}
+	if dnsRecord.Status.ObservedGeneration != 0 || dnsRecord.Status.SpecChangedAt != nil {
+		t.Errorf("reconcileShadow() observed generation %d, spec changed at %v, want neither set",
+			dnsRecord.Status.ObservedGeneration, dnsRecord.Status.SpecChangedAt)
+	}
+	if dnsRecord.Status.QueuedAt.IsZero() {
+		t.Errorf("reconcileShadow() did not set queued at")
+	}
+}
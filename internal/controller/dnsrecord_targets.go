@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// excludeTargets removes the A and AAAA targets within the ExcludedTargetCIDRs from the endpoints of the given
+// DNSRecord, endpoints left without targets, and CNAME endpoints left pointing at them, setting the TargetsExcluded
+// condition if any are removed. As with target overrides, the spec is only changed in memory.
+func (r *DNSRecordReconciler) excludeTargets(dnsRecord *v1alpha1.DNSRecord) {
+	var excluded []string
+	endpoints := make([]*externaldnsendpoint.Endpoint, 0, len(dnsRecord.Spec.Endpoints))
+	for _, ep := range dnsRecord.Spec.Endpoints {
+		if ep.RecordType != externaldnsendpoint.RecordTypeA && ep.RecordType != externaldnsendpoint.RecordTypeAAAA {
+			endpoints = append(endpoints, ep)
+			continue
+		}
+		targets := slices.DeleteFunc(slices.Clone(ep.Targets), func(target string) bool {
+			if r.targetExcluded(target) {
+				excluded = append(excluded, target)
+				return true
+			}
+			return false
+		})
+		if len(targets) > 0 {
+			ep.Targets = targets
+			endpoints = append(endpoints, ep)
+		}
+	}
+
+	if len(excluded) == 0 {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeTargetsExcluded))
+		return
+	}
+	endpoints, dangling := dropDanglingEndpoints(dnsRecord.Spec.Endpoints, endpoints)
+	dnsRecord.Spec.Endpoints = endpoints
+	slices.Sort(excluded)
+	message := fmt.Sprintf("Targets %s are within excluded CIDRs and not published", strings.Join(slices.Compact(excluded), ", "))
+	if len(dangling) > 0 {
+		slices.Sort(dangling)
+		message += fmt.Sprintf(", CNAME endpoints %s are left without a target and not published either", strings.Join(slices.Compact(dangling), ", "))
+	}
+	setDNSRecordCondition(dnsRecord, string(v1alpha1.ConditionTypeTargetsExcluded), metav1.ConditionTrue, "ExcludedCIDR", message)
+}
+
+// dropDanglingEndpoints removes the CNAME endpoints whose targets are all hostnames defined by the original endpoints
+// but no longer by the given endpoints, repeating until none are left, so chains of CNAMEs leading to removed
+// endpoints are removed too. It returns the remaining endpoints and the hostnames of the removed ones.
+func dropDanglingEndpoints(original, endpoints []*externaldnsendpoint.Endpoint) ([]*externaldnsendpoint.Endpoint, []string) {
+	hostnames := func(endpoints []*externaldnsendpoint.Endpoint) map[string]bool {
+		names := make(map[string]bool, len(endpoints))
+		for _, ep := range endpoints {
+			names[strings.TrimSuffix(ep.DNSName, ".")] = true
+		}
+		return names
+	}
+	defined := hostnames(original)
+	var dangling []string
+	for {
+		remaining := hostnames(endpoints)
+		count := len(endpoints)
+		endpoints = slices.DeleteFunc(endpoints, func(ep *externaldnsendpoint.Endpoint) bool {
+			if ep.RecordType != externaldnsendpoint.RecordTypeCNAME || len(ep.Targets) == 0 {
+				return false
+			}
+			for _, target := range ep.Targets {
+				if target = strings.TrimSuffix(target, "."); !defined[target] || remaining[target] {
+					return false
+				}
+			}
+			dangling = append(dangling, ep.DNSName)
+			return true
+		})
+		if len(endpoints) == count {
+			return endpoints, dangling
+		}
+	}
+}
+
+// targetExcluded returns true if the given target is an address within the ExcludedTargetCIDRs
+func (r *DNSRecordReconciler) targetExcluded(target string) bool {
+	addr, err := netip.ParseAddr(target)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range r.ExcludedTargetCIDRs {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
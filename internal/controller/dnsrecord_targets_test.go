@@ -0,0 +1,51 @@
+//go:build unit
+
+package controller
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestExcludeTargets(t *testing.T) {
+	r := &DNSRecordReconciler{
+		ExcludedTargetCIDRs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{Endpoints: []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "10.1.2.3", "172.32.200.1"),
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeAAAA, "fd00::1"),
+		externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeCNAME, "10.internal.example.com"),
+		externaldnsendpoint.NewEndpoint("internal.example.com", externaldnsendpoint.RecordTypeA, "10.0.0.1"),
+		externaldnsendpoint.NewEndpoint("app.example.com", externaldnsendpoint.RecordTypeCNAME, "internal.example.com"),
+		externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "app.example.com."),
+		externaldnsendpoint.NewEndpoint("api.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"),
+	}}}
+
+	r.excludeTargets(dnsRecord)
+
+	want := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "172.32.200.1"),
+		externaldnsendpoint.NewEndpoint("bar.example.com", externaldnsendpoint.RecordTypeCNAME, "10.internal.example.com"),
+		externaldnsendpoint.NewEndpoint("api.example.com", externaldnsendpoint.RecordTypeCNAME, "foo.example.com"),
+	}
+	if !reflect.DeepEqual(dnsRecord.Spec.Endpoints, want) {
+		t.Errorf("excludeTargets() endpoints = %v, want %v", dnsRecord.Spec.Endpoints, want)
+	}
+	condition := meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeTargetsExcluded))
+	if condition == nil || condition.Message != "Targets 10.0.0.1, 10.1.2.3, fd00::1 are within excluded CIDRs and not published, "+
+		"CNAME endpoints app.example.com, www.example.com are left without a target and not published either" {
+		t.Errorf("excludeTargets() condition = %v", condition)
+	}
+
+	r.ExcludedTargetCIDRs = []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")}
+	r.excludeTargets(dnsRecord)
+	if meta.FindStatusCondition(dnsRecord.Status.Conditions, string(v1alpha1.ConditionTypeTargetsExcluded)) != nil {
+		t.Errorf("excludeTargets() kept the TargetsExcluded condition with no targets excluded")
+	}
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// rejectWildcards returns an error if the given DNSRecord has a wildcard root host or endpoint, for zones whose limits
+// reject wildcards
+func rejectWildcards(dnsRecord *v1alpha1.DNSRecord) error {
+	var wildcards []string
+	names := []string{dnsRecord.Spec.RootHost}
+	for _, ep := range dnsRecord.Spec.Endpoints {
+		names = append(names, ep.DNSName)
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, v1alpha1.WildcardPrefix) && !slices.Contains(wildcards, name) {
+			wildcards = append(wildcards, name)
+		}
+	}
+	if len(wildcards) > 0 {
+		return fmt.Errorf("wildcards %s are not allowed in zone %s", strings.Join(wildcards, ", "), dnsRecord.Status.ZoneDomainName)
+	}
+	return nil
+}
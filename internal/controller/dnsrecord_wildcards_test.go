@@ -0,0 +1,104 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestRejectWildcards(t *testing.T) {
+	tests := []struct {
+		name      string
+		rootHost  string
+		endpoints []*externaldnsendpoint.Endpoint
+		wantErr   string
+	}{
+		{
+			name:     "no wildcards",
+			rootHost: "foo.example.com",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+		},
+		{
+			name:     "wildcard root host",
+			rootHost: "*.foo.example.com",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("*.foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+				externaldnsendpoint.NewEndpoint("*.foo.example.com", externaldnsendpoint.RecordTypeAAAA, "::1"),
+			},
+			wantErr: "wildcards *.foo.example.com are not allowed in zone example.com",
+		},
+		{
+			name:     "wildcard endpoint",
+			rootHost: "foo.example.com",
+			endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+				externaldnsendpoint.NewEndpoint("*.bar.foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+			wantErr: "wildcards *.bar.foo.example.com are not allowed in zone example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dnsRecord := &v1alpha1.DNSRecord{
+				Spec:   v1alpha1.DNSRecordSpec{RootHost: tt.rootHost, Endpoints: tt.endpoints},
+				Status: v1alpha1.DNSRecordStatus{ZoneDomainName: "example.com"},
+			}
+			err := rejectWildcards(dnsRecord)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("rejectWildcards() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcileRejectWildcards(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "wildcard",
+			Namespace:  "default",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			RootHost:    "*.foo.example.com",
+			ProviderRef: v1alpha1.ProviderRef{Name: "dns-provider-creds"},
+			Endpoints: []*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("*.foo.example.com", externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+			},
+		},
+		Status: v1alpha1.DNSRecordStatus{OwnerID: "owner", ZoneID: "example.com", ZoneDomainName: "example.com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dnsRecord).WithStatusSubresource(dnsRecord).Build()
+	r := &DNSRecordReconciler{Client: c, ZoneLimits: map[string]ZoneLimits{"example.com": {RejectWildcards: true}}}
+
+	key := client.ObjectKeyFromObject(dnsRecord)
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	current := &v1alpha1.DNSRecord{}
+	if err := c.Get(ctx, key, current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	condition := meta.FindStatusCondition(current.Status.Conditions, string(v1alpha1.ConditionTypeReady))
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "WildcardRejected" {
+		t.Errorf("Reconcile() Ready condition = %v, want the wildcard rejected", condition)
+	}
+}
@@ -62,6 +62,11 @@ type Plan struct {
 	ExcludeRecords []string
 	// OwnerID of records to manage
 	OwnerID string
+	// AdoptUnowned allows a plan with an owner to take ownership of existing unowned records it desires,
+	// instead of reporting an owner conflict.
+	AdoptUnowned bool
+	// AdoptMatchingOnly restricts AdoptUnowned to unowned records whose targets already match the desired targets.
+	AdoptMatchingOnly bool
 	// Errors list of errors describing issues that can't be resolved by the plan.
 	// Populated after calling Calculate()
 	Errors []error
@@ -315,7 +320,9 @@ func (p *Plan) Calculate() *Plan {
 				if records.current != nil && len(records.candidates) > 0 {
 					candidate := t.resolver.ResolveUpdate(records.current, records.candidates)
 					current := records.current.DeepCopy()
+					previous := records.previous
 					owners := []string{}
+					adopted := false
 					if endpointOwner, hasOwner := current.Labels[endpoint.OwnerLabelKey]; hasOwner && endpointOwner != "" {
 						if p.OwnerID == "" {
 							// Only allow owned records to be updated by other owned records
@@ -328,15 +335,27 @@ func (p *Plan) Calculate() *Plan {
 						slices.Sort(owners)
 						owners = slices.Compact[[]string, string](owners)
 						current.Labels[endpoint.OwnerLabelKey] = strings.Join(owners, OwnerLabelDeliminator)
-					} else {
-						if p.OwnerID != "" {
+					} else if p.OwnerID != "" {
+						if !p.AdoptUnowned {
 							// Only allow unowned records to be updated by other unowned records
 							errs = append(errs, fmt.Errorf("%w, cannot update endpoint '%s' with owner when existing endpoint is not owned", ErrOwnerConflict, candidate.DNSName))
 							continue
 						}
+						if p.AdoptMatchingOnly && targetChanged(candidate, records.current) {
+							errs = append(errs, fmt.Errorf("%w, cannot adopt endpoint '%s' when the targets of the existing endpoint differ", ErrOwnerConflict, candidate.DNSName))
+							continue
+						}
+						// Take ownership of the unowned record, replacing its targets as if they were previously ours
+						owners = []string{p.OwnerID}
+						previous = records.current
+						adopted = true
+						if current.Labels == nil {
+							current.Labels = endpoint.Labels{}
+						}
+						current.Labels[endpoint.OwnerLabelKey] = p.OwnerID
 					}
 					inheritOwner(current, candidate)
-					managedChanges.updates = append(managedChanges.updates, &endpointUpdate{desired: candidate, current: records.current, previous: records.previous, isDelete: false})
+					managedChanges.updates = append(managedChanges.updates, &endpointUpdate{desired: candidate, current: records.current, previous: previous, isDelete: false, isAdopt: adopted})
 					managedChanges.dnsNameOwners[key.dnsName] = append(managedChanges.dnsNameOwners[key.dnsName], owners...)
 				}
 			}
@@ -420,13 +439,15 @@ func inheritOwner(from, to *endpoint.Endpoint) {
 
 type endpointUpdate struct {
 	isDelete bool
+	// isAdopt is set when the current record is unowned and being adopted, its ownership must be written
+	isAdopt  bool
 	current  *endpoint.Endpoint
 	previous *endpoint.Endpoint
 	desired  *endpoint.Endpoint
 }
 
 func (e *endpointUpdate) ShouldUpdate() bool {
-	return shouldUpdateOwner(e.desired, e.current) || shouldUpdateTTL(e.desired, e.current) || targetChanged(e.desired, e.current) || shouldUpdateProviderSpecific(e.desired, e.current)
+	return e.isAdopt || shouldUpdateOwner(e.desired, e.current) || shouldUpdateTTL(e.desired, e.current) || targetChanged(e.desired, e.current) || shouldUpdateProviderSpecific(e.desired, e.current)
 }
 
 func (e *endpointUpdate) IsDeleting() bool {
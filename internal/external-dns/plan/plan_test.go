@@ -1630,6 +1630,56 @@ func (suite *PlanTestSuite) TestPlanOwnerARecordUpdateNoOwner() {
 	assert.EqualError(suite.T(), cp.Error(), "owner conflict, cannot update endpoint 'foo' with owner when existing endpoint is not owned")
 }
 
+// Should allow unowned records to be adopted by a plan with an owner
+func (suite *PlanTestSuite) TestPlanOwnerARecordAdoptNoOwner() {
+	current := []*endpoint.Endpoint{suite.fooA1OwnerNone}
+	desired := []*endpoint.Endpoint{suite.fooA2OwnerNone}
+	expectedChanges := &plan.Changes{
+		Create:    []*endpoint.Endpoint{},
+		UpdateOld: []*endpoint.Endpoint{suite.fooA1OwnerNone.DeepCopy()},
+		UpdateNew: []*endpoint.Endpoint{suite.fooA2Owner2.DeepCopy()},
+		Delete:    []*endpoint.Endpoint{},
+	}
+
+	p := &Plan{
+		OwnerID:        "owner2",
+		AdoptUnowned:   true,
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+	}
+
+	cp := p.Calculate()
+	validateChanges(suite.T(), cp.Changes, expectedChanges)
+	assert.NoError(suite.T(), cp.Error())
+	assert.Equal(suite.T(), "owner2", cp.Changes.UpdateNew[0].Labels[endpoint.OwnerLabelKey])
+}
+
+// Should not adopt unowned records whose targets differ when only matching records are adopted
+func (suite *PlanTestSuite) TestPlanOwnerARecordAdoptMatchingOnly() {
+	current := []*endpoint.Endpoint{suite.fooA1OwnerNone}
+	desired := []*endpoint.Endpoint{suite.fooA2OwnerNone}
+
+	p := &Plan{
+		OwnerID:           "owner2",
+		AdoptUnowned:      true,
+		AdoptMatchingOnly: true,
+		Policies:          []Policy{&SyncPolicy{}},
+		Current:           current,
+		Desired:           desired,
+		ManagedRecords:    []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+	}
+
+	cp := p.Calculate()
+	assert.EqualError(suite.T(), cp.Error(), "owner conflict, cannot adopt endpoint 'foo' when the targets of the existing endpoint differ")
+
+	p.Desired = []*endpoint.Endpoint{suite.fooA1OwnerNone.DeepCopy()}
+	cp = p.Calculate()
+	assert.NoError(suite.T(), cp.Error())
+	assert.Equal(suite.T(), "owner2", cp.Changes.UpdateNew[0].Labels[endpoint.OwnerLabelKey])
+}
+
 // Should not merge targets of unowned records with a shared dnsName and type
 func (suite *PlanTestSuite) TestNoOwnerARecordUpdate() {
 	current := []*endpoint.Endpoint{suite.fooA1OwnerNone}
@@ -204,6 +204,8 @@ type Route53API interface {
 type Route53Change struct {
 	route53.Change
 	OwnedRecord string
+	sizeBytes   int
+	sizeValues  int
 }
 
 type Route53Changes []*Route53Change
@@ -216,6 +218,24 @@ func (cs Route53Changes) Route53Changes() []*route53.Change {
 	return ret
 }
 
+// SizeInBytes returns the total number of characters in the values of all changes, as counted against the route53 change batch limit
+func (cs Route53Changes) SizeInBytes() int {
+	size := 0
+	for _, c := range cs {
+		size += c.sizeBytes
+	}
+	return size
+}
+
+// SizeInValues returns the total number of resource record values of all changes, as counted against the route53 change batch limit
+func (cs Route53Changes) SizeInValues() int {
+	size := 0
+	for _, c := range cs {
+		size += c.sizeValues
+	}
+	return size
+}
+
 type zonesListCache struct {
 	age      time.Time
 	duration time.Duration
@@ -225,12 +245,14 @@ type zonesListCache struct {
 // AWSProvider is an implementation of Provider for AWS Route53.
 type AWSProvider struct {
 	provider.BaseProvider
-	client               Route53API
-	logger               logr.Logger
-	dryRun               bool
-	batchChangeSize      int
-	batchChangeInterval  time.Duration
-	evaluateTargetHealth bool
+	client                Route53API
+	logger                logr.Logger
+	dryRun                bool
+	batchChangeSize       int
+	batchChangeSizeBytes  int
+	batchChangeSizeValues int
+	batchChangeInterval   time.Duration
+	evaluateTargetHealth  bool
 	// only consider hosted zones managing domains ending in this suffix
 	domainFilter endpoint.DomainFilter
 	// filter hosted zones by id
@@ -247,35 +269,39 @@ type AWSProvider struct {
 
 // AWSConfig contains configuration to create a new AWS provider.
 type AWSConfig struct {
-	DomainFilter         endpoint.DomainFilter
-	ZoneIDFilter         provider.ZoneIDFilter
-	ZoneTypeFilter       provider.ZoneTypeFilter
-	ZoneTagFilter        provider.ZoneTagFilter
-	BatchChangeSize      int
-	BatchChangeInterval  time.Duration
-	EvaluateTargetHealth bool
-	PreferCNAME          bool
-	DryRun               bool
-	ZoneCacheDuration    time.Duration
+	DomainFilter          endpoint.DomainFilter
+	ZoneIDFilter          provider.ZoneIDFilter
+	ZoneTypeFilter        provider.ZoneTypeFilter
+	ZoneTagFilter         provider.ZoneTagFilter
+	BatchChangeSize       int
+	BatchChangeSizeBytes  int
+	BatchChangeSizeValues int
+	BatchChangeInterval   time.Duration
+	EvaluateTargetHealth  bool
+	PreferCNAME           bool
+	DryRun                bool
+	ZoneCacheDuration     time.Duration
 }
 
 // NewAWSProvider initializes a new AWS Route53 based Provider.
 func NewAWSProvider(ctx context.Context, awsConfig AWSConfig, client Route53API) (*AWSProvider, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 	provider := &AWSProvider{
-		client:               client,
-		logger:               logger,
-		domainFilter:         awsConfig.DomainFilter,
-		zoneIDFilter:         awsConfig.ZoneIDFilter,
-		zoneTypeFilter:       awsConfig.ZoneTypeFilter,
-		zoneTagFilter:        awsConfig.ZoneTagFilter,
-		batchChangeSize:      awsConfig.BatchChangeSize,
-		batchChangeInterval:  awsConfig.BatchChangeInterval,
-		evaluateTargetHealth: awsConfig.EvaluateTargetHealth,
-		preferCNAME:          awsConfig.PreferCNAME,
-		dryRun:               awsConfig.DryRun,
-		zonesCache:           &zonesListCache{duration: awsConfig.ZoneCacheDuration},
-		failedChangesQueue:   make(map[string]Route53Changes),
+		client:                client,
+		logger:                logger,
+		domainFilter:          awsConfig.DomainFilter,
+		zoneIDFilter:          awsConfig.ZoneIDFilter,
+		zoneTypeFilter:        awsConfig.ZoneTypeFilter,
+		zoneTagFilter:         awsConfig.ZoneTagFilter,
+		batchChangeSize:       awsConfig.BatchChangeSize,
+		batchChangeSizeBytes:  awsConfig.BatchChangeSizeBytes,
+		batchChangeSizeValues: awsConfig.BatchChangeSizeValues,
+		batchChangeInterval:   awsConfig.BatchChangeInterval,
+		evaluateTargetHealth:  awsConfig.EvaluateTargetHealth,
+		preferCNAME:           awsConfig.PreferCNAME,
+		dryRun:                awsConfig.DryRun,
+		zonesCache:            &zonesListCache{duration: awsConfig.ZoneCacheDuration},
+		failedChangesQueue:    make(map[string]Route53Changes),
 	}
 
 	return provider, nil
@@ -565,7 +591,15 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes Route53Changes,
 		retriedChanges, newChanges := findChangesInQueue(cs, p.failedChangesQueue[z])
 		p.failedChangesQueue[z] = nil
 
-		batchCs := append(p.batchChangeSet(newChanges, p.batchChangeSize), p.batchChangeSet(retriedChanges, p.batchChangeSize)...)
+		newBatchCs, err := p.batchChangeSet(newChanges, p.batchChangeSize, p.batchChangeSizeBytes, p.batchChangeSizeValues)
+		if err != nil {
+			zoneErrors = append(zoneErrors, fmt.Errorf("Failure in zone %s [Id: %s] when batching changes: %w", aws.StringValue(zones[z].Name), z, err))
+		}
+		retriedBatchCs, err := p.batchChangeSet(retriedChanges, p.batchChangeSize, p.batchChangeSizeBytes, p.batchChangeSizeValues)
+		if err != nil {
+			zoneErrors = append(zoneErrors, fmt.Errorf("Failure in zone %s [Id: %s] when batching changes: %w", aws.StringValue(zones[z].Name), z, err))
+		}
+		batchCs := append(newBatchCs, retriedBatchCs...)
 		for i, b := range batchCs {
 			if len(b) == 0 {
 				continue
@@ -647,7 +681,7 @@ func (p *AWSProvider) newChanges(action string, endpoints []*endpoint.Endpoint)
 		if dualstack {
 			// make a copy of change, modify RRS type to AAAA, then add new change
 			rrs := *change.ResourceRecordSet
-			change2 := &Route53Change{Change: route53.Change{Action: change.Action, ResourceRecordSet: &rrs}}
+			change2 := &Route53Change{Change: route53.Change{Action: change.Action, ResourceRecordSet: &rrs}, sizeBytes: change.sizeBytes, sizeValues: change.sizeValues}
 			change2.ResourceRecordSet.Type = aws.String(route53.RRTypeAaaa)
 			changes = append(changes, change2)
 		}
@@ -735,6 +769,8 @@ func (p *AWSProvider) newChange(action string, ep *endpoint.Endpoint) (*Route53C
 			HostedZoneId:         aws.String(cleanZoneID(targetHostedZone)),
 			EvaluateTargetHealth: aws.Bool(evalTargetHealth),
 		}
+		change.sizeBytes += len(ep.Targets[0])
+		change.sizeValues++
 	} else {
 		change.ResourceRecordSet.Type = aws.String(ep.RecordType)
 		if !ep.RecordTTL.IsConfigured() {
@@ -747,9 +783,17 @@ func (p *AWSProvider) newChange(action string, ep *endpoint.Endpoint) (*Route53C
 			change.ResourceRecordSet.ResourceRecords[idx] = &route53.ResourceRecord{
 				Value: aws.String(val),
 			}
+			change.sizeBytes += len(val)
+			change.sizeValues++
 		}
 	}
 
+	// route53 counts the values of UPSERT changes twice against the change batch limits
+	if action == route53.ChangeActionUpsert {
+		change.sizeBytes *= 2
+		change.sizeValues *= 2
+	}
+
 	setIdentifier := ep.SetIdentifier
 	if setIdentifier != "" {
 		change.ResourceRecordSet.SetIdentifier = aws.String(setIdentifier)
@@ -855,10 +899,13 @@ func (p *AWSProvider) tagsForZone(ctx context.Context, zoneID string) (map[strin
 	return tagMap, nil
 }
 
-func (p *AWSProvider) batchChangeSet(cs Route53Changes, batchSize int) []Route53Changes {
-	if len(cs) <= batchSize {
+// batchChangeSet splits the given changes into batches within the given limits of changes, value characters and values per batch.
+// Changes to the same record set, and its ownership records, are always kept in the same batch. An error is returned for
+// every record set whose changes exceed the limits of a single batch, these changes are not included in any batch.
+func (p *AWSProvider) batchChangeSet(cs Route53Changes, batchSize, batchSizeBytes, batchSizeValues int) ([]Route53Changes, error) {
+	if len(cs) <= batchSize && cs.SizeInBytes() <= batchSizeBytes && cs.SizeInValues() <= batchSizeValues {
 		res := sortChangesByActionNameType(cs)
-		return []Route53Changes{res}
+		return []Route53Changes{res}, nil
 	}
 
 	batchChanges := make([]Route53Changes, 0)
@@ -871,18 +918,31 @@ func (p *AWSProvider) batchChangeSet(cs Route53Changes, batchSize int) []Route53
 	}
 	sort.Strings(names)
 
+	var errs []error
 	currentBatch := Route53Changes{}
-	for k, name := range names {
+	for _, name := range names {
 		v := changesByOwnership[name]
 		if len(v) > batchSize {
-			p.logger.Info(fmt.Sprintf("Total changes for %v exceeds max batch size of %d, total changes: %d; changes will not be performed", k, batchSize, len(v)))
+			errs = append(errs, fmt.Errorf("total changes for %s exceeds max batch size of %d, total changes: %d; changes will not be performed", name, batchSize, len(v)))
+			continue
+		}
+		if v.SizeInBytes() > batchSizeBytes {
+			errs = append(errs, fmt.Errorf("total size of values for %s exceeds max batch size of %d characters, total characters: %d; changes will not be performed", name, batchSizeBytes, v.SizeInBytes()))
+			continue
+		}
+		if v.SizeInValues() > batchSizeValues {
+			errs = append(errs, fmt.Errorf("total values for %s exceeds max batch size of %d values, total values: %d; changes will not be performed", name, batchSizeValues, v.SizeInValues()))
 			continue
 		}
 
-		if len(currentBatch)+len(v) > batchSize {
+		if len(currentBatch)+len(v) > batchSize ||
+			currentBatch.SizeInBytes()+v.SizeInBytes() > batchSizeBytes ||
+			currentBatch.SizeInValues()+v.SizeInValues() > batchSizeValues {
 			// currentBatch would be too large if we add this changeset;
 			// add currentBatch to batchChanges and start a new currentBatch
-			batchChanges = append(batchChanges, sortChangesByActionNameType(currentBatch))
+			if len(currentBatch) > 0 {
+				batchChanges = append(batchChanges, sortChangesByActionNameType(currentBatch))
+			}
 			currentBatch = append(Route53Changes{}, v...)
 		} else {
 			currentBatch = append(currentBatch, v...)
@@ -893,7 +953,10 @@ func (p *AWSProvider) batchChangeSet(cs Route53Changes, batchSize int) []Route53
 		batchChanges = append(batchChanges, sortChangesByActionNameType(currentBatch))
 	}
 
-	return batchChanges
+	for _, err := range errs {
+		p.logger.Error(err, "unable to batch changes")
+	}
+	return batchChanges, errors.Join(errs...)
 }
 
 func sortChangesByActionNameType(cs Route53Changes) Route53Changes {
@@ -1028,10 +1091,8 @@ func (p *AWSProvider) isAWSAlias(ep *endpoint.Endpoint) string {
 
 // canonicalHostedZone returns the matching canonical zone for a given hostname.
 func (p *AWSProvider) canonicalHostedZone(hostname string) string {
-	for suffix, zone := range canonicalHostedZones {
-		if strings.HasSuffix(hostname, suffix) {
-			return zone
-		}
+	if zone := CanonicalHostedZone(hostname); zone != "" {
+		return zone
 	}
 
 	if strings.HasSuffix(hostname, ".amazonaws.com") {
@@ -1043,6 +1104,17 @@ func (p *AWSProvider) canonicalHostedZone(hostname string) string {
 	return ""
 }
 
+// CanonicalHostedZone returns the canonical hosted zone of the AWS resource, e.g. an ELB or a CloudFront distribution,
+// with the given hostname, or an empty string if the hostname is not of a known AWS resource.
+func CanonicalHostedZone(hostname string) string {
+	for suffix, zone := range canonicalHostedZones {
+		if strings.HasSuffix(hostname, suffix) {
+			return zone
+		}
+	}
+	return ""
+}
+
 // cleanZoneID removes the "/hostedzone/" prefix
 func cleanZoneID(id string) string {
 	return strings.TrimPrefix(id, "/hostedzone/")
@@ -40,9 +40,11 @@ import (
 )
 
 const (
-	defaultBatchChangeSize      = 4000
-	defaultBatchChangeInterval  = time.Second
-	defaultEvaluateTargetHealth = true
+	defaultBatchChangeSize       = 4000
+	defaultBatchChangeSizeBytes  = 32000
+	defaultBatchChangeSizeValues = 1000
+	defaultBatchChangeInterval   = time.Second
+	defaultEvaluateTargetHealth  = true
 )
 
 // Compile time check for interface conformance
@@ -1531,8 +1533,9 @@ func TestAWSBatchChangeSet(t *testing.T) {
 	}
 
 	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
-	batchCs := p.batchChangeSet(cs, defaultBatchChangeSize)
+	batchCs, err := p.batchChangeSet(cs, defaultBatchChangeSize, defaultBatchChangeSizeBytes, defaultBatchChangeSizeValues)
 
+	require.NoError(t, err)
 	require.Equal(t, 1, len(batchCs))
 
 	// sorting cs not needed as it should be returned as is
@@ -1571,8 +1574,9 @@ func TestAWSBatchChangeSetExceeding(t *testing.T) {
 
 	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
 
-	batchCs := p.batchChangeSet(cs, testLimit)
+	batchCs, err := p.batchChangeSet(cs, testLimit, defaultBatchChangeSizeBytes, defaultBatchChangeSizeValues)
 
+	require.NoError(t, err)
 	require.Equal(t, expectedBatchCount, len(batchCs))
 
 	// sorting cs needed to match batchCs
@@ -1611,11 +1615,68 @@ func TestAWSBatchChangeSetExceedingNameChange(t *testing.T) {
 
 	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
 
-	batchCs := p.batchChangeSet(cs, testLimit)
+	batchCs, err := p.batchChangeSet(cs, testLimit, defaultBatchChangeSizeBytes, defaultBatchChangeSizeValues)
 
+	require.Error(t, err)
 	require.Equal(t, 0, len(batchCs))
 }
 
+func TestAWSBatchChangeSetExceedingBytesAndValues(t *testing.T) {
+	var cs Route53Changes
+	const testCount = 10
+	const testBytesLimit = 30
+	const testValuesLimit = 5
+
+	for i := 1; i <= testCount; i++ {
+		cs = append(cs, &Route53Change{
+			Change: route53.Change{
+				Action: aws.String(route53.ChangeActionCreate),
+				ResourceRecordSet: &route53.ResourceRecordSet{
+					Name: aws.String(fmt.Sprintf("host-%d", i)),
+					Type: aws.String("A"),
+				},
+			},
+			sizeBytes:  10,
+			sizeValues: 1,
+		})
+	}
+
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+
+	batchCs, err := p.batchChangeSet(cs, defaultBatchChangeSize, testBytesLimit, defaultBatchChangeSizeValues)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(batchCs))
+	for _, batch := range batchCs {
+		require.LessOrEqual(t, batch.SizeInBytes(), testBytesLimit)
+	}
+
+	batchCs, err = p.batchChangeSet(cs, defaultBatchChangeSize, defaultBatchChangeSizeBytes, testValuesLimit)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(batchCs))
+	for _, batch := range batchCs {
+		require.LessOrEqual(t, batch.SizeInValues(), testValuesLimit)
+	}
+}
+
+func TestAWSBatchChangeSetRecordSetExceedingValues(t *testing.T) {
+	targets := make([]string, 0, defaultBatchChangeSizeValues+1)
+	for i := 0; i <= defaultBatchChangeSizeValues; i++ {
+		targets = append(targets, fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+	}
+
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	cs := Route53Changes{}
+	cs = append(cs, p.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("large.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, targets...),
+		endpoint.NewEndpoint("small.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "8.8.8.8"),
+	})...)
+
+	batchCs, err := p.batchChangeSet(cs, defaultBatchChangeSize, defaultBatchChangeSizeBytes, defaultBatchChangeSizeValues)
+	require.ErrorContains(t, err, "total values for large.zone-1.ext-dns-test-2.teapot.zalan.do exceeds max batch size of 1000 values")
+	require.Equal(t, 1, len(batchCs))
+	require.Equal(t, "small.zone-1.ext-dns-test-2.teapot.zalan.do", aws.StringValue(batchCs[0][0].ResourceRecordSet.Name))
+}
+
 func validateEndpoints(t *testing.T, provider *AWSProvider, endpoints []*endpoint.Endpoint, expected []*endpoint.Endpoint) {
 	assert.True(t, testutils.SameEndpoints(endpoints, expected), "actual and expected endpoints don't match. %+v:%+v", endpoints, expected)
 
@@ -1947,17 +2008,19 @@ func newAWSProviderWithTagFilter(t *testing.T, domainFilter endpoint.DomainFilte
 	client := NewRoute53APIStub(t)
 
 	provider := &AWSProvider{
-		client:               client,
-		batchChangeSize:      defaultBatchChangeSize,
-		batchChangeInterval:  defaultBatchChangeInterval,
-		evaluateTargetHealth: evaluateTargetHealth,
-		domainFilter:         domainFilter,
-		zoneIDFilter:         zoneIDFilter,
-		zoneTypeFilter:       zoneTypeFilter,
-		zoneTagFilter:        zoneTagFilter,
-		dryRun:               false,
-		zonesCache:           &zonesListCache{duration: 1 * time.Minute},
-		failedChangesQueue:   make(map[string]Route53Changes),
+		client:                client,
+		batchChangeSize:       defaultBatchChangeSize,
+		batchChangeSizeBytes:  defaultBatchChangeSizeBytes,
+		batchChangeSizeValues: defaultBatchChangeSizeValues,
+		batchChangeInterval:   defaultBatchChangeInterval,
+		evaluateTargetHealth:  evaluateTargetHealth,
+		domainFilter:          domainFilter,
+		zoneIDFilter:          zoneIDFilter,
+		zoneTypeFilter:        zoneTypeFilter,
+		zoneTagFilter:         zoneTagFilter,
+		dryRun:                false,
+		zonesCache:            &zonesListCache{duration: 1 * time.Minute},
+		failedChangesQueue:    make(map[string]Route53Changes),
 	}
 
 	createAWSZone(t, provider, &route53.HostedZone{
@@ -52,6 +52,7 @@ type InMemoryProvider struct {
 	domain         endpoint.DomainFilter
 	client         *InMemoryClient
 	filter         *filter
+	dryRun         bool
 	OnApplyChanges func(ctx context.Context, changes *plan.Changes)
 	OnRecords      func()
 }
@@ -104,6 +105,13 @@ func InMemoryWithClient(memoryClient *InMemoryClient) InMemoryOption {
 	}
 }
 
+// InMemoryWithDryRun validates changes, as when they are applied, without modifying the records in memory
+func InMemoryWithDryRun(dryRun bool) InMemoryOption {
+	return func(p *InMemoryProvider) {
+		p.dryRun = dryRun
+	}
+}
+
 // NewInMemoryProvider returns InMemoryProvider DNS provider interface implementation
 func NewInMemoryProvider(ctx context.Context, opts ...InMemoryOption) *InMemoryProvider {
 	logger := logr.FromContextOrDiscard(ctx)
@@ -222,6 +230,12 @@ func (im *InMemoryProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 			UpdateOld: perZoneChanges[zoneID].UpdateOld,
 			Delete:    perZoneChanges[zoneID].Delete,
 		}
+		if im.dryRun {
+			if err := im.client.ValidateChanges(zoneID, change); err != nil {
+				return err
+			}
+			continue
+		}
 		err := im.client.ApplyChanges(ctx, zoneID, change)
 		if err != nil {
 			return err
@@ -362,6 +376,14 @@ func (c *InMemoryClient) ApplyChanges(ctx context.Context, zoneID string, change
 	return nil
 }
 
+// ValidateChanges validates the given changes to the given zone, as ApplyChanges does, without applying them
+func (c *InMemoryClient) ValidateChanges(zoneID string, changes *plan.Changes) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.validateChangeBatch(zoneID, changes)
+}
+
 func (c *InMemoryClient) updateMesh(mesh sets.Set[endpoint.EndpointKey], record *endpoint.Endpoint) error {
 	if mesh.Has(record.Key()) {
 		return ErrDuplicateRecordFound
@@ -560,6 +560,20 @@ func testInMemoryApplyChanges(t *testing.T) {
 				assert.Equal(t, ti.expectedZonesState, c.zones)
 			}
 		})
+		t.Run(ti.title+" dry run", func(t *testing.T) {
+			im := NewInMemoryProvider(context.Background(), InMemoryWithDryRun(true))
+			c := &InMemoryClient{}
+			c.zones = getInitData()
+			im.client = c
+
+			err := im.ApplyChanges(context.Background(), ti.changes)
+			if ti.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, getInitData(), c.zones)
+		})
 	}
 }
 
@@ -18,7 +18,12 @@ package registry
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +37,14 @@ import (
 const (
 	recordTemplate              = "%{record_type}"
 	providerSpecificForceUpdate = "txt/force-update"
+
+	// SignatureLabelKey is the label holding the signature of registry TXT records when signing is enabled
+	SignatureLabelKey = "signature"
+	// RetainedLabelKey is the label marking records retained in the zone by the deletion policy of their owner, they are
+	// never deleted as orphans
+	RetainedLabelKey = "retained"
+	// txtEncryptionNonceLabelKey is the label external-dns stores the encryption nonce of encrypted TXT records in
+	txtEncryptionNonceLabelKey = "txt-encryption-nonce"
 )
 
 // TXTRegistry implements registry interface with ownership implemented via associated TXT records
@@ -57,6 +70,15 @@ type TXTRegistry struct {
 	txtEncryptEnabled bool
 	txtEncryptAESKey  []byte
 
+	// sign text records
+	txtSigningKey []byte
+	// accept unsigned TXT records of this owner, re-signing them on the next update
+	txtSigningMigration bool
+	// unsigned TXT records accepted for migration, keyed by the endpoint they hold ownership of
+	unsignedTXTRecords map[endpoint.EndpointKey]*endpoint.Endpoint
+	// DNS names whose ownership TXT records were ignored for lacking a valid signature
+	unverifiedOwners map[string]struct{}
+
 	logger logr.Logger
 }
 
@@ -98,6 +120,22 @@ func NewTXTRegistry(ctx context.Context, provider provider.Provider, txtPrefix,
 	}, nil
 }
 
+// WithSigningKey enables signing of the TXT records written by the registry with an HMAC of the given key.
+// TXT records without a valid signature are then ignored, so ownership of records can't be claimed by anyone
+// without the key. All registries sharing a zone must use the same key to recognise each other's ownership.
+func (im *TXTRegistry) WithSigningKey(key []byte) *TXTRegistry {
+	im.txtSigningKey = key
+	return im
+}
+
+// WithUnsignedMigration accepts, while signing is enabled, unsigned TXT records whose only owner is the owner of
+// this registry, so records published before a signing key was set are not lost. The records they hold ownership
+// of are updated, replacing them with signed TXT records.
+func (im *TXTRegistry) WithUnsignedMigration() *TXTRegistry {
+	im.txtSigningMigration = true
+	return im
+}
+
 func getSupportedTypes() []string {
 	return []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS}
 }
@@ -130,6 +168,8 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 
 	labelMap := map[endpoint.EndpointKey]endpoint.Labels{}
 	txtRecordsMap := map[string]struct{}{}
+	unsignedTXTRecords := map[endpoint.EndpointKey]*endpoint.Endpoint{}
+	unverifiedOwners := map[string]struct{}{}
 
 	for _, record := range records {
 		if record.RecordType != endpoint.RecordTypeTXT {
@@ -148,17 +188,29 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		if err != nil {
 			return nil, err
 		}
-
 		endpointName, recordType := im.mapper.toEndpointName(record.DNSName)
 		key := endpoint.EndpointKey{
 			DNSName:       endpointName,
 			RecordType:    recordType,
 			SetIdentifier: record.SetIdentifier,
 		}
+		if im.txtSigningKey != nil && !hmac.Equal([]byte(labels[SignatureLabelKey]), []byte(im.signature(record.DNSName, record.SetIdentifier, labels))) {
+			if im.txtSigningMigration && labels[SignatureLabelKey] == "" && labels[endpoint.OwnerLabelKey] == im.ownerID {
+				im.logger.V(1).Info("migrating unsigned TXT record", "dnsName", record.DNSName, "setIdentifier", record.SetIdentifier)
+				unsignedTXTRecords[key] = record
+			} else {
+				// ownership claimed without a valid signature is ignored, as for an invalid heritage
+				im.logger.V(1).Info("ignoring TXT record without valid signature", "dnsName", record.DNSName, "setIdentifier", record.SetIdentifier)
+				unverifiedOwners[endpointName] = struct{}{}
+				endpoints = append(endpoints, record)
+				continue
+			}
+		}
 		labelMap[key] = labels
 		txtRecordsMap[record.DNSName] = struct{}{}
 	}
 
+	migratedTXTRecords := map[endpoint.EndpointKey]*endpoint.Endpoint{}
 	for _, ep := range endpoints {
 		if ep.Labels == nil {
 			ep.Labels = endpoint.NewLabels()
@@ -192,6 +244,12 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 			}
 		}
 
+		// Force the update of records owned through an unsigned TXT record, so it is replaced by a signed one
+		if txt, unsigned := unsignedTXTRecords[key]; unsigned {
+			migratedTXTRecords[ep.Key()] = txt
+			ep.WithProviderSpecific(providerSpecificForceUpdate, "true")
+		}
+
 		// Handle the migration of TXT records created before the new format (introduced in v0.12.0).
 		// The migration is done for the TXT records owned by this instance only.
 		if len(txtRecordsMap) > 0 && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
@@ -207,6 +265,9 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		}
 	}
 
+	im.unsignedTXTRecords = migratedTXTRecords
+	im.unverifiedOwners = unverifiedOwners
+
 	// Update the cache.
 	if im.cacheInterval > 0 {
 		im.recordsCache = endpoints
@@ -216,6 +277,17 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 	return endpoints, nil
 }
 
+// HasUnverifiedOwner returns whether ownership of records of the given DNS name is claimed by TXT records that were
+// ignored, as of the last call to Records, for lacking a valid signature.
+func (im *TXTRegistry) HasUnverifiedOwner(dnsName string) bool {
+	dnsNameSplit := strings.SplitN(strings.ToLower(dnsName), ".", 2)
+	if im.wildcardReplacement != "" && dnsNameSplit[0] == "*" {
+		dnsNameSplit[0] = strings.ToLower(im.wildcardReplacement)
+	}
+	_, unverified := im.unverifiedOwners[strings.Join(dnsNameSplit, ".")]
+	return unverified
+}
+
 // generateTXTRecord generates both "old" and "new" TXT records.
 // Once we decide to drop old format we need to drop toTXTName() and rename toNewTXTName
 func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpoint {
@@ -238,7 +310,16 @@ func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpo
 	if isAlias, found := r.GetProviderSpecificProperty("alias"); found && isAlias == "true" && recordType == endpoint.RecordTypeA {
 		recordType = endpoint.RecordTypeCNAME
 	}
-	txtNew := endpoint.NewEndpoint(im.mapper.toNewTXTName(r.DNSName, recordType), endpoint.RecordTypeTXT, r.Labels.Serialize(true, im.txtEncryptEnabled, im.txtEncryptAESKey))
+	txtName := im.mapper.toNewTXTName(r.DNSName, recordType)
+	labels := r.Labels
+	if im.txtSigningKey != nil {
+		labels = endpoint.NewLabels()
+		for k, v := range r.Labels {
+			labels[k] = v
+		}
+		labels[SignatureLabelKey] = im.signature(txtName, r.SetIdentifier, labels)
+	}
+	txtNew := endpoint.NewEndpoint(txtName, endpoint.RecordTypeTXT, labels.Serialize(true, im.txtEncryptEnabled, im.txtEncryptAESKey))
 	if txtNew != nil {
 		txtNew.WithSetIdentifier(r.SetIdentifier)
 		txtNew.Labels[endpoint.OwnedRecordLabelKey] = r.DNSName
@@ -249,6 +330,56 @@ func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpo
 	return endpoints
 }
 
+// currentTXTRecords returns the TXT records published for the given record: the unsigned TXT record being
+// migrated, if any, or else the TXT records generated from its labels.
+func (im *TXTRegistry) currentTXTRecords(r *endpoint.Endpoint) []*endpoint.Endpoint {
+	if txt, unsigned := im.unsignedTXTRecords[r.Key()]; unsigned {
+		return []*endpoint.Endpoint{txt}
+	}
+	return im.generateTXTRecord(r)
+}
+
+// signature returns the hex encoded HMAC, with the signing key, of the TXT record with the given name and set
+// identifier holding the given labels. The signature and encryption nonce labels are not signed.
+func (im *TXTRegistry) signature(txtName, setIdentifier string, labels endpoint.Labels) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != SignatureLabelKey && k != txtEncryptionNonceLabelKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	mac := hmac.New(sha256.New, im.txtSigningKey)
+	mac.Write([]byte(strings.ToLower(txtName) + "\n" + setIdentifier))
+	for _, k := range keys {
+		mac.Write([]byte("\n" + k + "=" + labels[k]))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// OwnedRecords returns the keys of all records, including the registry TXT records, owned by this instance
+// when the given endpoints are published. Keys are sorted by DNS name, record type and set identifier.
+func (im *TXTRegistry) OwnedRecords(endpoints []*endpoint.Endpoint) []endpoint.EndpointKey {
+	keys := []endpoint.EndpointKey{}
+	for _, ep := range endpoints {
+		keys = append(keys, ep.Key())
+		for _, txt := range im.generateTXTRecord(ep) {
+			keys = append(keys, txt.Key())
+		}
+	}
+	slices.SortFunc(keys, func(a, b endpoint.EndpointKey) int {
+		if c := strings.Compare(a.DNSName, b.DNSName); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.RecordType, b.RecordType); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SetIdentifier, b.SetIdentifier)
+	})
+	return slices.Compact(keys)
+}
+
 // ApplyChanges updates dns provider with the changes
 // for each created/deleted record it will also take into account TXT records for creation/deletion
 func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
@@ -276,18 +407,25 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		// when we delete TXT records for which value has changed (due to new label) this would still work because
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
 		// !!! After migration to the new TXT registry format we can drop records in old format here!!!
-		filteredChanges.Delete = append(filteredChanges.Delete, im.generateTXTRecord(r)...)
+		filteredChanges.Delete = append(filteredChanges.Delete, im.currentTXTRecords(r)...)
 
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
 		}
 	}
 
+	// unowned records being adopted by this owner have no TXT records yet, they are created instead of updated
+	adopted := map[endpoint.EndpointKey]bool{}
+
 	// make sure TXT records are consistently updated as well
 	for _, r := range filteredChanges.UpdateOld {
-		// when we updateOld TXT records for which value has changed (due to new label) this would still work because
-		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
-		filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, im.generateTXTRecord(r)...)
+		if im.ownerID != "" && r.Labels[endpoint.OwnerLabelKey] == "" {
+			adopted[r.Key()] = true
+		} else {
+			// when we updateOld TXT records for which value has changed (due to new label) this would still work because
+			// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
+			filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, im.currentTXTRecords(r)...)
+		}
 		// remove old version of record from cache
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -296,7 +434,11 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 
 	// make sure TXT records are consistently updated as well
 	for _, r := range filteredChanges.UpdateNew {
-		filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, im.generateTXTRecord(r)...)
+		if adopted[r.Key()] {
+			filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
+		} else {
+			filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, im.generateTXTRecord(r)...)
+		}
 		// add new version of record to cache
 		if im.cacheInterval > 0 {
 			im.addToCache(r)
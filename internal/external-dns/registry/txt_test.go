@@ -534,6 +534,7 @@ func testTXTRegistryApplyChanges(t *testing.T) {
 	t.Run("With Templated Suffix", testTXTRegistryApplyChangesWithTemplatedSuffix)
 	t.Run("With Suffix", testTXTRegistryApplyChangesWithSuffix)
 	t.Run("No prefix", testTXTRegistryApplyChangesNoPrefix)
+	t.Run("Adopt unowned", testTXTRegistryApplyChangesAdoptUnowned)
 }
 
 func testTXTRegistryApplyChangesWithPrefix(t *testing.T) {
@@ -896,6 +897,60 @@ func testTXTRegistryApplyChangesNoPrefix(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func testTXTRegistryApplyChangesAdoptUnowned(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	ctx := context.Background()
+	p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+		},
+	})
+	r, _ := NewTXTRegistry(context.Background(), p, "txt.", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+
+	changes := &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "new-foo.loadbalancer.com", endpoint.RecordTypeCNAME, "owner"),
+		},
+	}
+	expected := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwnerAndOwnedRecord("txt.cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "", "foo.test-zone.example.org"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "new-foo.loadbalancer.com", endpoint.RecordTypeCNAME, "owner"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+		},
+	}
+	p.OnApplyChanges = func(ctx context.Context, got *plan.Changes) {
+		mExpected := map[string][]*endpoint.Endpoint{
+			"Create":    expected.Create,
+			"UpdateNew": expected.UpdateNew,
+			"UpdateOld": expected.UpdateOld,
+			"Delete":    expected.Delete,
+		}
+		mGot := map[string][]*endpoint.Endpoint{
+			"Create":    got.Create,
+			"UpdateNew": got.UpdateNew,
+			"UpdateOld": got.UpdateOld,
+			"Delete":    got.Delete,
+		}
+		assert.True(t, testutils.SamePlanChanges(mGot, mExpected))
+	}
+	err := r.ApplyChanges(ctx, changes)
+	require.NoError(t, err)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "owner", records[0].Labels[endpoint.OwnerLabelKey])
+}
+
 func testTXTRegistryMissingRecords(t *testing.T) {
 	t.Run("No prefix", testTXTRegistryMissingRecordsNoPrefix)
 	t.Run("With Prefix", testTXTRegistryMissingRecordsWithPrefix)
@@ -1500,6 +1555,150 @@ func TestFailGenerateTXT(t *testing.T) {
 	assert.Equal(t, expectedTXT, gotTXT)
 }
 
+func TestOwnedRecords(t *testing.T) {
+	records := []*endpoint.Endpoint{
+		newEndpointWithOwner("foo.test-zone.example.org", "1.1.1.1", endpoint.RecordTypeA, "owner").WithSetIdentifier("set-1"),
+		newEndpointWithOwner("*.test-zone.example.org", "foo.test-zone.example.org", endpoint.RecordTypeCNAME, "owner"),
+		newEndpointWithOwner("bar.test-zone.example.org", "2001:DB8::1", endpoint.RecordTypeAAAA, "owner"),
+	}
+	expected := []endpoint.EndpointKey{
+		{DNSName: "*.test-zone.example.org", RecordType: endpoint.RecordTypeCNAME},
+		{DNSName: "bar.test-zone.example.org", RecordType: endpoint.RecordTypeAAAA},
+		{DNSName: "foo.test-zone.example.org", RecordType: endpoint.RecordTypeA, SetIdentifier: "set-1"},
+		{DNSName: "kuadrant-a-foo.test-zone.example.org", RecordType: endpoint.RecordTypeTXT, SetIdentifier: "set-1"},
+		{DNSName: "kuadrant-aaaa-bar.test-zone.example.org", RecordType: endpoint.RecordTypeTXT},
+		{DNSName: "kuadrant-cname-wildcard.test-zone.example.org", RecordType: endpoint.RecordTypeTXT},
+	}
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	r, _ := NewTXTRegistry(context.Background(), p, "kuadrant-", "", "owner", time.Hour, "wildcard", []string{}, []string{}, false, nil)
+	assert.Equal(t, expected, r.OwnedRecords(records))
+}
+
+func TestTXTRegistrySigning(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	ctx := context.WithValue(context.Background(), provider.RecordsContextKey, []*endpoint.Endpoint{})
+
+	r, _ := NewTXTRegistry(ctx, p, "kuadrant-", "", "owner", 0, "wildcard", []string{}, []string{}, false, nil)
+	r = r.WithSigningKey([]byte("cluster-key"))
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("signed.test-zone.example.org", "1.1.1.1", endpoint.RecordTypeA, "owner"),
+		},
+	}))
+
+	// a record with an ownership TXT record forged without the signing key
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("forged.test-zone.example.org", "2.2.2.2", endpoint.RecordTypeA, ""),
+			newEndpointWithOwnerAndOwnedRecord("kuadrant-a-forged.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "", "forged.test-zone.example.org"),
+		},
+	}))
+
+	// ownership TXT records without a valid signature are returned as unowned records
+	txtRecords := func(records []*endpoint.Endpoint) []string {
+		var names []string
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeTXT {
+				names = append(names, record.DNSName)
+			}
+		}
+		return names
+	}
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kuadrant-a-forged.test-zone.example.org"}, txtRecords(records))
+	for _, record := range records {
+		if record.DNSName == "forged.test-zone.example.org" {
+			assert.Equal(t, "", record.Labels[endpoint.OwnerLabelKey])
+		}
+	}
+
+	other, _ := NewTXTRegistry(ctx, p, "kuadrant-", "", "owner", 0, "wildcard", []string{}, []string{}, false, nil)
+	records, err = other.WithSigningKey([]byte("other-key")).Records(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"kuadrant-a-forged.test-zone.example.org", "kuadrant-a-signed.test-zone.example.org"}, txtRecords(records))
+}
+
+func TestTXTRegistrySigningMigration(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	ctx := context.WithValue(context.Background(), provider.RecordsContextKey, []*endpoint.Endpoint{})
+
+	// records published, by this and another owner, before a signing key was set
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("migrated.test-zone.example.org", "1.1.1.1", endpoint.RecordTypeA, ""),
+			newEndpointWithOwnerAndOwnedRecord("kuadrant-a-migrated.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "", "migrated.test-zone.example.org"),
+			newEndpointWithOwner("other.test-zone.example.org", "2.2.2.2", endpoint.RecordTypeA, ""),
+			newEndpointWithOwnerAndOwnedRecord("kuadrant-a-other.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, "", "other.test-zone.example.org"),
+		},
+	}))
+
+	owners := func(r *TXTRegistry) map[string]string {
+		records, err := r.Records(ctx)
+		require.NoError(t, err)
+		owners := map[string]string{}
+		for _, record := range records {
+			if record.RecordType == endpoint.RecordTypeA {
+				owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey]
+			}
+		}
+		return owners
+	}
+	signed := func(migration bool) *TXTRegistry {
+		r, _ := NewTXTRegistry(ctx, p, "kuadrant-", "", "owner", 0, "wildcard", []string{}, []string{}, false, nil)
+		r = r.WithSigningKey([]byte("cluster-key"))
+		if migration {
+			r = r.WithUnsignedMigration()
+		}
+		return r
+	}
+
+	// without migration the unsigned records are unowned
+	assert.Equal(t, map[string]string{
+		"migrated.test-zone.example.org": "",
+		"other.test-zone.example.org":    "",
+	}, owners(signed(false)))
+
+	// with migration the unsigned records of the owner are owned, and are updated on the next write
+	r := signed(true)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	desired := []*endpoint.Endpoint{newEndpointWithOwner("migrated.test-zone.example.org", "1.1.1.1", endpoint.RecordTypeA, "")}
+	pl := &plan.Plan{
+		Policies:       []plan.Policy{&plan.SyncPolicy{}},
+		Current:        records,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		OwnerID:        "owner",
+	}
+	changes := pl.Calculate().Changes
+	require.Len(t, changes.UpdateNew, 1)
+	assert.Equal(t, "migrated.test-zone.example.org", changes.UpdateNew[0].DNSName)
+	require.NoError(t, r.ApplyChanges(ctx, changes))
+
+	// the TXT records of the migrated records are signed, those of other owners stay unsigned
+	zoneRecords, err := p.Records(ctx)
+	require.NoError(t, err)
+	signatures := map[string]bool{}
+	for _, record := range zoneRecords {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			signatures[record.DNSName] = strings.Contains(record.Targets[0], SignatureLabelKey+"=")
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		"kuadrant-a-migrated.test-zone.example.org": true,
+		"kuadrant-a-other.test-zone.example.org":    false,
+	}, signatures)
+	assert.Equal(t, map[string]string{
+		"migrated.test-zone.example.org": "owner",
+		"other.test-zone.example.org":    "",
+	}, owners(signed(false)))
+}
+
 func TestTXTRegistryApplyChangesEncrypt(t *testing.T) {
 	p := inmemory.NewInMemoryProvider()
 	p.CreateZone(testZone)
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+const kindLabel = "kind"
+
+// cacheListTimeout is the time listing the objects of each kind is given, so a cache not yet synced does not block
+// metrics collection
+const cacheListTimeout = 5 * time.Second
+
+var cacheObjectsDesc = prometheus.NewDesc(
+	"dns_operator_cache_objects",
+	"Number of objects of each kind held in the informer cache of the controller",
+	[]string{kindLabel}, nil)
+
+// cachedKinds returns a new empty list of each kind of object held in the informer cache, keyed by kind
+var cachedKinds = map[string]func() client.ObjectList{
+	"DNSRecord": func() client.ObjectList { return &v1alpha1.DNSRecordList{} },
+	"Secret":    func() client.ObjectList { return &v1.SecretList{} },
+}
+
+// cacheCollector counts the objects held in the informer cache each time metrics are collected
+type cacheCollector struct {
+	reader client.Reader
+}
+
+// RegisterCacheCollector registers a collector of the number of objects of each kind held in the given cache
+func RegisterCacheCollector(cache client.Reader) error {
+	return metrics.Registry.Register(&cacheCollector{reader: cache})
+}
+
+// Describe implements prometheus.Collector
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheObjectsDesc
+}
+
+// Collect implements prometheus.Collector. The cached objects are listed without deep copying them, as only their
+// number is read.
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for kind, newList := range cachedKinds {
+		list := newList()
+		ctx, cancel := context.WithTimeout(context.Background(), cacheListTimeout)
+		err := c.reader.List(ctx, list, client.UnsafeDisableDeepCopy)
+		cancel()
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(cacheObjectsDesc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(cacheObjectsDesc, prometheus.GaugeValue, float64(meta.LenList(list)), kind)
+	}
+}
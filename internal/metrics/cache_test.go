@@ -0,0 +1,42 @@
+//go:build unit
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestCacheCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.DNSRecord{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}},
+		&v1alpha1.DNSRecord{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "provider", Namespace: "default"}},
+	).Build()
+
+	want := `
+# HELP dns_operator_cache_objects Number of objects of each kind held in the informer cache of the controller
+# TYPE dns_operator_cache_objects gauge
+dns_operator_cache_objects{kind="DNSRecord"} 2
+dns_operator_cache_objects{kind="Secret"} 1
+`
+	if err := testutil.CollectAndCompare(&cacheCollector{reader: reader}, strings.NewReader(want)); err != nil {
+		t.Errorf("cacheCollector metrics differ: %v", err)
+	}
+}
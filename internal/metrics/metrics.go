@@ -12,6 +12,11 @@ const (
 	mzRecordNameLabel       = "managed_zone_name"
 	mzRecordNamespaceLabel  = "managed_zone_namespace"
 	mzSecretNameLabel       = "managed_zone_secret_name"
+	providerLabel           = "provider"
+	zoneDomainNameLabel     = "zone_domain_name"
+	operationLabel          = "operation"
+	secretNameLabel         = "secret_name"
+	secretNamespaceLabel    = "secret_namespace"
 )
 
 var (
@@ -27,9 +32,113 @@ var (
 			Help: "Emits one when provider secret is found to be absent, or zero when expected secrets exist",
 		},
 		[]string{mzRecordNameLabel, mzRecordNamespaceLabel, mzSecretNameLabel})
+	PublishDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "dns_provider_record_publish_duration_seconds",
+			Help:    "Time taken from a DNS record spec change being observed to the changes being written to the DNS provider",
+			Buckets: publishBuckets,
+		},
+		[]string{dnsRecordNameLabel, dnsRecordNamespaceLabel})
+	PropagationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "dns_provider_record_propagation_duration_seconds",
+			Help:    "Time taken from a DNS record spec change being observed to the DNS provider being validated to hold the changes",
+			Buckets: publishBuckets,
+		},
+		[]string{dnsRecordNameLabel, dnsRecordNamespaceLabel})
+	DriftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dns_provider_record_drift_corrections_total",
+			Help: "Counts corrections of records in the DNS provider zone found to differ from the endpoints last published for an unchanged DNS record",
+		},
+		[]string{dnsRecordNameLabel, dnsRecordNamespaceLabel})
+	NameServerPropagationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "dns_provider_record_nameserver_propagation_duration_seconds",
+			Help:    "Time taken from changes of a DNS record being published to the authoritative name servers of its zone serving them",
+			Buckets: publishBuckets,
+		},
+		[]string{dnsRecordNameLabel, dnsRecordNamespaceLabel})
+	PublishDeadlineExceeded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dns_provider_record_publish_deadline_exceeded",
+			Help: "Emits one when a DNS record spec change has not been validated in the DNS provider within the record's publish deadline, or zero otherwise. Only exported for DNS records with a publish deadline",
+		},
+		[]string{dnsRecordNameLabel, dnsRecordNamespaceLabel})
+	ZoneChurnCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dns_provider_zone_churn_total",
+			Help: "Counts record deletions and target changes written to a DNS provider zone",
+		},
+		[]string{zoneDomainNameLabel})
+	ZoneChurnAnomaly = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dns_provider_zone_churn_anomaly",
+			Help: "Emits one when the rate of record deletions and target changes in a DNS provider zone exceeds the churn limit, or zero otherwise",
+		},
+		[]string{zoneDomainNameLabel})
+	ShadowDivergence = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dns_provider_record_shadow_divergence",
+			Help: "Number of endpoints of a DNS record missing from, or differing to, the records in the DNS provider zone when running in shadow mode",
+		},
+		[]string{dnsRecordNameLabel, dnsRecordNamespaceLabel})
+	OrphanedRecords = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dns_provider_zone_orphaned_records",
+			Help: "Number of records in a DNS provider zone whose owners are no longer the owner of any DNS record, found by the latest orphan record sweep",
+		},
+		[]string{zoneDomainNameLabel})
+	ProviderClientCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dns_provider_client_created_total",
+			Help: "Counts DNS provider clients created from provider secrets, one for each use of a provider by the controller",
+		},
+		[]string{providerLabel})
+	ProviderSecretListFailing = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dns_provider_secret_list_failing",
+			Help: "Emits one when the last request listing DNS provider zones or records with a provider secret failed, or zero otherwise",
+		},
+		[]string{providerLabel, secretNamespaceLabel, secretNameLabel})
+	ProviderSecretLastList = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dns_provider_secret_last_successful_list_timestamp_seconds",
+			Help: "Unix time of the last successful request listing DNS provider zones or records with a provider secret",
+		},
+		[]string{providerLabel, secretNamespaceLabel, secretNameLabel})
+	ProviderRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dns_provider_request_total",
+			Help: "Counts requests made to the DNS provider API by operation, the basis of provider API costs",
+		},
+		[]string{providerLabel, zoneDomainNameLabel, operationLabel})
+	ProviderRequestCost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dns_provider_request_estimated_cost_total",
+			Help: "Estimated cost of requests made to the DNS provider API by operation, from the configured request costs",
+		},
+		[]string{providerLabel, zoneDomainNameLabel, operationLabel})
 )
 
+// publishBuckets covers durations from one second up to one hour
+var publishBuckets = []float64{1, 2.5, 5, 10, 15, 30, 60, 120, 300, 600, 900, 1800, 3600}
+
 func init() {
 	metrics.Registry.MustRegister(WriteCounter)
 	metrics.Registry.MustRegister(SecretMissing)
+	metrics.Registry.MustRegister(PublishDuration)
+	metrics.Registry.MustRegister(PropagationDuration)
+	metrics.Registry.MustRegister(DriftCorrections)
+	metrics.Registry.MustRegister(NameServerPropagationDuration)
+	metrics.Registry.MustRegister(PublishDeadlineExceeded)
+	metrics.Registry.MustRegister(ZoneChurnCounter)
+	metrics.Registry.MustRegister(ZoneChurnAnomaly)
+	metrics.Registry.MustRegister(ShadowDivergence)
+	metrics.Registry.MustRegister(OrphanedRecords)
+	metrics.Registry.MustRegister(ProviderClientCounter)
+	metrics.Registry.MustRegister(ProviderSecretListFailing)
+	metrics.Registry.MustRegister(ProviderSecretLastList)
+	metrics.Registry.MustRegister(ProviderRequestCounter)
+	metrics.Registry.MustRegister(ProviderRequestCost)
 }
@@ -0,0 +1,104 @@
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// heapProfilePattern matches the file names of captured heap profiles, which sort in the order they were captured
+const heapProfilePattern = "heap-*.pprof"
+
+// HeapProfiler captures a heap profile whenever the heap in use grows above a threshold, e.g. during the reconcile of
+// a large zone, to find the cause of memory spikes that are gone by the time anyone looks.
+type HeapProfiler struct {
+	// Threshold is the number of bytes of heap in use above which a profile is captured
+	Threshold uint64
+	// Interval is the time between checks of the heap in use
+	Interval time.Duration
+	// Dir is the directory profiles are written to
+	Dir string
+	// MaxProfiles is the number of profiles kept, older profiles are removed
+	MaxProfiles int
+}
+
+var _ manager.LeaderElectionRunnable = &HeapProfiler{}
+
+// NeedLeaderElection is false, the memory of every replica is profiled
+func (p *HeapProfiler) NeedLeaderElection() bool {
+	return false
+}
+
+// Start checks the heap in use every Interval until the given context is done. A single profile is captured each time
+// the heap grows above the threshold, another is only captured once it has dropped below it in between.
+func (p *HeapProfiler) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("heap_profiler")
+	if err := os.MkdirAll(p.Dir, 0o700); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	armed := true
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			var memStats runtime.MemStats
+			runtime.ReadMemStats(&memStats)
+			if memStats.HeapAlloc <= p.Threshold {
+				armed = true
+				continue
+			}
+			if !armed {
+				continue
+			}
+			armed = false
+			file, err := p.capture(time.Now())
+			if err != nil {
+				logger.Error(err, "Failed to capture heap profile")
+				continue
+			}
+			logger.Info("Heap in use above threshold, captured heap profile", "heapAlloc", memStats.HeapAlloc,
+				"threshold", p.Threshold, "file", file)
+		}
+	}
+}
+
+// capture writes a heap profile, named after the given time, to Dir and removes all but the newest MaxProfiles
+func (p *HeapProfiler) capture(now time.Time) (string, error) {
+	file := filepath.Join(p.Dir, fmt.Sprintf("heap-%s.pprof", now.UTC().Format("20060102T150405.000Z")))
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	if err = pprof.Lookup("heap").WriteTo(f, 0); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+
+	profiles, err := filepath.Glob(filepath.Join(p.Dir, heapProfilePattern))
+	if err != nil {
+		return file, err
+	}
+	slices.Sort(profiles)
+	for len(profiles) > max(p.MaxProfiles, 1) {
+		if err = os.Remove(profiles[0]); err != nil {
+			return file, err
+		}
+		profiles = profiles[1:]
+	}
+	return file, nil
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package profiling
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHeapProfilerCapture(t *testing.T) {
+	p := &HeapProfiler{Dir: t.TempDir(), MaxProfiles: 2}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var captured []string
+	for i := 0; i < 3; i++ {
+		file, err := p.capture(start.Add(time.Duration(i) * time.Minute))
+		if err != nil {
+			t.Fatalf("capture() error = %v", err)
+		}
+		captured = append(captured, file)
+	}
+
+	profiles, err := filepath.Glob(filepath.Join(p.Dir, heapProfilePattern))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(profiles) != 2 || profiles[0] != captured[1] || profiles[1] != captured[2] {
+		t.Errorf("capture() kept profiles %v, want the newest %v", profiles, captured[1:])
+	}
+}
+
+func TestHeapProfilerStart(t *testing.T) {
+	p := &HeapProfiler{Dir: filepath.Join(t.TempDir(), "profiles"), Interval: 10 * time.Millisecond, MaxProfiles: 5}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// the heap stays above the threshold, a single profile is captured for it
+	profiles, err := filepath.Glob(filepath.Join(p.Dir, heapProfilePattern))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(profiles) != 1 {
+		t.Errorf("Start() captured profiles %v, want one", profiles)
+	}
+}
@@ -0,0 +1,93 @@
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+const (
+	// PathPrefix is the path the profiling endpoints are served under
+	PathPrefix = "/debug/pprof/"
+	// CapturedPathPrefix is the path the heap profiles captured by the HeapProfiler are served under
+	CapturedPathPrefix = PathPrefix + "captured/"
+)
+
+// Handlers returns the pprof profiling endpoints, and the heap profiles captured to the given directory, keyed by path.
+// Every request must be authorized with WithAuthorization.
+func Handlers(capturedDir string) map[string]http.Handler {
+	return map[string]http.Handler{
+		PathPrefix:             http.HandlerFunc(pprof.Index),
+		PathPrefix + "cmdline": http.HandlerFunc(pprof.Cmdline),
+		PathPrefix + "profile": http.HandlerFunc(pprof.Profile),
+		PathPrefix + "symbol":  http.HandlerFunc(pprof.Symbol),
+		PathPrefix + "trace":   http.HandlerFunc(pprof.Trace),
+		CapturedPathPrefix:     http.StripPrefix(CapturedPathPrefix, http.FileServer(http.Dir(capturedDir))),
+	}
+}
+
+// WithAuthorization only passes requests to the given handler from users authorized to get their path, as a non
+// resource URL, e.g. with a ClusterRole granting get on /debug/pprof/*. Users are authenticated with the bearer token
+// of the request, as kube-rbac-proxy does for the metrics endpoint.
+func WithAuthorization(logger logr.Logger, client kubernetes.Interface, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		allowed, err := authorize(req.Context(), client, token, req.URL.Path)
+		if err != nil {
+			logger.Error(err, "Failed to authorize profiling request", "path", req.URL.Path)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// authorize returns true if the user of the given token is allowed to get the given path
+func authorize(ctx context.Context, client kubernetes.Interface, token, path string) (bool, error) {
+	tokenReview, err := client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return false, nil
+	}
+
+	user := tokenReview.Status.User
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:                  user.Username,
+			UID:                   user.UID,
+			Groups:                user.Groups,
+			Extra:                 extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: "get"},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package profiling
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestWithAuthorization(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = review.Spec.Token != "invalid"
+		review.Status.User = authenticationv1.UserInfo{Username: review.Spec.Token}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.User == "admin" && review.Spec.NonResourceAttributes.Path == PathPrefix+"heap"
+		return true, review, nil
+	})
+	handler := WithAuthorization(logr.Discard(), client, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{name: "no token", path: PathPrefix + "heap", wantCode: http.StatusUnauthorized},
+		{name: "invalid token", path: PathPrefix + "heap", token: "invalid", wantCode: http.StatusForbidden},
+		{name: "user not allowed", path: PathPrefix + "heap", token: "developer", wantCode: http.StatusForbidden},
+		{name: "path not allowed", path: PathPrefix + "trace", token: "admin", wantCode: http.StatusForbidden},
+		{name: "allowed", path: PathPrefix + "heap", token: "admin", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Errorf("WithAuthorization() status = %d, want %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
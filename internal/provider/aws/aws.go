@@ -19,13 +19,18 @@ package aws
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/go-logr/logr"
 
 	v1 "k8s.io/api/core/v1"
@@ -43,7 +48,13 @@ const (
 	providerSpecificWeight                   = "aws/weight"
 	providerSpecificGeolocationCountryCode   = "aws/geolocation-country-code"
 	providerSpecificGeolocationContinentCode = "aws/geolocation-continent-code"
+	providerSpecificGeolocationSubdivision   = "aws/geolocation-subdivision-code"
+	providerSpecificFailover                 = "aws/failover"
+	providerSpecificRegion                   = "aws/region"
+	providerSpecificAlias                    = "alias"
 	awsBatchChangeSize                       = 1000
+	awsBatchChangeSizeBytes                  = 32000
+	awsBatchChangeSizeValues                 = 1000
 	awsBatchChangeInterval                   = time.Second
 	awsEvaluateTargetHealth                  = false
 	awsPreferCNAME                           = true
@@ -55,10 +66,13 @@ type Route53DNSProvider struct {
 	awsConfig             externaldnsprovideraws.AWSConfig
 	logger                logr.Logger
 	route53Client         *route53.Route53
+	iamClient             iamiface.IAMAPI
+	stsClient             stsiface.STSAPI
 	healthCheckReconciler provider.HealthCheckReconciler
 }
 
 var _ provider.Provider = &Route53DNSProvider{}
+var _ provider.PermissionChecker = &Route53DNSProvider{}
 
 func NewProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Config) (provider.Provider, error) {
 	config := aws.NewConfig()
@@ -69,7 +83,7 @@ func NewProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Config)
 		return nil, fmt.Errorf("AWS Provider credentials is empty")
 	}
 
-	sessionOpts.Config.Credentials = credentials.NewStaticCredentials(string(s.Data[v1alpha1.AWSAccessKeyIDKey]), string(s.Data[v1alpha1.AWSSecretAccessKeyKey]), "")
+	sessionOpts.Config.Credentials = credentials.NewStaticCredentials(string(s.Data[v1alpha1.AWSAccessKeyIDKey]), string(s.Data[v1alpha1.AWSSecretAccessKeyKey]), string(s.Data[v1alpha1.AWSSessionTokenKey]))
 	sessionOpts.SharedConfigState = session.SharedConfigDisable
 	sess, err := session.NewSessionWithOptions(sessionOpts)
 	if err != nil {
@@ -82,16 +96,18 @@ func NewProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Config)
 	route53Client := route53.New(sess, config)
 
 	awsConfig := externaldnsprovideraws.AWSConfig{
-		DomainFilter:         c.DomainFilter,
-		ZoneIDFilter:         c.ZoneIDFilter,
-		ZoneTypeFilter:       c.ZoneTypeFilter,
-		ZoneTagFilter:        externaldnsprovider.NewZoneTagFilter([]string{}),
-		BatchChangeSize:      awsBatchChangeSize,
-		BatchChangeInterval:  awsBatchChangeInterval,
-		EvaluateTargetHealth: awsEvaluateTargetHealth,
-		PreferCNAME:          awsPreferCNAME,
-		DryRun:               false,
-		ZoneCacheDuration:    awsZoneCacheDuration,
+		DomainFilter:          c.DomainFilter,
+		ZoneIDFilter:          c.ZoneIDFilter,
+		ZoneTypeFilter:        c.ZoneTypeFilter,
+		ZoneTagFilter:         externaldnsprovider.NewZoneTagFilter([]string{}),
+		BatchChangeSize:       awsBatchChangeSize,
+		BatchChangeSizeBytes:  awsBatchChangeSizeBytes,
+		BatchChangeSizeValues: awsBatchChangeSizeValues,
+		BatchChangeInterval:   awsBatchChangeInterval,
+		EvaluateTargetHealth:  awsEvaluateTargetHealth,
+		PreferCNAME:           awsPreferCNAME,
+		DryRun:                c.DryRun,
+		ZoneCacheDuration:     awsZoneCacheDuration,
 	}
 
 	logger := log.FromContext(ctx).WithName("aws-dns").WithValues("region", config.Region)
@@ -107,6 +123,8 @@ func NewProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Config)
 		awsConfig:     awsConfig,
 		logger:        logger,
 		route53Client: route53Client,
+		iamClient:     iam.New(sess, config),
+		stsClient:     sts.New(sess, config),
 	}
 	return p, nil
 }
@@ -114,27 +132,148 @@ func NewProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Config)
 // #### External DNS Provider ####
 
 func (p *Route53DNSProvider) AdjustEndpoints(endpoints []*externaldnsendpoint.Endpoint) ([]*externaldnsendpoint.Endpoint, error) {
+	// CNAME records are not allowed at the zone apex, publish them as route53 ALIAS A records instead, along with the
+	// CNAME records they lead to, e.g. the klb tree of a load balanced record, as ALIAS records can only target A records
+	for _, ep := range endpoints {
+		if ep.RecordType == externaldnsendpoint.RecordTypeCNAME && provider.IsZoneApex(ep, p.awsConfig.DomainFilter) {
+			if alias, ok := ep.GetProviderSpecificProperty(providerSpecificAlias); !ok || alias == "true" {
+				chain, err := apexAliasChain(ep, endpoints)
+				if err != nil {
+					return nil, err
+				}
+				for _, e := range chain {
+					e.RecordType = externaldnsendpoint.RecordTypeA
+					e.SetProviderSpecificProperty(providerSpecificAlias, "true")
+				}
+			}
+		}
+	}
+
 	endpoints, err := p.AWSProvider.AdjustEndpoints(endpoints)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, ep := range endpoints {
-		if prop, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificWeight); ok {
-			ep.DeleteProviderSpecificProperty(v1alpha1.ProviderSpecificWeight)
-			ep.WithProviderSpecific(providerSpecificWeight, prop)
+		if err = adjustGeoCode(ep); err != nil {
+			return nil, err
+		}
+		provider.TranslateProviderSpecific(ep, providerSpecificTranslations)
+	}
+	return endpoints, nil
+}
+
+// apexAliasChain returns the given zone apex CNAME endpoint and the CNAME endpoints of the given endpoints, which are in
+// the same zone, it leads to, to be published as route53 ALIAS A records. Route53 rejects ALIAS records targeting CNAME
+// records, or records outside of the zone, so an error is returned unless each of them has a single target that is
+// an AWS resource such as an ELB, an A endpoint, or another endpoint of the chain.
+func apexAliasChain(apex *externaldnsendpoint.Endpoint, endpoints []*externaldnsendpoint.Endpoint) ([]*externaldnsendpoint.Endpoint, error) {
+	byName := map[string][]*externaldnsendpoint.Endpoint{}
+	for _, e := range endpoints {
+		name := strings.ToLower(strings.TrimSuffix(e.DNSName, "."))
+		byName[name] = append(byName[name], e)
+	}
+
+	var chain []*externaldnsendpoint.Endpoint
+	visited := map[*externaldnsendpoint.Endpoint]bool{}
+	for queue := []*externaldnsendpoint.Endpoint{apex}; len(queue) > 0; queue = queue[1:] {
+		ep := queue[0]
+		if visited[ep] {
+			continue
 		}
+		visited[ep] = true
+		chain = append(chain, ep)
 
-		if prop, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificGeoCode); ok {
-			ep.DeleteProviderSpecificProperty(v1alpha1.ProviderSpecificGeoCode)
-			if provider.IsISO3166Alpha2Code(prop) || prop == "*" {
-				ep.WithProviderSpecific(providerSpecificGeolocationCountryCode, prop)
-			} else {
-				ep.WithProviderSpecific(providerSpecificGeolocationContinentCode, prop)
+		if len(ep.Targets) != 1 {
+			return nil, fmt.Errorf("invalid endpoint %s, CNAME records at the zone apex, and the CNAME records they lead to, "+
+				"are published as route53 ALIAS records, so must have a single target", ep.DNSName)
+		}
+		target := strings.TrimSuffix(ep.Targets[0], ".")
+		if externaldnsprovideraws.CanonicalHostedZone(target) != "" {
+			continue
+		}
+		resolved := false
+		for _, e := range byName[strings.ToLower(target)] {
+			switch e.RecordType {
+			case externaldnsendpoint.RecordTypeA:
+				resolved = true
+			case externaldnsendpoint.RecordTypeCNAME:
+				queue = append(queue, e)
+				resolved = true
 			}
 		}
+		if !resolved {
+			return nil, fmt.Errorf("invalid endpoint %s, CNAME records at the zone apex, and the CNAME records they lead to, "+
+				"are published as route53 ALIAS records, so target %s must be an AWS resource such as an ELB, or an A or CNAME "+
+				"endpoint of the record", ep.DNSName, target)
+		}
 	}
-	return endpoints, nil
+	return chain, nil
+}
+
+// route53Subdivisions are the subdivision codes, by country, route53 supports geolocation for
+var route53Subdivisions = map[string][]string{
+	"US": {
+		"AK", "AL", "AR", "AZ", "CA", "CO", "CT", "DC", "DE", "FL", "GA", "HI", "IA", "ID", "IL", "IN", "KS",
+		"KY", "LA", "MA", "MD", "ME", "MI", "MN", "MO", "MS", "MT", "NC", "ND", "NE", "NH", "NJ", "NM", "NV",
+		"NY", "OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VA", "VT", "WA", "WI", "WV", "WY",
+	},
+}
+
+// adjustGeoCode validates the geo code of the given endpoint, if it has one, replacing continent geo codes, e.g. GEO-EU,
+// with their route53 continent code, and subdivision geo codes, e.g. US-CA, with their route53 country and subdivision
+// codes. Subdivisions route53 has no geolocation for are rejected.
+// Continent codes without the prefix that aren't also country codes are accepted as is.
+func adjustGeoCode(ep *externaldnsendpoint.Endpoint) error {
+	value, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificGeoCode)
+	if !ok {
+		return nil
+	}
+	if continent, ok := provider.ParseContinentCode(value); ok {
+		ep.DeleteProviderSpecificProperty(v1alpha1.ProviderSpecificGeoCode)
+		setProviderSpecificDefault(ep, providerSpecificGeolocationContinentCode, continent)
+		return nil
+	}
+	if country, subdivision, ok := strings.Cut(value, "-"); ok && provider.IsISO3166Alpha2Code(country) {
+		if !slices.Contains(route53Subdivisions[country], subdivision) {
+			return fmt.Errorf("invalid geo code %s for endpoint %s, subdivision %s of %s is not supported by route53", value, ep.DNSName, subdivision, country)
+		}
+		ep.DeleteProviderSpecificProperty(v1alpha1.ProviderSpecificGeoCode)
+		setProviderSpecificDefault(ep, providerSpecificGeolocationCountryCode, country)
+		setProviderSpecificDefault(ep, providerSpecificGeolocationSubdivision, subdivision)
+		return nil
+	}
+	if value == "*" || provider.IsISO3166Alpha2Code(value) || provider.IsContinentCode(value) {
+		return nil
+	}
+	return fmt.Errorf("invalid geo code %s for endpoint %s, must be an ISO 3166 alpha-2 country code, optionally followed by a subdivision code, "+
+		"a continent code prefixed with %s, or *", value, ep.DNSName, v1alpha1.ContinentCodePrefix)
+}
+
+// setProviderSpecificDefault sets the given provider specific property unless it is already set on the endpoint
+func setProviderSpecificDefault(ep *externaldnsendpoint.Endpoint, name, value string) {
+	if _, ok := ep.GetProviderSpecificProperty(name); !ok {
+		ep.WithProviderSpecific(name, value)
+	}
+}
+
+// providerSpecificTranslations maps the provider agnostic provider specific properties to their route53 equivalents
+var providerSpecificTranslations = provider.ProviderSpecificTranslations{
+	v1alpha1.ProviderSpecificWeight: func(_ string) string {
+		return providerSpecificWeight
+	},
+	v1alpha1.ProviderSpecificGeoCode: func(value string) string {
+		if provider.IsISO3166Alpha2Code(value) || value == "*" {
+			return providerSpecificGeolocationCountryCode
+		}
+		return providerSpecificGeolocationContinentCode
+	},
+	v1alpha1.ProviderSpecificFailover: func(_ string) string {
+		return providerSpecificFailover
+	},
+	v1alpha1.ProviderSpecificRegion: func(_ string) string {
+		return providerSpecificRegion
+	},
 }
 
 // #### DNS Operator Provider ####
@@ -0,0 +1,283 @@
+//go:build unit
+
+package aws
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	externaldnsprovideraws "github.com/kuadrant/dns-operator/internal/external-dns/provider/aws"
+)
+
+func TestAdjustGeoCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		geoCode string
+		want    externaldnsendpoint.ProviderSpecific
+		wantErr bool
+	}{
+		{
+			name:    "country code",
+			geoCode: "IE",
+			want:    externaldnsendpoint.ProviderSpecific{{Name: v1alpha1.ProviderSpecificGeoCode, Value: "IE"}},
+		},
+		{
+			name:    "default geo",
+			geoCode: "*",
+			want:    externaldnsendpoint.ProviderSpecific{{Name: v1alpha1.ProviderSpecificGeoCode, Value: "*"}},
+		},
+		{
+			name:    "continent geo code",
+			geoCode: "GEO-NA",
+			want:    externaldnsendpoint.ProviderSpecific{{Name: providerSpecificGeolocationContinentCode, Value: "NA"}},
+		},
+		{
+			name:    "continent code without prefix",
+			geoCode: "EU",
+			want:    externaldnsendpoint.ProviderSpecific{{Name: v1alpha1.ProviderSpecificGeoCode, Value: "EU"}},
+		},
+		{
+			name:    "subdivision geo code",
+			geoCode: "US-CA",
+			want: externaldnsendpoint.ProviderSpecific{
+				{Name: providerSpecificGeolocationCountryCode, Value: "US"},
+				{Name: providerSpecificGeolocationSubdivision, Value: "CA"},
+			},
+		},
+		{
+			name:    "unknown subdivision",
+			geoCode: "US-ZZ",
+			wantErr: true,
+		},
+		{
+			name:    "subdivision of country without subdivision geolocation",
+			geoCode: "GB-SCT",
+			wantErr: true,
+		},
+		{
+			name:    "empty subdivision",
+			geoCode: "US-",
+			wantErr: true,
+		},
+		{
+			name:    "subdivision of invalid country",
+			geoCode: "XX-CA",
+			wantErr: true,
+		},
+		{
+			name:    "invalid continent geo code",
+			geoCode: "GEO-XX",
+			wantErr: true,
+		},
+		{
+			name:    "free form geo code",
+			geoCode: "europe",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := externaldnsendpoint.NewEndpoint("foo.example.com", externaldnsendpoint.RecordTypeCNAME, "lb.example.com").
+				WithProviderSpecific(v1alpha1.ProviderSpecificGeoCode, tt.geoCode)
+			err := adjustGeoCode(ep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("adjustGeoCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(ep.ProviderSpecific, tt.want) {
+				t.Errorf("adjustGeoCode() got = %v, want %v", ep.ProviderSpecific, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustEndpointsZoneApex(t *testing.T) {
+	awsConfig := externaldnsprovideraws.AWSConfig{
+		DomainFilter: externaldnsendpoint.NewDomainFilter([]string{"example.com"}),
+		PreferCNAME:  awsPreferCNAME,
+	}
+	awsProvider, err := externaldnsprovideraws.NewAWSProvider(context.Background(), awsConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Route53DNSProvider{AWSProvider: awsProvider, awsConfig: awsConfig}
+
+	// klb returns the endpoints of a load balanced record, with geo and weighted CNAMEs resolving to A records
+	klb := func() []*externaldnsendpoint.Endpoint {
+		return []*externaldnsendpoint.Endpoint{
+			externaldnsendpoint.NewEndpoint("www.example.com", externaldnsendpoint.RecordTypeCNAME, "klb.example.com"),
+			externaldnsendpoint.NewEndpoint("klb.example.com", externaldnsendpoint.RecordTypeCNAME, "ie.klb.example.com").
+				WithSetIdentifier("IE").WithProviderSpecific(v1alpha1.ProviderSpecificGeoCode, "IE"),
+			externaldnsendpoint.NewEndpoint("klb.example.com", externaldnsendpoint.RecordTypeCNAME, "ie.klb.example.com").
+				WithSetIdentifier("default").WithProviderSpecific(v1alpha1.ProviderSpecificGeoCode, "*"),
+			externaldnsendpoint.NewEndpoint("ie.klb.example.com", externaldnsendpoint.RecordTypeCNAME, "2c71gf.lb-4ej5le.example.com").
+				WithSetIdentifier("2c71gf.lb-4ej5le.example.com").WithProviderSpecific(v1alpha1.ProviderSpecificWeight, "120"),
+			externaldnsendpoint.NewEndpoint("2c71gf.lb-4ej5le.example.com", externaldnsendpoint.RecordTypeA, "172.32.200.1"),
+			externaldnsendpoint.NewEndpoint("eu.klb.example.com", externaldnsendpoint.RecordTypeCNAME, "app.example.org"),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		// wantAliases are the dnsNames of the endpoints, other than the zone apex, published as ALIAS A records
+		wantAliases []string
+		wantErr     bool
+	}{
+		{
+			name:   "A record of the record",
+			target: "2c71gf.lb-4ej5le.example.com",
+		},
+		{
+			name:   "AWS load balancer",
+			target: "lb-1234.eu-west-1.elb.amazonaws.com",
+		},
+		{
+			name:   "klb tree of the record",
+			target: "klb.example.com",
+			wantAliases: []string{
+				"klb.example.com",
+				"ie.klb.example.com",
+			},
+		},
+		{
+			name:    "CNAME record of the record leading outside of the zone",
+			target:  "eu.klb.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "hostname outside of the zone",
+			target:  "app.example.org",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints := append([]*externaldnsendpoint.Endpoint{
+				externaldnsendpoint.NewEndpoint("example.com", externaldnsendpoint.RecordTypeCNAME, tt.target),
+			}, klb()...)
+
+			endpoints, err := p.AdjustEndpoints(endpoints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AdjustEndpoints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if apex := endpoints[0]; apex.RecordType != externaldnsendpoint.RecordTypeA {
+				t.Errorf("AdjustEndpoints() zone apex record type = %s, want A", apex.RecordType)
+			} else if alias, _ := apex.GetProviderSpecificProperty(providerSpecificAlias); alias != "true" {
+				t.Errorf("AdjustEndpoints() zone apex alias = %q, want true", alias)
+			}
+			for _, ep := range endpoints[1:] {
+				alias, _ := ep.GetProviderSpecificProperty(providerSpecificAlias)
+				switch {
+				case slices.Contains(tt.wantAliases, ep.DNSName):
+					if ep.RecordType != externaldnsendpoint.RecordTypeA || alias != "true" {
+						t.Errorf("AdjustEndpoints() %s record type = %s, alias = %q, want an ALIAS A record", ep.DNSName, ep.RecordType, alias)
+					}
+				case ep.RecordType == externaldnsendpoint.RecordTypeCNAME && alias != "false":
+					t.Errorf("AdjustEndpoints() %s alias = %q, want false", ep.DNSName, alias)
+				case ep.RecordType == externaldnsendpoint.RecordTypeA && alias == "true":
+					t.Errorf("AdjustEndpoints() %s is an ALIAS record, want an A record", ep.DNSName)
+				}
+			}
+		})
+	}
+}
+
+type fakeSTS struct {
+	stsiface.STSAPI
+	arn string
+}
+
+func (f *fakeSTS) GetCallerIdentityWithContext(_ aws.Context, _ *sts.GetCallerIdentityInput, _ ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(f.arn)}, nil
+}
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	allowed map[string]bool
+	input   *iam.SimulatePrincipalPolicyInput
+}
+
+func (f *fakeIAM) SimulatePrincipalPolicyWithContext(_ aws.Context, input *iam.SimulatePrincipalPolicyInput, _ ...request.Option) (*iam.SimulatePolicyResponse, error) {
+	f.input = input
+	decision := iam.PolicyEvaluationDecisionTypeImplicitDeny
+	if f.allowed[aws.StringValue(input.PolicySourceArn)] {
+		decision = iam.PolicyEvaluationDecisionTypeAllowed
+	}
+	return &iam.SimulatePolicyResponse{
+		EvaluationResults: []*iam.EvaluationResult{{EvalActionName: input.ActionNames[0], EvalDecision: aws.String(decision)}},
+	}, nil
+}
+
+func TestCheckWritePermission(t *testing.T) {
+	tests := []struct {
+		name          string
+		callerARN     string
+		wantPrincipal string
+		wantAllowed   bool
+		wantErr       bool
+	}{
+		{
+			name:          "allowed user",
+			callerARN:     "arn:aws:iam::123456789012:user/dns-operator",
+			wantPrincipal: "arn:aws:iam::123456789012:user/dns-operator",
+			wantAllowed:   true,
+		},
+		{
+			name:          "assumed role simulated as its role",
+			callerARN:     "arn:aws:sts::123456789012:assumed-role/dns-operator/session",
+			wantPrincipal: "arn:aws:iam::123456789012:role/dns-operator",
+			wantAllowed:   true,
+		},
+		{
+			name:          "denied user",
+			callerARN:     "arn:aws:iam::123456789012:user/readonly",
+			wantPrincipal: "arn:aws:iam::123456789012:user/readonly",
+		},
+		{
+			name:      "federated user",
+			callerARN: "arn:aws:sts::123456789012:federated-user/dns-operator",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeIAM := &fakeIAM{allowed: map[string]bool{
+				"arn:aws:iam::123456789012:user/dns-operator": true,
+				"arn:aws:iam::123456789012:role/dns-operator": true,
+			}}
+			p := &Route53DNSProvider{iamClient: fakeIAM, stsClient: &fakeSTS{arn: tt.callerARN}}
+			allowed, reason, err := p.CheckWritePermission(context.Background(), "/hostedzone/Z123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckWritePermission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if allowed != tt.wantAllowed || (!allowed && reason == "") {
+				t.Errorf("CheckWritePermission() = %v %q, want %v", allowed, reason, tt.wantAllowed)
+			}
+			if got := aws.StringValue(fakeIAM.input.PolicySourceArn); got != tt.wantPrincipal {
+				t.Errorf("CheckWritePermission() simulated principal %s, want %s", got, tt.wantPrincipal)
+			}
+			if got := aws.StringValueSlice(fakeIAM.input.ResourceArns); !reflect.DeepEqual(got, []string{"arn:aws:route53:::hostedzone/Z123"}) {
+				t.Errorf("CheckWritePermission() simulated resources %v", got)
+			}
+		})
+	}
+}
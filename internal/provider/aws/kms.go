@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+
+	"github.com/kuadrant/dns-operator/internal/provider"
+)
+
+// kmsDecrypter is a provider.SecretDecrypter decrypting provider secret data values encrypted with AWS KMS.
+// Each value is a KMS ciphertext blob. The KMS client uses the credentials of the operator, e.g. an IAM role for its
+// ServiceAccount, resolved from the environment.
+type kmsDecrypter struct {
+	once   sync.Once
+	client kmsiface.KMSAPI
+	err    error
+}
+
+var _ provider.SecretDecrypter = &kmsDecrypter{}
+
+func (d *kmsDecrypter) Decrypt(ctx context.Context, data map[string][]byte) (map[string][]byte, error) {
+	d.once.Do(func() {
+		if d.client != nil {
+			return
+		}
+		sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+		if err != nil {
+			d.err = fmt.Errorf("unable to create aws session: %s", err)
+			return
+		}
+		d.client = kms.New(sess)
+	})
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	plaintext := make(map[string][]byte, len(data))
+	for key, ciphertext := range data {
+		out, err := d.client.DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt %s: %w", key, err)
+		}
+		plaintext[key] = out.Plaintext
+	}
+	return plaintext, nil
+}
+
+// Register the KMS secret decrypter with the provider factory
+func init() {
+	provider.RegisterSecretDecrypter("aws-kms", &kmsDecrypter{})
+}
@@ -0,0 +1,46 @@
+//go:build unit
+
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// fakeKMS "decrypts" ciphertext by stripping the "encrypted:" prefix
+type fakeKMS struct {
+	kmsiface.KMSAPI
+}
+
+func (fakeKMS) DecryptWithContext(_ context.Context, in *kms.DecryptInput, _ ...request.Option) (*kms.DecryptOutput, error) {
+	plaintext, ok := bytes.CutPrefix(in.CiphertextBlob, []byte("encrypted:"))
+	if !ok {
+		return nil, errors.New("InvalidCiphertextException")
+	}
+	return &kms.DecryptOutput{Plaintext: plaintext}, nil
+}
+
+func TestKMSDecrypterDecrypt(t *testing.T) {
+	d := &kmsDecrypter{client: fakeKMS{}}
+
+	data, err := d.Decrypt(context.Background(), map[string][]byte{
+		"AWS_ACCESS_KEY_ID":     []byte("encrypted:id"),
+		"AWS_SECRET_ACCESS_KEY": []byte("encrypted:secret"),
+	})
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if string(data["AWS_ACCESS_KEY_ID"]) != "id" || string(data["AWS_SECRET_ACCESS_KEY"]) != "secret" {
+		t.Errorf("Decrypt() = %v, want plaintext values", data)
+	}
+
+	if _, err = d.Decrypt(context.Background(), map[string][]byte{"AWS_REGION": []byte("us-east-1")}); err == nil {
+		t.Errorf("Decrypt() error = nil, want error for plaintext value")
+	}
+}
@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// changeRecordsAction is the IAM action writing the records of a hosted zone
+const changeRecordsAction = "route53:ChangeResourceRecordSets"
+
+// CheckWritePermission implements provider.PermissionChecker. The IAM policies of the principal of the credentials
+// are simulated for route53:ChangeResourceRecordSets on the hosted zone, which requires the credentials to be allowed
+// sts:GetCallerIdentity and iam:SimulatePrincipalPolicy. Service control policies and permission boundaries of the
+// account are not simulated.
+func (p *Route53DNSProvider) CheckWritePermission(ctx context.Context, zoneID string) (bool, string, error) {
+	identity, err := p.stsClient.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return false, "", fmt.Errorf("unable to get the identity of the credentials: %w", err)
+	}
+	principal, err := principalARN(aws.StringValue(identity.Arn))
+	if err != nil {
+		return false, "", err
+	}
+	zoneARN := arn.ARN{
+		Partition: principal.Partition,
+		Service:   "route53",
+		Resource:  "hostedzone/" + strings.TrimPrefix(zoneID, "/hostedzone/"),
+	}
+
+	out, err := p.iamClient.SimulatePrincipalPolicyWithContext(ctx, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal.String()),
+		ActionNames:     aws.StringSlice([]string{changeRecordsAction}),
+		ResourceArns:    aws.StringSlice([]string{zoneARN.String()}),
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("unable to simulate the policies of %s: %w", principal, err)
+	}
+	for _, result := range out.EvaluationResults {
+		if decision := aws.StringValue(result.EvalDecision); decision != iam.PolicyEvaluationDecisionTypeAllowed {
+			return false, fmt.Sprintf("%s is %s %s on %s", principal, decision, changeRecordsAction, zoneARN), nil
+		}
+	}
+	return true, "", nil
+}
+
+// principalARN returns the ARN of the IAM principal, whose policies can be simulated, of the given caller identity ARN.
+// Assumed role sessions are simulated as their role, roles with a path are not supported as the path is not part of
+// the session ARN.
+func principalARN(callerARN string) (arn.ARN, error) {
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return arn.ARN{}, fmt.Errorf("invalid caller identity ARN %s: %w", callerARN, err)
+	}
+	if parsed.Service != "sts" {
+		return parsed, nil
+	}
+	// arn:aws:sts::<account>:assumed-role/<role>/<session>
+	parts := strings.Split(parsed.Resource, "/")
+	if len(parts) != 3 || parts[0] != "assumed-role" {
+		return arn.ARN{}, fmt.Errorf("policies of caller identity %s cannot be simulated", callerARN)
+	}
+	return arn.ARN{Partition: parsed.Partition, Service: "iam", AccountID: parsed.AccountID, Resource: "role/" + parts[1]}, nil
+}
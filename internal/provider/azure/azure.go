@@ -9,6 +9,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
 
 	"github.com/kuadrant/dns-operator/api/v1alpha1"
 	externaldnsproviderazure "github.com/kuadrant/dns-operator/internal/external-dns/provider/azure"
@@ -43,7 +44,7 @@ func NewAzureProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Co
 	azureConfig.DomainFilter = c.DomainFilter
 	azureConfig.ZoneNameFilter = c.DomainFilter
 	azureConfig.IDFilter = c.ZoneIDFilter
-	azureConfig.DryRun = false
+	azureConfig.DryRun = c.DryRun
 
 	azureProvider, err := externaldnsproviderazure.NewAzureProviderFromConfig(ctx, azureConfig)
 
@@ -86,6 +87,19 @@ func (p *AzureProvider) DNSZoneForHost(ctx context.Context, host string) (*provi
 	return provider.FindDNSZoneForHost(ctx, host, zones)
 }
 
+// AdjustEndpoints rejects endpoints using routing policies the azure provider does not support
+func (p *AzureProvider) AdjustEndpoints(endpoints []*externaldnsendpoint.Endpoint) ([]*externaldnsendpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		if _, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificFailover); ok {
+			return nil, fmt.Errorf("invalid endpoint %s, failover routing is not supported by the azure provider", ep.DNSName)
+		}
+		if _, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificRegion); ok {
+			return nil, fmt.Errorf("invalid endpoint %s, latency routing is not supported by the azure provider", ep.DNSName)
+		}
+	}
+	return p.AzureProvider.AdjustEndpoints(endpoints)
+}
+
 func (p *AzureProvider) HealthCheckReconciler() provider.HealthCheckReconciler {
 	return NewAzureHealthCheckReconciler()
 }
@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// continentCodes are the two letter codes of the continents
+var continentCodes = []string{"AF", "AN", "AS", "EU", "NA", "OC", "SA"}
+
+// IsContinentCode returns true if it's a valid two letter continent code that isn't also an ISO 3166 Alpha 2 country code
+func IsContinentCode(code string) bool {
+	return slices.Contains(continentCodes, code) && !IsISO3166Alpha2Code(code)
+}
+
+// ParseContinentCode returns the two letter continent code of a continent geo code, e.g. EU for GEO-EU, and true if
+// the given code is a valid continent geo code.
+func ParseContinentCode(code string) (string, bool) {
+	continent, ok := strings.CutPrefix(code, v1alpha1.ContinentCodePrefix)
+	return continent, ok && slices.Contains(continentCodes, continent)
+}
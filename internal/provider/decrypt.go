@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// SecretDecrypter decrypts the data of provider secrets stored encrypted, e.g. with a KMS, for organizations that
+// prohibit plaintext provider credentials in the cluster.
+type SecretDecrypter interface {
+	// Decrypt returns the plaintext of each of the given encrypted data values
+	Decrypt(ctx context.Context, data map[string][]byte) (map[string][]byte, error)
+}
+
+var (
+	decrypters     = make(map[string]SecretDecrypter)
+	decryptersLock sync.RWMutex
+)
+
+// RegisterSecretDecrypter will register a secret decrypter, so it can be used to decrypt provider secrets annotated
+// with the given name.
+func RegisterSecretDecrypter(name string, d SecretDecrypter) {
+	decryptersLock.Lock()
+	defer decryptersLock.Unlock()
+	decrypters[name] = d
+}
+
+// decryptSecret returns a copy of the given provider secret with its data decrypted by the secret decrypter named in
+// its v1alpha1.SecretDecrypterAnnotation. Secrets without the annotation are returned unchanged.
+func decryptSecret(ctx context.Context, secret *v1.Secret) (*v1.Secret, error) {
+	name, ok := secret.Annotations[v1alpha1.SecretDecrypterAnnotation]
+	if !ok {
+		return secret, nil
+	}
+
+	decryptersLock.RLock()
+	d, ok := decrypters[name]
+	decryptersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("secret decrypter '%s' not registered", name)
+	}
+
+	data, err := d.Decrypt(ctx, secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt provider secret %s/%s with '%s': %w", secret.Namespace, secret.Name, name, err)
+	}
+	secret = secret.DeepCopy()
+	secret.Data = data
+	return secret, nil
+}
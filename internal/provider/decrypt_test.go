@@ -0,0 +1,81 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// upperDecrypter "decrypts" data values by upper casing them
+type upperDecrypter struct{}
+
+func (upperDecrypter) Decrypt(_ context.Context, data map[string][]byte) (map[string][]byte, error) {
+	plaintext := map[string][]byte{}
+	for key, value := range data {
+		plaintext[key] = []byte(strings.ToUpper(string(value)))
+	}
+	return plaintext, nil
+}
+
+func TestDecryptSecret(t *testing.T) {
+	RegisterSecretDecrypter("upper", upperDecrypter{})
+
+	testCases := []struct {
+		name      string
+		decrypter *string
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name:      "plaintext secret",
+			wantValue: "value",
+		},
+		{
+			name:      "encrypted secret",
+			decrypter: ptr("upper"),
+			wantValue: "VALUE",
+		},
+		{
+			name:      "unregistered decrypter",
+			decrypter: ptr("unknown"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "test", Annotations: map[string]string{}},
+				Data:       map[string][]byte{"key": []byte("value")},
+			}
+			if tt.decrypter != nil {
+				secret.Annotations[v1alpha1.SecretDecrypterAnnotation] = *tt.decrypter
+			}
+
+			decrypted, err := decryptSecret(context.Background(), secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decryptSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := string(decrypted.Data["key"]); got != tt.wantValue {
+				t.Errorf("decryptSecret() data = %s, want %s", got, tt.wantValue)
+			}
+			if got := string(secret.Data["key"]); got != "value" {
+				t.Errorf("decryptSecret() modified the given secret data to %s", got)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
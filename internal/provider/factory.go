@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
 )
 
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
@@ -57,11 +58,17 @@ type Factory interface {
 type factory struct {
 	client.Client
 	providers []string
+	// readerFor returns the reader used to read provider secrets in the given namespace
+	readerFor func(namespace string) (client.Reader, error)
 }
 
 // NewFactory returns a new provider factory with the given client and given providers enabled.
 // Will return an error if any given provider has no registered provider implementation.
 func NewFactory(c client.Client, p []string) (Factory, error) {
+	return newFactory(c, p)
+}
+
+func newFactory(c client.Client, p []string) (*factory, error) {
 	var err error
 	registeredProviders := maps.Keys(constructors)
 	for _, provider := range p {
@@ -69,7 +76,11 @@ func NewFactory(c client.Client, p []string) (Factory, error) {
 			err = errors.Join(err, fmt.Errorf("provider '%s' not registered", provider))
 		}
 	}
-	return &factory{Client: c, providers: p}, err
+	f := &factory{Client: c, providers: p}
+	f.readerFor = func(_ string) (client.Reader, error) {
+		return f.Client, nil
+	}
+	return f, err
 }
 
 // ProviderFor will return a Provider interface for the given ProviderAccessor secret.
@@ -82,7 +93,11 @@ func (f *factory) ProviderFor(ctx context.Context, pa v1alpha1.ProviderAccessor,
 			Namespace: pa.GetNamespace(),
 		}}
 
-	if err := f.Client.Get(ctx, client.ObjectKeyFromObject(providerSecret), providerSecret); err != nil {
+	reader, err := f.readerFor(providerSecret.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err = reader.Get(ctx, client.ObjectKeyFromObject(providerSecret), providerSecret); err != nil {
 		return nil, err
 	}
 
@@ -91,6 +106,15 @@ func (f *factory) ProviderFor(ctx context.Context, pa v1alpha1.ProviderAccessor,
 		return nil, err
 	}
 
+	providerSecret, err = decryptSecret(ctx, providerSecret)
+	if err != nil {
+		return nil, err
+	}
+	providerSecret, err = resolveVaultCredentials(ctx, providerSecret)
+	if err != nil {
+		return nil, err
+	}
+
 	constructorsLock.RLock()
 	defer constructorsLock.RUnlock()
 	if constructor, ok := constructors[provider]; ok {
@@ -98,7 +122,13 @@ func (f *factory) ProviderFor(ctx context.Context, pa v1alpha1.ProviderAccessor,
 			return nil, fmt.Errorf("provider '%s' not enabled", provider)
 		}
 		logger.V(1).Info(fmt.Sprintf("initializing %s provider with config", provider), "config", c)
-		return constructor(ctx, providerSecret, c)
+		p, err := constructor(ctx, providerSecret, c)
+		if err != nil {
+			return nil, err
+		}
+		metrics.ProviderClientCounter.WithLabelValues(provider).Inc()
+		p = newInstrumentedProvider(p, provider, client.ObjectKeyFromObject(providerSecret), c)
+		return newFrozenProvider(p, providerSecret.Annotations[v1alpha1.FreezeZonesAnnotation]), nil
 	}
 
 	return nil, fmt.Errorf("provider '%s' not registered", provider)
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultInjectionConfig configures faults injected into provider writes.
+// It is intended for validating alerting and failover runbooks in non production environments only.
+type FaultInjectionConfig struct {
+	// Zones is the list of zone domain names faults are injected for. Writes to any other zone are unaffected.
+	Zones []string
+	// WriteDelay is the time each write to a selected zone is delayed by.
+	WriteDelay time.Duration
+	// FailWrites causes each write to a selected zone to fail with ErrInjectedFault, after any configured delay.
+	FailWrites bool
+}
+
+// faultInjectingFactory is a Factory that wraps all providers it returns in a faultInjectingProvider
+type faultInjectingFactory struct {
+	Factory
+	config FaultInjectionConfig
+}
+
+// NewFaultInjectingFactory returns a Factory that injects faults, as configured by the given FaultInjectionConfig,
+// into writes made by all providers returned by the given Factory.
+func NewFaultInjectingFactory(f Factory, config FaultInjectionConfig) Factory {
+	return &faultInjectingFactory{Factory: f, config: config}
+}
+
+func (f *faultInjectingFactory) ProviderFor(ctx context.Context, pa v1alpha1.ProviderAccessor, c Config) (Provider, error) {
+	p, err := f.Factory.ProviderFor(ctx, pa, c)
+	if err != nil {
+		return nil, err
+	}
+	return &faultInjectingProvider{Provider: p, config: f.config}, nil
+}
+
+// faultInjectingProvider is a Provider that delays or fails writes to selected zones
+type faultInjectingProvider struct {
+	Provider
+	config FaultInjectionConfig
+}
+
+var _ Provider = &faultInjectingProvider{}
+
+// Unwrap returns the wrapped Provider
+func (p *faultInjectingProvider) Unwrap() Provider {
+	return p.Provider
+}
+
+// ApplyChanges implements externaldnsprovider.Provider
+func (p *faultInjectingProvider) ApplyChanges(ctx context.Context, changes *externaldnsplan.Changes) error {
+	zone, ok := p.selectedZone(changes)
+	if !ok {
+		return p.Provider.ApplyChanges(ctx, changes)
+	}
+	logger := log.FromContext(ctx).WithValues("zone", zone)
+
+	if p.config.WriteDelay > 0 {
+		logger.Info("injecting write delay", "delay", p.config.WriteDelay)
+		select {
+		case <-time.After(p.config.WriteDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if p.config.FailWrites {
+		logger.Info("injecting write failure")
+		return fmt.Errorf("%w: write to zone %s failed", ErrInjectedFault, zone)
+	}
+
+	return p.Provider.ApplyChanges(ctx, changes)
+}
+
+// selectedZone returns the first configured zone that any of the changed endpoints belong to
+func (p *faultInjectingProvider) selectedZone(changes *externaldnsplan.Changes) (string, bool) {
+	return zoneForChanges(changes, p.config.Zones)
+}
+
+// zoneForChanges returns the first of the given zones that any of the changed endpoints belong to.
+// A zone of "*" matches all endpoints.
+func zoneForChanges(changes *externaldnsplan.Changes, zones []string) (string, bool) {
+	for _, eps := range [][]*externaldnsendpoint.Endpoint{changes.Create, changes.UpdateNew, changes.UpdateOld, changes.Delete} {
+		for _, ep := range eps {
+			if zone, ok := zoneForName(ep.DNSName, zones); ok {
+				return zone, true
+			}
+		}
+	}
+	return "", false
+}
+
+// zoneForName returns the first of the given zones that the given domain name belongs to.
+// A zone of "*" matches all domain names.
+func zoneForName(dnsName string, zones []string) (string, bool) {
+	dnsName = strings.ToLower(strings.TrimSuffix(dnsName, "."))
+	for _, zone := range zones {
+		zone = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(zone), "."))
+		if zone == "*" || dnsName == zone || strings.HasSuffix(dnsName, "."+zone) {
+			return zone, true
+		}
+	}
+	return "", false
+}
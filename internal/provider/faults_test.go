@@ -0,0 +1,84 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+)
+
+type applyCountingProvider struct {
+	Provider
+	applied int
+}
+
+func (p *applyCountingProvider) ApplyChanges(_ context.Context, _ *externaldnsplan.Changes) error {
+	p.applied++
+	return nil
+}
+
+func TestFaultInjectingProviderApplyChanges(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      FaultInjectionConfig
+		dnsName     string
+		wantErr     error
+		wantApplied int
+		minDuration time.Duration
+	}{
+		{
+			name:        "zone not selected",
+			config:      FaultInjectionConfig{Zones: []string{"example.com"}, FailWrites: true},
+			dnsName:     "foo.example.org",
+			wantApplied: 1,
+		},
+		{
+			name:        "similar zone not selected",
+			config:      FaultInjectionConfig{Zones: []string{"example.com"}, FailWrites: true},
+			dnsName:     "foo.myexample.com",
+			wantApplied: 1,
+		},
+		{
+			name:        "selected zone write fails",
+			config:      FaultInjectionConfig{Zones: []string{"example.com"}, FailWrites: true},
+			dnsName:     "foo.example.com",
+			wantErr:     ErrInjectedFault,
+			wantApplied: 0,
+		},
+		{
+			name:        "selected zone write delayed",
+			config:      FaultInjectionConfig{Zones: []string{"Example.com."}, WriteDelay: 50 * time.Millisecond},
+			dnsName:     "foo.example.com",
+			wantApplied: 1,
+			minDuration: 50 * time.Millisecond,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			inner := &applyCountingProvider{}
+			p := &faultInjectingProvider{Provider: inner, config: testCase.config}
+			changes := &externaldnsplan.Changes{
+				Create: []*externaldnsendpoint.Endpoint{
+					externaldnsendpoint.NewEndpoint(testCase.dnsName, externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+				},
+			}
+
+			start := time.Now()
+			err := p.ApplyChanges(context.Background(), changes)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("expected error '%v' got '%v'", testCase.wantErr, err)
+			}
+			if inner.applied != testCase.wantApplied {
+				t.Errorf("expected %v writes got %v", testCase.wantApplied, inner.applied)
+			}
+			if elapsed := time.Since(start); elapsed < testCase.minDuration {
+				t.Errorf("expected write to take at least %v took %v", testCase.minDuration, elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+)
+
+var ErrZoneFrozen = errors.New("zone frozen")
+
+// frozenProvider is a Provider that rejects all writes to frozen zones
+type frozenProvider struct {
+	Provider
+	zones []string
+}
+
+var _ Provider = &frozenProvider{}
+
+// Unwrap returns the wrapped Provider
+func (p *frozenProvider) Unwrap() Provider {
+	return p.Provider
+}
+
+// newFrozenProvider returns the given Provider wrapped in a frozenProvider if the given FreezeZonesAnnotation
+// value lists any zones, otherwise the given Provider is returned unchanged.
+func newFrozenProvider(p Provider, frozenZones string) Provider {
+	if strings.TrimSpace(frozenZones) == "" {
+		return p
+	}
+	return &frozenProvider{Provider: p, zones: strings.Split(frozenZones, ",")}
+}
+
+// ApplyChanges implements externaldnsprovider.Provider
+func (p *frozenProvider) ApplyChanges(ctx context.Context, changes *externaldnsplan.Changes) error {
+	if zone, ok := zoneForChanges(changes, p.zones); ok {
+		log.FromContext(ctx).Info("zone is frozen, not applying changes", "zone", zone)
+		return fmt.Errorf("%w: writes to zone %s are stopped", ErrZoneFrozen, zone)
+	}
+	return p.Provider.ApplyChanges(ctx, changes)
+}
+
+// ZoneFrozen returns true if writes to the zone with the given domain name are stopped for the given Provider,
+// unwrapping the Provider to find whether it is frozen. Writes other than record changes, e.g. to health checks,
+// must not be made to frozen zones either.
+func ZoneFrozen(p Provider, zoneDomainName string) bool {
+	for {
+		if frozen, ok := p.(*frozenProvider); ok {
+			_, ok = zoneForName(zoneDomainName, frozen.zones)
+			return ok
+		}
+		wrapped, ok := p.(wrappedProvider)
+		if !ok {
+			return false
+		}
+		p = wrapped.Unwrap()
+	}
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+)
+
+func TestFrozenProviderApplyChanges(t *testing.T) {
+	testCases := []struct {
+		name        string
+		frozenZones string
+		dnsName     string
+		wantErr     error
+		wantApplied int
+	}{
+		{
+			name:        "no zones frozen",
+			frozenZones: "",
+			dnsName:     "foo.example.com",
+			wantApplied: 1,
+		},
+		{
+			name:        "other zone frozen",
+			frozenZones: "example.org",
+			dnsName:     "foo.example.com",
+			wantApplied: 1,
+		},
+		{
+			name:        "zone frozen",
+			frozenZones: "example.org, example.com",
+			dnsName:     "foo.example.com",
+			wantErr:     ErrZoneFrozen,
+			wantApplied: 0,
+		},
+		{
+			name:        "all zones frozen",
+			frozenZones: "*",
+			dnsName:     "foo.example.com",
+			wantErr:     ErrZoneFrozen,
+			wantApplied: 0,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			inner := &applyCountingProvider{}
+			p := newFrozenProvider(inner, testCase.frozenZones)
+			changes := &externaldnsplan.Changes{
+				Create: []*externaldnsendpoint.Endpoint{
+					externaldnsendpoint.NewEndpoint(testCase.dnsName, externaldnsendpoint.RecordTypeA, "127.0.0.1"),
+				},
+			}
+
+			err := p.ApplyChanges(context.Background(), changes)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("expected error '%v' got '%v'", testCase.wantErr, err)
+			}
+			if inner.applied != testCase.wantApplied {
+				t.Errorf("expected %v writes got %v", testCase.wantApplied, inner.applied)
+			}
+		})
+	}
+}
+
+func TestZoneFrozen(t *testing.T) {
+	inner := &applyCountingProvider{}
+	if ZoneFrozen(newFrozenProvider(inner, ""), "example.com") {
+		t.Errorf("expected zone example.com not to be frozen without frozen zones")
+	}
+	p := newInstrumentedProvider(newFrozenProvider(inner, "example.org, example.com."), "stub", client.ObjectKey{}, Config{})
+	if !ZoneFrozen(p, "example.com") {
+		t.Errorf("expected zone example.com to be frozen")
+	}
+	if ZoneFrozen(p, "example.net") {
+		t.Errorf("expected zone example.net not to be frozen")
+	}
+	if !ZoneFrozen(newFrozenProvider(inner, "*"), "example.net") {
+		t.Errorf("expected zone example.net to be frozen with all zones frozen")
+	}
+}
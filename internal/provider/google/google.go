@@ -134,7 +134,7 @@ func NewProviderFromSecret(ctx context.Context, s *corev1.Secret, c provider.Con
 		ZoneTypeFilter:      c.ZoneTypeFilter,
 		BatchChangeSize:     GoogleBatchChangeSize,
 		BatchChangeInterval: GoogleBatchChangeInterval,
-		DryRun:              false,
+		DryRun:              c.DryRun,
 	}
 
 	logger := log.FromContext(ctx).WithName("google-dns").WithValues("project", project)
@@ -187,6 +187,20 @@ func (p *GoogleDNSProvider) Records(ctx context.Context) (endpoints []*externald
 
 // AdjustEndpoints takes source endpoints and translates them to a google specific format
 func (p *GoogleDNSProvider) AdjustEndpoints(endpoints []*externaldnsendpoint.Endpoint) ([]*externaldnsendpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		if _, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificFailover); ok {
+			return nil, fmt.Errorf("invalid endpoint %s, failover routing is not supported by the google provider", ep.DNSName)
+		}
+		if _, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificRegion); ok {
+			return nil, fmt.Errorf("invalid endpoint %s, latency routing is not supported by the google provider", ep.DNSName)
+		}
+		if geo, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificGeoCode); ok && strings.HasPrefix(geo, v1alpha1.ContinentCodePrefix) {
+			return nil, fmt.Errorf("invalid geo code %s for endpoint %s, continent geo codes are not supported by the google provider", geo, ep.DNSName)
+		}
+		if ep.RecordType == externaldnsendpoint.RecordTypeCNAME && provider.IsZoneApex(ep, p.googleConfig.DomainFilter) {
+			return nil, fmt.Errorf("invalid endpoint %s, CNAME records at the zone apex are not supported by the google provider", ep.DNSName)
+		}
+	}
 	return endpointsToGoogleFormat(endpoints), nil
 }
 
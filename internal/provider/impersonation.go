@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewImpersonatingFactory returns a new provider factory, as NewFactory, that reads the provider secret of each
+// ProviderAccessor impersonating the ServiceAccount with the given name in the namespace of the ProviderAccessor.
+// Access to provider secrets is then enforced by the RBAC of each namespace's ServiceAccount rather than the
+// permissions of the operator, which must be allowed to impersonate the ServiceAccounts.
+func NewImpersonatingFactory(c client.Client, p []string, cfg *rest.Config, serviceAccountName string) (Factory, error) {
+	f, err := newFactory(c, p)
+	if err != nil {
+		return nil, err
+	}
+	i := &impersonator{
+		config:             cfg,
+		options:            client.Options{Scheme: c.Scheme(), Mapper: c.RESTMapper()},
+		serviceAccountName: serviceAccountName,
+	}
+	f.readerFor = i.readerFor
+	return f, nil
+}
+
+// impersonator creates, and caches, clients impersonating a ServiceAccount in each namespace
+type impersonator struct {
+	config             *rest.Config
+	options            client.Options
+	serviceAccountName string
+	clients            sync.Map
+}
+
+func (i *impersonator) readerFor(namespace string) (client.Reader, error) {
+	if c, ok := i.clients.Load(namespace); ok {
+		return c.(client.Reader), nil
+	}
+	c, err := client.New(impersonatedConfig(i.config, namespace, i.serviceAccountName), i.options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client impersonating service account %s/%s: %w", namespace, i.serviceAccountName, err)
+	}
+	i.clients.Store(namespace, c)
+	return c, nil
+}
+
+// impersonatedConfig returns a copy of the given config impersonating the named ServiceAccount in the given namespace
+func impersonatedConfig(cfg *rest.Config, namespace, serviceAccountName string) *rest.Config {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Impersonate = rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccountName),
+	}
+	return cfg
+}
@@ -0,0 +1,66 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestImpersonatedConfig(t *testing.T) {
+	cfg := &rest.Config{Host: "https://example.com"}
+	impersonated := impersonatedConfig(cfg, "tenant", "dns-operator")
+
+	if got, want := impersonated.Impersonate.UserName, "system:serviceaccount:tenant:dns-operator"; got != want {
+		t.Errorf("impersonatedConfig() UserName = %s, want %s", got, want)
+	}
+	if impersonated.Host != cfg.Host {
+		t.Errorf("impersonatedConfig() Host = %s, want %s", impersonated.Host, cfg.Host)
+	}
+	if cfg.Impersonate.UserName != "" {
+		t.Errorf("impersonatedConfig() modified the given config")
+	}
+}
+
+func TestFactoryReadsSecretWithNamespaceReader(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "tenant"}, Type: v1alpha1.SecretTypeKuadrantInmemory}
+	f, _ := newFactory(fake.NewClientBuilder().WithObjects(secret).Build(), []string{"inmemory"})
+
+	var readNamespace string
+	f.readerFor = func(namespace string) (client.Reader, error) {
+		readNamespace = namespace
+		return forbiddenReader{}, nil
+	}
+
+	record := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "record", Namespace: "tenant"},
+		Spec:       v1alpha1.DNSRecordSpec{ProviderRef: v1alpha1.ProviderRef{Name: "creds"}},
+	}
+	_, err := f.ProviderFor(context.Background(), record, Config{})
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("ProviderFor() error = %v, want forbidden", err)
+	}
+	if readNamespace != "tenant" {
+		t.Errorf("ProviderFor() read secret with reader for namespace %q, want %q", readNamespace, "tenant")
+	}
+}
+
+// forbiddenReader is a client.Reader denying all reads
+type forbiddenReader struct{}
+
+func (forbiddenReader) Get(_ context.Context, key client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+	return apierrors.NewForbidden(v1.Resource("secrets"), key.Name, nil)
+}
+
+func (forbiddenReader) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+	return apierrors.NewForbidden(v1.Resource("secrets"), "", nil)
+}
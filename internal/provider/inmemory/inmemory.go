@@ -18,10 +18,12 @@ package inmemory
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
 
 	"github.com/kuadrant/dns-operator/api/v1alpha1"
 	"github.com/kuadrant/dns-operator/internal/external-dns/provider/inmemory"
@@ -50,6 +52,7 @@ func NewProviderFromSecret(ctx context.Context, s *v1.Secret, c provider.Config)
 		inmemory.InMemoryWithClient(client),
 		inmemory.InMemoryInitZones(initZones),
 		inmemory.InMemoryWithDomain(c.DomainFilter),
+		inmemory.InMemoryWithDryRun(c.DryRun),
 		inmemory.InMemoryWithLogging())
 	p := &InMemoryDNSProvider{
 		InMemoryProvider: inmemoryProvider,
@@ -90,6 +93,19 @@ func (p *InMemoryDNSProvider) DNSZoneForHost(ctx context.Context, host string) (
 	return provider.FindDNSZoneForHost(ctx, host, zones)
 }
 
+// AdjustEndpoints rejects endpoints using routing policies the inmemory provider does not support
+func (p *InMemoryDNSProvider) AdjustEndpoints(endpoints []*externaldnsendpoint.Endpoint) ([]*externaldnsendpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		if _, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificFailover); ok {
+			return nil, fmt.Errorf("invalid endpoint %s, failover routing is not supported by the inmemory provider", ep.DNSName)
+		}
+		if _, ok := ep.GetProviderSpecificProperty(v1alpha1.ProviderSpecificRegion); ok {
+			return nil, fmt.Errorf("invalid endpoint %s, latency routing is not supported by the inmemory provider", ep.DNSName)
+		}
+	}
+	return p.InMemoryProvider.AdjustEndpoints(endpoints)
+}
+
 func (i *InMemoryDNSProvider) HealthCheckReconciler() provider.HealthCheckReconciler {
 	return &provider.FakeHealthCheckReconciler{}
 }
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+// requestCosts are the estimated costs of requests made to the provider API, by provider and operation
+var requestCosts map[string]map[string]float64
+
+// ConfigureRequestCosts sets the estimated costs of requests made to the provider API, by provider and operation, that
+// are added up by the dns_provider_request_estimated_cost_total metric. Requests of operations without a cost are
+// counted but not costed.
+func ConfigureRequestCosts(costs map[string]map[string]float64) {
+	requestCosts = costs
+}
+
+// instrumentedProvider is a Provider that counts requests made to the provider API and their estimated cost, and
+// tracks whether those listing zones and records with its provider secret succeed for the readiness check
+type instrumentedProvider struct {
+	Provider
+	name   string
+	zone   string
+	secret client.ObjectKey
+}
+
+var _ Provider = &instrumentedProvider{}
+
+// Unwrap returns the wrapped Provider
+func (p *instrumentedProvider) Unwrap() Provider {
+	return p.Provider
+}
+
+// newInstrumentedProvider returns the given Provider, created by the named provider constructor from the given provider
+// secret and Config, wrapped in an instrumentedProvider. Requests are counted against the zones in the Config domain
+// filter.
+func newInstrumentedProvider(p Provider, name string, secret client.ObjectKey, c Config) Provider {
+	return &instrumentedProvider{Provider: p, name: name, zone: strings.Join(c.DomainFilter.Filters, ","), secret: secret}
+}
+
+func (p *instrumentedProvider) count(operation string) {
+	metrics.ProviderRequestCounter.WithLabelValues(p.name, p.zone, operation).Inc()
+	if cost, ok := requestCosts[p.name][operation]; ok {
+		metrics.ProviderRequestCost.WithLabelValues(p.name, p.zone, operation).Add(cost)
+	}
+}
+
+// Records implements externaldnsprovider.Provider
+func (p *instrumentedProvider) Records(ctx context.Context) ([]*externaldnsendpoint.Endpoint, error) {
+	p.count("Records")
+	endpoints, err := p.Provider.Records(ctx)
+	providerListHealth.observe(ctx, p.name, p.secret, err)
+	return endpoints, err
+}
+
+// ApplyChanges implements externaldnsprovider.Provider
+func (p *instrumentedProvider) ApplyChanges(ctx context.Context, changes *externaldnsplan.Changes) error {
+	p.count("ApplyChanges")
+	return p.Provider.ApplyChanges(ctx, changes)
+}
+
+func (p *instrumentedProvider) DNSZones(ctx context.Context) ([]DNSZone, error) {
+	p.count("DNSZones")
+	zones, err := p.Provider.DNSZones(ctx)
+	providerListHealth.observe(ctx, p.name, p.secret, err)
+	return zones, err
+}
+
+func (p *instrumentedProvider) DNSZoneForHost(ctx context.Context, host string) (*DNSZone, error) {
+	p.count("DNSZoneForHost")
+	return p.Provider.DNSZoneForHost(ctx, host)
+}
+
+func (p *instrumentedProvider) HealthCheckReconciler() HealthCheckReconciler {
+	return &instrumentedHealthCheckReconciler{HealthCheckReconciler: p.Provider.HealthCheckReconciler(), provider: p}
+}
+
+// instrumentedHealthCheckReconciler is a HealthCheckReconciler that counts requests made to the provider API for
+// health checks against the provider it belongs to
+type instrumentedHealthCheckReconciler struct {
+	HealthCheckReconciler
+	provider *instrumentedProvider
+}
+
+func (r *instrumentedHealthCheckReconciler) Reconcile(ctx context.Context, spec HealthCheckSpec, endpoint *externaldnsendpoint.Endpoint, probeStatus *v1alpha1.HealthCheckStatusProbe, address string) HealthCheckResult {
+	r.provider.count("ReconcileHealthCheck")
+	return r.HealthCheckReconciler.Reconcile(ctx, spec, endpoint, probeStatus, address)
+}
+
+func (r *instrumentedHealthCheckReconciler) Delete(ctx context.Context, endpoint *externaldnsendpoint.Endpoint, probeStatus *v1alpha1.HealthCheckStatusProbe) (HealthCheckResult, error) {
+	r.provider.count("DeleteHealthCheck")
+	return r.HealthCheckReconciler.Delete(ctx, endpoint, probeStatus)
+}
+
+func (r *instrumentedHealthCheckReconciler) HealthCheckExists(ctx context.Context, probeStatus *v1alpha1.HealthCheckStatusProbe) (bool, error) {
+	r.provider.count("HealthCheckExists")
+	return r.HealthCheckReconciler.HealthCheckExists(ctx, probeStatus)
+}
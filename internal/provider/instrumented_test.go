@@ -0,0 +1,101 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+	externaldnsplan "sigs.k8s.io/external-dns/plan"
+
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+type recordsStubProvider struct {
+	Provider
+}
+
+func (p *recordsStubProvider) Records(_ context.Context) ([]*externaldnsendpoint.Endpoint, error) {
+	return nil, nil
+}
+
+func (p *recordsStubProvider) ApplyChanges(_ context.Context, _ *externaldnsplan.Changes) error {
+	return nil
+}
+
+func (p *recordsStubProvider) DNSZoneForHost(_ context.Context, _ string) (*DNSZone, error) {
+	return &DNSZone{}, nil
+}
+
+func (p *recordsStubProvider) HealthCheckReconciler() HealthCheckReconciler {
+	return &FakeHealthCheckReconciler{}
+}
+
+func TestInstrumentedProviderCountsRequests(t *testing.T) {
+	p := newInstrumentedProvider(&recordsStubProvider{}, "stub", client.ObjectKey{Namespace: "ns", Name: "stub-credentials"}, Config{
+		DomainFilter: externaldnsendpoint.NewDomainFilter([]string{"example.com"}),
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.Records(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := p.ApplyChanges(context.Background(), &externaldnsplan.Changes{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := testutil.ToFloat64(metrics.ProviderRequestCounter.WithLabelValues("stub", "example.com", "Records")); got != 3 {
+		t.Errorf("expected 3 Records requests got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.ProviderRequestCounter.WithLabelValues("stub", "example.com", "ApplyChanges")); got != 1 {
+		t.Errorf("expected 1 ApplyChanges request got %v", got)
+	}
+}
+
+func TestInstrumentedProviderEstimatesCosts(t *testing.T) {
+	ConfigureRequestCosts(map[string]map[string]float64{"costed": {"ApplyChanges": 0.25, "ReconcileHealthCheck": 0.5}})
+	defer ConfigureRequestCosts(nil)
+	p := newInstrumentedProvider(&recordsStubProvider{}, "costed", client.ObjectKey{Namespace: "ns", Name: "costed-credentials"}, Config{
+		DomainFilter: externaldnsendpoint.NewDomainFilter([]string{"example.com"}),
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := p.ApplyChanges(ctx, &externaldnsplan.Changes{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		p.HealthCheckReconciler().Reconcile(ctx, HealthCheckSpec{}, &externaldnsendpoint.Endpoint{}, nil, "127.0.0.1")
+	}
+	if _, err := p.DNSZoneForHost(ctx, "foo.example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.HealthCheckReconciler().HealthCheckExists(ctx, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []struct {
+		operation string
+		requests  float64
+		cost      float64
+	}{
+		{"ApplyChanges", 2, 0.5},
+		{"ReconcileHealthCheck", 2, 1},
+		{"DNSZoneForHost", 1, 0},
+		{"HealthCheckExists", 1, 0},
+	} {
+		if got := testutil.ToFloat64(metrics.ProviderRequestCounter.WithLabelValues("costed", "example.com", want.operation)); got != want.requests {
+			t.Errorf("expected %v %s requests got %v", want.requests, want.operation, got)
+		}
+		if got := testutil.ToFloat64(metrics.ProviderRequestCost.WithLabelValues("costed", "example.com", want.operation)); got != want.cost {
+			t.Errorf("expected %s cost %v got %v", want.operation, want.cost, got)
+		}
+	}
+	if got := testutil.ToFloat64(metrics.ProviderRequestCounter.WithLabelValues("costed", "example.com", "DNSZones")); got != 0 {
+		t.Errorf("expected DNSZoneForHost not to be counted as DNSZones got %v", got)
+	}
+}
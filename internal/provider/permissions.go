@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+	"errors"
+)
+
+// PermissionChecker is implemented by providers able to check, without writing, whether their credentials are allowed
+// to write the records of a zone
+type PermissionChecker interface {
+	// CheckWritePermission returns whether the credentials of the provider are allowed to write the records of the zone
+	// with the given ID, and the reason if they are not. An error is returned if the permissions could not be checked.
+	CheckWritePermission(ctx context.Context, zoneID string) (bool, string, error)
+}
+
+// ErrPermissionCheckUnsupported is returned by CheckWritePermission for providers unable to check their permissions
+var ErrPermissionCheckUnsupported = errors.New("the provider does not support checking permissions")
+
+// wrappedProvider is implemented by Providers wrapping another Provider, e.g. to instrument it
+type wrappedProvider interface {
+	Unwrap() Provider
+}
+
+// CheckWritePermission checks whether the credentials of the given Provider are allowed to write the records of the
+// zone with the given ID, as PermissionChecker, unwrapping the Provider to the one implementing it.
+// ErrPermissionCheckUnsupported is returned if no Provider it wraps implements PermissionChecker.
+func CheckWritePermission(ctx context.Context, p Provider, zoneID string) (bool, string, error) {
+	for {
+		if checker, ok := p.(PermissionChecker); ok {
+			return checker.CheckWritePermission(ctx, zoneID)
+		}
+		wrapped, ok := p.(wrappedProvider)
+		if !ok {
+			return false, "", ErrPermissionCheckUnsupported
+		}
+		p = wrapped.Unwrap()
+	}
+}
@@ -0,0 +1,33 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+type permissionCheckingProvider struct {
+	Provider
+}
+
+func (p *permissionCheckingProvider) CheckWritePermission(_ context.Context, _ string) (bool, string, error) {
+	return true, "", nil
+}
+
+func TestCheckWritePermission(t *testing.T) {
+	config := Config{DomainFilter: externaldnsendpoint.NewDomainFilter([]string{"example.com"})}
+	wrapped := newFrozenProvider(newInstrumentedProvider(&permissionCheckingProvider{}, "stub", client.ObjectKey{}, config), "example.com")
+	if allowed, _, err := CheckWritePermission(context.Background(), wrapped, "example.com"); err != nil || !allowed {
+		t.Errorf("CheckWritePermission() of wrapped provider = %v, %v", allowed, err)
+	}
+
+	unsupported := newInstrumentedProvider(&recordsStubProvider{}, "stub", client.ObjectKey{}, config)
+	if _, _, err := CheckWritePermission(context.Background(), unsupported, "example.com"); !errors.Is(err, ErrPermissionCheckUnsupported) {
+		t.Errorf("CheckWritePermission() of unsupported provider error = %v, want %v", err, ErrPermissionCheckUnsupported)
+	}
+}
@@ -46,6 +46,8 @@ type Config struct {
 	ZoneTypeFilter externaldnsprovider.ZoneTypeFilter
 	// only consider hosted zones ending with this zone id
 	ZoneIDFilter externaldnsprovider.ZoneIDFilter
+	// validate changes, as far as the provider allows, without applying them to the zone
+	DryRun bool
 }
 
 type ProviderSpecificLabels struct {
@@ -119,3 +121,35 @@ func findDNSZoneForHost(originalHost, host string, zones []DNSZone) (*DNSZone, s
 
 	return findDNSZoneForHost(originalHost, parentDomain, zones)
 }
+
+// IsZoneApex returns true if the dnsName of the given endpoint is the domain of one of the zones of the given domain filter
+func IsZoneApex(ep *externaldnsendpoint.Endpoint, domainFilter externaldnsendpoint.DomainFilter) bool {
+	dnsName := strings.TrimSuffix(ep.DNSName, ".")
+	for _, domain := range domainFilter.Filters {
+		if strings.EqualFold(dnsName, strings.TrimSuffix(domain, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderSpecificTranslations maps provider agnostic provider specific property names, e.g. v1alpha1.ProviderSpecificWeight,
+// to a function returning the provider namespaced property name for a given value of the property.
+type ProviderSpecificTranslations map[string]func(value string) string
+
+// TranslateProviderSpecific replaces the provider agnostic provider specific properties of the given endpoint with their
+// provider namespaced equivalents. Provider namespaced properties set directly on the endpoint are accepted as is and
+// take precedence over the provider agnostic property they would be translated from.
+func TranslateProviderSpecific(ep *externaldnsendpoint.Endpoint, translations ProviderSpecificTranslations) {
+	for name, translate := range translations {
+		value, ok := ep.GetProviderSpecificProperty(name)
+		if !ok {
+			continue
+		}
+		ep.DeleteProviderSpecificProperty(name)
+		translated := translate(value)
+		if _, ok := ep.GetProviderSpecificProperty(translated); !ok {
+			ep.WithProviderSpecific(translated, value)
+		}
+	}
+}
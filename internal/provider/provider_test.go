@@ -4,7 +4,10 @@ package provider
 
 import (
 	"errors"
+	"reflect"
 	"testing"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
 )
 
 func TestSanitizeError(t *testing.T) {
@@ -207,3 +210,49 @@ func Test_findDNSZoneForHost(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateProviderSpecific(t *testing.T) {
+	translations := ProviderSpecificTranslations{
+		"weight": func(_ string) string {
+			return "test/weight"
+		},
+	}
+	testCases := []struct {
+		name             string
+		providerSpecific externaldnsendpoint.ProviderSpecific
+		want             externaldnsendpoint.ProviderSpecific
+	}{
+		{
+			name:             "translates provider agnostic property",
+			providerSpecific: externaldnsendpoint.ProviderSpecific{{Name: "weight", Value: "120"}},
+			want:             externaldnsendpoint.ProviderSpecific{{Name: "test/weight", Value: "120"}},
+		},
+		{
+			name:             "accepts provider namespaced property",
+			providerSpecific: externaldnsendpoint.ProviderSpecific{{Name: "test/weight", Value: "120"}},
+			want:             externaldnsendpoint.ProviderSpecific{{Name: "test/weight", Value: "120"}},
+		},
+		{
+			name: "provider namespaced property takes precedence",
+			providerSpecific: externaldnsendpoint.ProviderSpecific{
+				{Name: "weight", Value: "120"},
+				{Name: "test/weight", Value: "200"},
+			},
+			want: externaldnsendpoint.ProviderSpecific{{Name: "test/weight", Value: "200"}},
+		},
+		{
+			name:             "ignores other properties",
+			providerSpecific: externaldnsendpoint.ProviderSpecific{{Name: "other", Value: "foo"}},
+			want:             externaldnsendpoint.ProviderSpecific{{Name: "other", Value: "foo"}},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := &externaldnsendpoint.Endpoint{DNSName: "foo.example.com", ProviderSpecific: tt.providerSpecific}
+			TranslateProviderSpecific(ep, translations)
+			if !reflect.DeepEqual(ep.ProviderSpecific, tt.want) {
+				t.Errorf("TranslateProviderSpecific() got = %v, want %v", ep.ProviderSpecific, tt.want)
+			}
+		})
+	}
+}
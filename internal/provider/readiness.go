@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+// listHealth tracks whether requests listing the zones and records with each provider secret are succeeding
+type listHealth struct {
+	mu sync.Mutex
+	// started is the time tracking started, standing in for the last successful list of secrets not yet used
+	started time.Time
+	// lastSuccess is the time of the last successful list of each provider secret
+	lastSuccess map[client.ObjectKey]time.Time
+	// failingSince is the time of the first failed list, since the last successful one, of each failing provider secret
+	failingSince map[client.ObjectKey]time.Time
+	// lastErr is the error of the last failed list of each failing provider secret
+	lastErr map[client.ObjectKey]error
+}
+
+func newListHealth() *listHealth {
+	return &listHealth{
+		started:      time.Now(),
+		lastSuccess:  map[client.ObjectKey]time.Time{},
+		failingSince: map[client.ObjectKey]time.Time{},
+		lastErr:      map[client.ObjectKey]error{},
+	}
+}
+
+// providerListHealth is the list health of all provider secrets used by provider factories
+var providerListHealth = newListHealth()
+
+// observe records the outcome of a request listing the zones or records of the named provider with the given provider
+// secret. Requests cancelled by their context are not counted, they say nothing of the provider.
+func (h *listHealth) observe(ctx context.Context, name string, secret client.ObjectKey, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		now := time.Now()
+		h.lastSuccess[secret] = now
+		delete(h.failingSince, secret)
+		delete(h.lastErr, secret)
+		metrics.ProviderSecretListFailing.WithLabelValues(name, secret.Namespace, secret.Name).Set(0)
+		metrics.ProviderSecretLastList.WithLabelValues(name, secret.Namespace, secret.Name).Set(float64(now.Unix()))
+		return
+	}
+	if _, ok := h.failingSince[secret]; !ok {
+		h.failingSince[secret] = time.Now()
+	}
+	h.lastErr[secret] = err
+	metrics.ProviderSecretListFailing.WithLabelValues(name, secret.Namespace, secret.Name).Set(1)
+}
+
+// check returns an error if any of the given provider secrets has not been used to successfully list zones or records
+// for longer than the given window
+func (h *listHealth) check(secrets []client.ObjectKey, window time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var errs []error
+	for _, secret := range secrets {
+		last, ok := h.lastSuccess[secret]
+		if !ok {
+			last = h.started
+		}
+		if time.Since(last) <= window {
+			continue
+		}
+		if since, failing := h.failingSince[secret]; failing {
+			errs = append(errs, fmt.Errorf("provider secret %s has failed to list zones and records since %s: %w",
+				secret, since.UTC().Format(time.RFC3339), SanitizeError(h.lastErr[secret])))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("provider secret %s has not listed zones and records successfully since %s",
+			secret, last.UTC().Format(time.RFC3339)))
+	}
+	return errors.Join(errs...)
+}
+
+// ReadyzCheck returns a readiness check failing while any of the given provider secrets, the credentials owned by the
+// operator, has not been used to successfully list zones or records within the given window, whether its lists fail or
+// it can no longer be used at all, e.g. once deleted. Provider secrets of other namespaces are not checked, their failures are not the
+// operator's. Registry ownership records are read with the records of the zone, so their reads are checked too.
+// Reconciles only use the secrets on the leader, and only as often as they happen, so a ReadinessProber should list
+// zones with them on every replica.
+func ReadyzCheck(secrets []client.ObjectKey, window time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		return providerListHealth.check(secrets, window)
+	}
+}
+
+// ReadinessProber lists the zones of each of the provider secrets checked by the readiness check every Interval, so the
+// readiness of the replica does not depend on reconciles happening to use them. Interval must be shorter than the
+// window of the readiness check.
+type ReadinessProber struct {
+	// Factory creates the providers of the secrets
+	Factory Factory
+	// Secrets are the provider secrets checked by the readiness check
+	Secrets []client.ObjectKey
+	// Interval is the time between lists of the zones of each secret
+	Interval time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = &ReadinessProber{}
+
+// NeedLeaderElection is false, the readiness of every replica is checked
+func (p *ReadinessProber) NeedLeaderElection() bool {
+	return false
+}
+
+// Start lists the zones of each secret, then again every Interval until the given context is done
+func (p *ReadinessProber) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		p.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe lists the zones of each secret. The outcome of each list is observed by the instrumented providers of the
+// Factory, secrets failing to create a provider, e.g. because they were deleted, are not listed so stop being ready once
+// the window of the readiness check has passed.
+func (p *ReadinessProber) probe(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("readiness_prober")
+	for _, secret := range p.Secrets {
+		accessor := &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Namespace: secret.Namespace},
+			Spec:       v1alpha1.DNSRecordSpec{ProviderRef: v1alpha1.ProviderRef{Name: secret.Name}},
+		}
+		dnsProvider, err := p.Factory.ProviderFor(ctx, accessor, Config{})
+		if err != nil {
+			logger.Error(err, "Failed to create the provider of the provider secret", "secret", secret)
+			continue
+		}
+		if _, err = dnsProvider.DNSZones(ctx); err != nil {
+			logger.Error(SanitizeError(err), "Failed to list the zones of the provider secret", "secret", secret)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/internal/metrics"
+)
+
+func TestListHealth(t *testing.T) {
+	h := newListHealth()
+	owned := client.ObjectKey{Namespace: "dns-operator-system", Name: "aws-credentials"}
+	other := client.ObjectKey{Namespace: "team-a", Name: "aws-credentials"}
+	secrets := []client.ObjectKey{owned}
+	ctx := context.Background()
+
+	if err := h.check(secrets, time.Minute); err != nil {
+		t.Errorf("check() of unused secret within the window error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := h.check(secrets, 0); err == nil {
+		t.Errorf("check() of secret unused past the window error = nil")
+	}
+
+	h.observe(ctx, "aws", owned, nil)
+	if err := h.check(secrets, time.Minute); err != nil {
+		t.Errorf("check() after successful list error = %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.ProviderSecretListFailing.WithLabelValues("aws", owned.Namespace, owned.Name)); got != 0 {
+		t.Errorf("expected list failing gauge 0 got %v", got)
+	}
+
+	h.observe(ctx, "aws", other, errors.New("access denied"))
+	if err := h.check(secrets, time.Minute); err != nil {
+		t.Errorf("check() with other secret failing error = %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.ProviderSecretListFailing.WithLabelValues("aws", other.Namespace, other.Name)); got != 1 {
+		t.Errorf("expected list failing gauge 1 for other secret got %v", got)
+	}
+
+	h.observe(ctx, "aws", owned, errors.New("connection refused"))
+	if err := h.check(secrets, time.Minute); err != nil {
+		t.Errorf("check() failing within the window error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := h.check(secrets, 0); err == nil {
+		t.Errorf("check() of failing secret past the window error = nil")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	h.observe(cancelled, "aws", other, context.Canceled)
+	if got := testutil.ToFloat64(metrics.ProviderSecretListFailing.WithLabelValues("aws", other.Namespace, other.Name)); got != 1 {
+		t.Errorf("expected cancelled request not to be counted got %v", got)
+	}
+
+	h.observe(ctx, "aws", owned, nil)
+	if err := h.check(secrets, time.Minute); err != nil {
+		t.Errorf("check() after recovered list error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := h.check(secrets, 0); err == nil {
+		t.Errorf("check() of secret not listed within the window error = nil")
+	}
+}
+
+type zonesStubProvider struct {
+	Provider
+	err error
+}
+
+func (p *zonesStubProvider) DNSZones(_ context.Context) ([]DNSZone, error) {
+	return nil, p.err
+}
+
+// zonesStubFactory creates instrumented zonesStubProviders, failing to list the zones of the secrets of failing
+type zonesStubFactory struct {
+	failing map[client.ObjectKey]bool
+}
+
+func (f *zonesStubFactory) ProviderFor(_ context.Context, pa v1alpha1.ProviderAccessor, c Config) (Provider, error) {
+	secret := client.ObjectKey{Namespace: pa.GetNamespace(), Name: pa.GetProviderRef().Name}
+	p := &zonesStubProvider{}
+	if f.failing[secret] {
+		p.err = errors.New("access denied")
+	}
+	return newInstrumentedProvider(p, "stub", secret, c), nil
+}
+
+func TestReadinessProberProbe(t *testing.T) {
+	ok := client.ObjectKey{Namespace: "dns-operator-system", Name: "probed-credentials"}
+	failing := client.ObjectKey{Namespace: "dns-operator-system", Name: "revoked-credentials"}
+	prober := &ReadinessProber{
+		Factory:  &zonesStubFactory{failing: map[client.ObjectKey]bool{failing: true}},
+		Secrets:  []client.ObjectKey{ok, failing},
+		Interval: time.Minute,
+	}
+
+	prober.probe(context.Background())
+	if got := testutil.ToFloat64(metrics.ProviderSecretLastList.WithLabelValues("stub", ok.Namespace, ok.Name)); got == 0 {
+		t.Errorf("probe() did not list the zones of %s", ok)
+	}
+	if got := testutil.ToFloat64(metrics.ProviderSecretListFailing.WithLabelValues("stub", failing.Namespace, failing.Name)); got != 1 {
+		t.Errorf("expected list failing gauge 1 for %s got %v", failing, got)
+	}
+	if prober.NeedLeaderElection() {
+		t.Errorf("NeedLeaderElection() = true, want every replica to probe")
+	}
+}
@@ -0,0 +1,290 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// vaultServiceAccountTokenPath is the path of the operator's ServiceAccount token, used to log in to Vault
+var vaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// vaultRequestTimeout is the time each request to Vault is given to complete
+const vaultRequestTimeout = 30 * time.Second
+
+var vaultHTTPClient = &http.Client{Timeout: vaultRequestTimeout}
+
+// VaultConfig is the Vault the operator fetches provider credentials from. Provider secrets only name the credentials
+// path, the operator's ServiceAccount token is only ever sent to the configured Vault, with the role bound to the
+// namespace of the secret.
+type VaultConfig struct {
+	// Address of the Vault server. Empty disables fetching credentials from Vault.
+	Address string
+	// AuthPath is the mount path of the Vault kubernetes auth method, defaults to "kubernetes"
+	AuthPath string
+	// Bindings are the Vault role, and credentials paths, each namespace may fetch credentials with, keyed by namespace.
+	// Provider secrets in namespaces without a binding can not fetch credentials from Vault.
+	Bindings map[string]VaultBinding
+}
+
+// VaultBinding is the Vault role the operator logs in with, and the credentials paths it reads, for the provider
+// secrets of a namespace
+type VaultBinding struct {
+	// Role is the Vault kubernetes auth role the operator logs in with
+	Role string
+	// PathPrefix is the path, e.g. "aws/creds/team-a", the credentials paths of provider secrets must be, or be within
+	PathPrefix string
+}
+
+// allows returns true if the given credentials path is the binding path prefix or within it
+func (b VaultBinding) allows(path string) bool {
+	prefix := strings.Trim(b.PathPrefix, "/")
+	if prefix == "" || slices.Contains(strings.Split(path, "/"), "..") {
+		return false
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+var vaultConfig VaultConfig
+
+// ConfigureVault sets the Vault provider credentials are fetched from
+func ConfigureVault(c VaultConfig) {
+	c.Address = strings.TrimSuffix(c.Address, "/")
+	c.AuthPath = strings.Trim(c.AuthPath, "/")
+	if c.AuthPath == "" {
+		c.AuthPath = "kubernetes"
+	}
+	vaultConfig = c
+}
+
+// vaultCredentials caches short-lived credentials fetched from Vault.
+// Credentials are fetched again once two thirds of their lease has elapsed, so they are renewed before they expire.
+// Vault tokens are reused, for all credentials fetched with the same role, until two thirds of their lease has elapsed
+// and are revoked once replaced.
+var vaultCredentials = &vaultCredentialsCache{
+	entries: map[vaultCredentialsKey]vaultCredentialsEntry{},
+	tokens:  map[vaultLoginKey]vaultTokenEntry{},
+}
+
+type vaultCredentialsCache struct {
+	sync.Mutex
+	entries map[vaultCredentialsKey]vaultCredentialsEntry
+	tokens  map[vaultLoginKey]vaultTokenEntry
+}
+
+// vaultCredentialsKey identifies cached credentials. The namespace of the provider secret is part of the key so
+// credentials are never shared between namespaces.
+type vaultCredentialsKey struct {
+	vaultLoginKey
+	namespace string
+	path      string
+}
+
+// vaultLoginKey identifies a Vault login
+type vaultLoginKey struct {
+	addr     string
+	authPath string
+	role     string
+}
+
+type vaultTokenEntry struct {
+	token   string
+	renewAt time.Time
+}
+
+type vaultCredentialsEntry struct {
+	data    map[string]string
+	renewAt time.Time
+}
+
+// vaultResponse is the subset of a Vault API response used
+type vaultResponse struct {
+	LeaseDuration int            `json:"lease_duration"`
+	Data          map[string]any `json:"data"`
+	Auth          *vaultAuth     `json:"auth"`
+	Errors        []string       `json:"errors"`
+}
+
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+}
+
+// resolveVaultCredentials returns a copy of the given provider secret with the short-lived credentials fetched from
+// the Vault path in its v1alpha1.VaultCredentialsPathKey. Secrets without the key are returned unchanged.
+// The role logged in with, and the paths that may be read, are those bound to the namespace of the secret, so a
+// tenant can never use the operator's identity to read credentials bound to another namespace.
+func resolveVaultCredentials(ctx context.Context, secret *v1.Secret) (*v1.Secret, error) {
+	path := strings.Trim(string(secret.Data[v1alpha1.VaultCredentialsPathKey]), "/")
+	if path == "" {
+		return secret, nil
+	}
+	for _, key := range []string{v1alpha1.VaultAddressKey, v1alpha1.VaultAuthPathKey} {
+		if _, ok := secret.Data[key]; ok {
+			return nil, fmt.Errorf("%s is not allowed in provider secrets, the Vault the operator logs in to is set by its --vault-addr and --vault-auth-path flags", key)
+		}
+	}
+	if vaultConfig.Address == "" {
+		return nil, fmt.Errorf("fetching credentials from Vault is not enabled, the operator must be started with --vault-addr")
+	}
+	binding, ok := vaultConfig.Bindings[secret.Namespace]
+	if !ok {
+		return nil, fmt.Errorf("fetching credentials from Vault is not allowed in namespace %s, it has no --vault-namespace-binding", secret.Namespace)
+	}
+	if role := string(secret.Data[v1alpha1.VaultRoleKey]); role != "" && role != binding.Role {
+		return nil, fmt.Errorf("%s %s is not the Vault role bound to namespace %s", v1alpha1.VaultRoleKey, role, secret.Namespace)
+	}
+	if !binding.allows(path) {
+		return nil, fmt.Errorf("%s %s is not within %s, the Vault path bound to namespace %s", v1alpha1.VaultCredentialsPathKey, path, binding.PathPrefix, secret.Namespace)
+	}
+
+	key := vaultCredentialsKey{
+		vaultLoginKey: vaultLoginKey{addr: vaultConfig.Address, authPath: vaultConfig.AuthPath, role: binding.Role},
+		namespace:     secret.Namespace,
+		path:          path,
+	}
+	data, err := vaultCredentials.get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch credentials for provider secret %s/%s from Vault: %w", secret.Namespace, secret.Name, err)
+	}
+
+	secret = secret.DeepCopy()
+	switch secret.Type {
+	case v1alpha1.SecretTypeKuadrantAWS:
+		secret.Data[v1alpha1.AWSAccessKeyIDKey] = []byte(data["access_key"])
+		secret.Data[v1alpha1.AWSSecretAccessKeyKey] = []byte(data["secret_key"])
+		delete(secret.Data, v1alpha1.AWSSessionTokenKey)
+		if token := data["security_token"]; token != "" {
+			secret.Data[v1alpha1.AWSSessionTokenKey] = []byte(token)
+		}
+	case v1alpha1.SecretTypeKuadrantGCP:
+		key, err := base64.StdEncoding.DecodeString(data["private_key_data"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid private_key_data from Vault: %w", err)
+		}
+		secret.Data[v1alpha1.GoogleJsonKey] = key
+	default:
+		return nil, fmt.Errorf("fetching credentials from Vault is not supported for %s provider secrets", secret.Type)
+	}
+	return secret, nil
+}
+
+// get returns the cached credentials of the given key, fetching them from Vault if they are due for renewal.
+// The cache is not locked while fetching, concurrent fetches of the same credentials are harmless.
+func (c *vaultCredentialsCache) get(ctx context.Context, key vaultCredentialsKey) (map[string]string, error) {
+	c.Lock()
+	entry, ok := c.entries[key]
+	c.Unlock()
+	if ok && time.Now().Before(entry.renewAt) {
+		return entry.data, nil
+	}
+
+	logger := log.FromContext(ctx).WithValues("vaultAddr", key.addr, "path", key.path)
+	logger.V(1).Info("fetching provider credentials from Vault")
+
+	token, err := c.token(ctx, key.vaultLoginKey)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := vaultRequest(ctx, http.MethodGet, key.addr+"/v1/"+key.path, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	for k, v := range creds.Data {
+		if s, ok := v.(string); ok {
+			data[k] = s
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.entries[key] = vaultCredentialsEntry{
+		data:    data,
+		renewAt: time.Now().Add(time.Duration(creds.LeaseDuration) * time.Second * 2 / 3),
+	}
+	return data, nil
+}
+
+// token returns the cached Vault token of the given login, logging in again once it is due for renewal.
+// The token replaced is revoked.
+func (c *vaultCredentialsCache) token(ctx context.Context, key vaultLoginKey) (string, error) {
+	c.Lock()
+	entry, ok := c.tokens[key]
+	c.Unlock()
+	if ok && time.Now().Before(entry.renewAt) {
+		return entry.token, nil
+	}
+
+	jwt, err := os.ReadFile(vaultServiceAccountTokenPath)
+	if err != nil {
+		return "", err
+	}
+	login, err := vaultRequest(ctx, http.MethodPost, key.addr+"/v1/auth/"+key.authPath+"/login", "", map[string]string{"role": key.role, "jwt": string(jwt)})
+	if err != nil {
+		return "", fmt.Errorf("login failed: %w", err)
+	}
+	if login.Auth == nil || login.Auth.ClientToken == "" {
+		return "", fmt.Errorf("login failed: no client token returned")
+	}
+
+	c.Lock()
+	c.tokens[key] = vaultTokenEntry{
+		token:   login.Auth.ClientToken,
+		renewAt: time.Now().Add(time.Duration(login.Auth.LeaseDuration) * time.Second * 2 / 3),
+	}
+	c.Unlock()
+
+	if ok {
+		if _, err = vaultRequest(ctx, http.MethodPost, key.addr+"/v1/auth/token/revoke-self", entry.token, nil); err != nil {
+			log.FromContext(ctx).V(1).Info("unable to revoke replaced Vault token", "error", err)
+		}
+	}
+	return login.Auth.ClientToken, nil
+}
+
+func vaultRequest(ctx context.Context, method, url, token string, body any) (*vaultResponse, error) {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, &reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := vaultHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	vaultResp := &vaultResponse{}
+	if resp.StatusCode == http.StatusNoContent {
+		return vaultResp, nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(vaultResp); err != nil {
+		return nil, fmt.Errorf("%s %s: unexpected response (%d): %w", method, url, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || len(vaultResp.Errors) > 0 {
+		return nil, fmt.Errorf("%s %s: %d %s", method, url, resp.StatusCode, strings.Join(vaultResp.Errors, ", "))
+	}
+	return vaultResp, nil
+}
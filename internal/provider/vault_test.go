@@ -0,0 +1,174 @@
+//go:build unit
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestResolveVaultCredentials(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("sa-jwt"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	vaultServiceAccountTokenPath = tokenPath
+
+	var credentialRequests, logins, revocations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			login := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&login)
+			if login["role"] != "dns" || login["jwt"] != "sa-jwt" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+		case "/v1/auth/token/revoke-self":
+			revocations++
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/aws/creds/dns":
+			credentialRequests++
+			if r.Header.Get("X-Vault-Token") != "vault-token" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"lease_duration":3600,"data":{"access_key":"id","secret_key":"secret","security_token":"token"}}`))
+		case "/v1/gcp/roleset/dns/key":
+			key := base64.StdEncoding.EncodeToString([]byte(`{"type":"service_account"}`))
+			_, _ = w.Write([]byte(`{"lease_duration":3600,"data":{"private_key_data":"` + key + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	newSecret := func(secretType v1.SecretType, path, role string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "test"},
+			Type:       secretType,
+			Data: map[string][]byte{
+				v1alpha1.VaultRoleKey:            []byte(role),
+				v1alpha1.VaultCredentialsPathKey: []byte(path),
+			},
+		}
+	}
+
+	if _, err := resolveVaultCredentials(context.Background(), newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns")); err == nil {
+		t.Errorf("resolveVaultCredentials() error = nil, want error while Vault is not configured")
+	}
+
+	defer ConfigureVault(VaultConfig{})
+	ConfigureVault(VaultConfig{Address: server.URL + "/", Bindings: map[string]VaultBinding{
+		"test":  {Role: "dns", PathPrefix: "aws/creds/dns"},
+		"other": {Role: "dns", PathPrefix: "aws/creds/dns"},
+		"gcp":   {Role: "dns", PathPrefix: "gcp/roleset/dns/"},
+	}})
+
+	secret, err := resolveVaultCredentials(context.Background(), newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns"))
+	if err != nil {
+		t.Fatalf("resolveVaultCredentials() error = %v", err)
+	}
+	if string(secret.Data[v1alpha1.AWSAccessKeyIDKey]) != "id" || string(secret.Data[v1alpha1.AWSSecretAccessKeyKey]) != "secret" ||
+		string(secret.Data[v1alpha1.AWSSessionTokenKey]) != "token" {
+		t.Errorf("resolveVaultCredentials() data = %v, want aws credentials from Vault", secret.Data)
+	}
+
+	if _, err = resolveVaultCredentials(context.Background(), newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns")); err != nil {
+		t.Fatalf("resolveVaultCredentials() error = %v", err)
+	}
+	if credentialRequests != 1 {
+		t.Errorf("resolveVaultCredentials() fetched credentials %d times, want cached credentials to be reused", credentialRequests)
+	}
+
+	// cached credentials are never shared between namespaces, the login token of the role is
+	otherNamespace := newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "")
+	otherNamespace.Namespace = "other"
+	if _, err = resolveVaultCredentials(context.Background(), otherNamespace); err != nil {
+		t.Fatalf("resolveVaultCredentials() error = %v", err)
+	}
+	if credentialRequests != 2 || logins != 1 {
+		t.Errorf("resolveVaultCredentials() fetched credentials %d times with %d logins, want credentials fetched per namespace with one login", credentialRequests, logins)
+	}
+
+	gcpSecret := newSecret(v1alpha1.SecretTypeKuadrantGCP, "gcp/roleset/dns/key", "dns")
+	gcpSecret.Namespace = "gcp"
+	secret, err = resolveVaultCredentials(context.Background(), gcpSecret)
+	if err != nil {
+		t.Fatalf("resolveVaultCredentials() error = %v", err)
+	}
+	if got := string(secret.Data[v1alpha1.GoogleJsonKey]); got != `{"type":"service_account"}` {
+		t.Errorf("resolveVaultCredentials() GOOGLE = %s, want decoded private key data", got)
+	}
+
+	// the role and paths are bound to the namespace of the secret by the operator
+	for _, tenant := range []*v1.Secret{
+		newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "other"),
+		newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/other", "dns"),
+		newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns-admin", "dns"),
+		newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns/../admin", "dns"),
+		newSecret(v1alpha1.SecretTypeKuadrantGCP, "gcp/roleset/dns/key", "dns"),
+	} {
+		if _, err = resolveVaultCredentials(context.Background(), tenant); err == nil {
+			t.Errorf("resolveVaultCredentials() error = nil, want error for role %s and path %s in namespace %s",
+				tenant.Data[v1alpha1.VaultRoleKey], tenant.Data[v1alpha1.VaultCredentialsPathKey], tenant.Namespace)
+		}
+	}
+	unbound := newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns")
+	unbound.Namespace = "unbound"
+	if _, err = resolveVaultCredentials(context.Background(), unbound); err == nil {
+		t.Errorf("resolveVaultCredentials() error = nil, want error for namespace without a binding")
+	}
+
+	// the Vault the operator's ServiceAccount token is sent to can not be set by a tenant
+	tenantVault := newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns")
+	tenantVault.Data[v1alpha1.VaultAddressKey] = []byte("https://vault.attacker.example")
+	if _, err = resolveVaultCredentials(context.Background(), tenantVault); err == nil {
+		t.Errorf("resolveVaultCredentials() error = nil, want error for secret setting %s", v1alpha1.VaultAddressKey)
+	}
+	tenantVault = newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns")
+	tenantVault.Data[v1alpha1.VaultAuthPathKey] = []byte("other")
+	if _, err = resolveVaultCredentials(context.Background(), tenantVault); err == nil {
+		t.Errorf("resolveVaultCredentials() error = nil, want error for secret setting %s", v1alpha1.VaultAuthPathKey)
+	}
+
+	// the token is replaced, and revoked, once due for renewal
+	vaultCredentials.Lock()
+	for key, entry := range vaultCredentials.tokens {
+		entry.renewAt = time.Now()
+		vaultCredentials.tokens[key] = entry
+	}
+	for key, entry := range vaultCredentials.entries {
+		entry.renewAt = time.Now()
+		vaultCredentials.entries[key] = entry
+	}
+	vaultCredentials.Unlock()
+	if _, err = resolveVaultCredentials(context.Background(), newSecret(v1alpha1.SecretTypeKuadrantAWS, "aws/creds/dns", "dns")); err != nil {
+		t.Fatalf("resolveVaultCredentials() error = %v", err)
+	}
+	if logins != 2 || revocations != 1 {
+		t.Errorf("resolveVaultCredentials() logged in %d times and revoked %d tokens, want the replaced token revoked", logins, revocations)
+	}
+
+	plain := &v1.Secret{Type: v1alpha1.SecretTypeKuadrantAWS, Data: map[string][]byte{v1alpha1.AWSAccessKeyIDKey: []byte("id")}}
+	if secret, err = resolveVaultCredentials(context.Background(), plain); err != nil || secret != plain {
+		t.Errorf("resolveVaultCredentials() = %v, %v, want secret without Vault path unchanged", secret, err)
+	}
+}
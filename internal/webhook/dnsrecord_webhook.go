@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/pkg/validation"
+)
+
+//+kubebuilder:webhook:path=/validate-kuadrant-io-v1alpha1-dnsrecord,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=dnsrecords,verbs=create;update,versions=v1alpha1,name=vdnsrecord.kuadrant.io,admissionReviewVersions=v1
+
+// DNSRecordValidator rejects DNSRecords at admission that the DNSRecord controller would otherwise only report as invalid
+// once reconciled, applying the same checks. Endpoints colliding with those of other DNSRecords with the same owner are
+// warned of, as the zone of a DNSRecord, in which the controller checks for collisions, is only known once reconciled.
+type DNSRecordValidator struct {
+	// Client reads the DNSRecords checked for colliding endpoints, collisions are not checked if nil
+	Client client.Reader
+	// ManagedRecordTypes are the record types the controller is configured to manage
+	ManagedRecordTypes []string
+}
+
+var _ webhook.CustomValidator = &DNSRecordValidator{}
+
+// SetupWithManager registers the validating webhook with the Manager.
+func (v *DNSRecordValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.DNSRecord{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *DNSRecordValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	dnsRecord, ok := obj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected a DNSRecord but got %T", obj)
+	}
+	if err := validation.Validate(dnsRecord, v.ManagedRecordTypes); err != nil {
+		return nil, err
+	}
+	return v.collisionWarnings(ctx, dnsRecord), nil
+}
+
+func (v *DNSRecordValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldRecord, ok := oldObj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected a DNSRecord but got %T", oldObj)
+	}
+	dnsRecord, ok := newObj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected a DNSRecord but got %T", newObj)
+	}
+	// never block removing the finalizer of a deleted record, or updates leaving the spec of records created before
+	// the webhook was enabled as it was
+	if dnsRecord.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldRecord.Spec, dnsRecord.Spec) {
+		return nil, nil
+	}
+	if err := validation.Validate(dnsRecord, v.ManagedRecordTypes); err != nil {
+		return nil, err
+	}
+	return v.collisionWarnings(ctx, dnsRecord), nil
+}
+
+func (v *DNSRecordValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// collisionWarnings returns a warning for each DNSRecord with the same owner as the given DNSRecord, and created before
+// it, that defines one of its endpoints with different targets. The controller refuses to publish the given DNSRecord
+// if it is in the same zone as such a DNSRecord. DNSRecords without an owner ID are owned by the hash of their UID, so
+// never collide.
+func (v *DNSRecordValidator) collisionWarnings(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) admission.Warnings {
+	ownerID := recordOwnerID(dnsRecord)
+	if v.Client == nil || ownerID == "" {
+		return nil
+	}
+	records := &v1alpha1.DNSRecordList{}
+	if err := v.Client.List(ctx, records); err != nil {
+		return admission.Warnings{fmt.Sprintf("endpoints were not checked for collisions with other DNSRecords: %v", err)}
+	}
+	var warnings admission.Warnings
+	for i := range records.Items {
+		other := &records.Items[i]
+		if client.ObjectKeyFromObject(other) == client.ObjectKeyFromObject(dnsRecord) || other.DeletionTimestamp != nil ||
+			recordOwnerID(other) != ownerID {
+			continue
+		}
+		// newer records are refused publishing on colliding with the given DNSRecord, not the other way around
+		if !dnsRecord.CreationTimestamp.IsZero() && dnsRecord.CreationTimestamp.Before(&other.CreationTimestamp) {
+			continue
+		}
+		if err := validation.EndpointCollision(dnsRecord, other, ownerID); err != nil {
+			warnings = append(warnings, err.Error()+"; this DNSRecord is not published while both are in the same zone")
+		}
+	}
+	return warnings
+}
+
+// recordOwnerID returns the owner ID of the given DNSRecord, or of its spec if not yet reconciled
+func recordOwnerID(dnsRecord *v1alpha1.DNSRecord) string {
+	if dnsRecord.Status.OwnerID != "" {
+		return dnsRecord.Status.OwnerID
+	}
+	return dnsRecord.Spec.OwnerID
+}
@@ -0,0 +1,127 @@
+//go:build unit
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestDNSRecordValidator(t *testing.T) {
+	validRecord := func() *v1alpha1.DNSRecord {
+		return &v1alpha1.DNSRecord{
+			Spec: v1alpha1.DNSRecordSpec{
+				RootHost:  "example.com",
+				Endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb.example.org")},
+			},
+		}
+	}
+	invalidRecord := func() *v1alpha1.DNSRecord {
+		dnsRecord := validRecord()
+		dnsRecord.Spec.Endpoints[0].Targets = endpoint.Targets{"lb"}
+		return dnsRecord
+	}
+	deletedRecord := func() *v1alpha1.DNSRecord {
+		dnsRecord := invalidRecord()
+		dnsRecord.DeletionTimestamp = &metav1.Time{}
+		return dnsRecord
+	}
+
+	tests := []struct {
+		name    string
+		old     *v1alpha1.DNSRecord
+		new     *v1alpha1.DNSRecord
+		wantErr bool
+	}{
+		{
+			name: "create valid record",
+			new:  validRecord(),
+		},
+		{
+			name:    "create invalid record",
+			new:     invalidRecord(),
+			wantErr: true,
+		},
+		{
+			name: "update valid record",
+			old:  validRecord(),
+			new:  validRecord(),
+		},
+		{
+			name:    "update to invalid spec",
+			old:     validRecord(),
+			new:     invalidRecord(),
+			wantErr: true,
+		},
+		{
+			name: "update leaving invalid spec unchanged",
+			old:  invalidRecord(),
+			new:  invalidRecord(),
+		},
+		{
+			name: "update deleted record",
+			old:  validRecord(),
+			new:  deletedRecord(),
+		},
+	}
+
+	validator := &DNSRecordValidator{ManagedRecordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.old == nil {
+				_, err = validator.ValidateCreate(context.Background(), tt.new)
+			} else {
+				_, err = validator.ValidateUpdate(context.Background(), tt.old, tt.new)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDNSRecordValidatorCollisionWarnings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	record := func(name, ownerID, target string) *v1alpha1.DNSRecord {
+		return &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1alpha1.DNSRecordSpec{
+				OwnerID:   ownerID,
+				RootHost:  "example.com",
+				Endpoints: []*endpoint.Endpoint{endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, target)},
+			},
+		}
+	}
+	validator := &DNSRecordValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			record("same-targets", "owner", "127.0.0.1"),
+			record("other-targets", "owner", "127.0.0.2"),
+			record("other-owner", "other", "127.0.0.3"),
+		).Build(),
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	warnings, err := validator.ValidateCreate(context.Background(), record("new", "owner", "127.0.0.1"))
+	if err != nil {
+		t.Fatalf("ValidateCreate() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "default/other-targets") {
+		t.Errorf("ValidateCreate() warnings = %v, want a collision with default/other-targets", warnings)
+	}
+
+	if warnings, err = validator.ValidateCreate(context.Background(), record("new", "", "127.0.0.1")); err != nil || len(warnings) != 0 {
+		t.Errorf("ValidateCreate() without owner ID warnings = %v, error = %v, want none", warnings, err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// DefaultTTL is the TTL of endpoints added by the DNSRecordBuilder
+const DefaultTTL = 60
+
+// DNSRecordBuilder builds a DNSRecord.
+type DNSRecordBuilder struct {
+	name        string
+	namespace   string
+	rootHost    string
+	providerRef string
+	ownerID     string
+	endpoints   []*externaldnsendpoint.Endpoint
+	healthCheck *v1alpha1.HealthCheckSpec
+}
+
+// NewDNSRecordBuilder returns a new DNSRecord builder with the name and namespace provided
+func NewDNSRecordBuilder(name, namespace string) *DNSRecordBuilder {
+	return &DNSRecordBuilder{name: name, namespace: namespace}
+}
+
+// For defines the root host of the DNSRecord.
+// Defaults to the dnsName of the first endpoint.
+func (rb *DNSRecordBuilder) For(rootHost string) *DNSRecordBuilder {
+	rb.rootHost = rootHost
+	return rb
+}
+
+// WithProviderSecret sets the name of the provider secret the DNSRecord references
+func (rb *DNSRecordBuilder) WithProviderSecret(name string) *DNSRecordBuilder {
+	rb.providerRef = name
+	return rb
+}
+
+// WithOwnerID sets the owner ID of the DNSRecord.
+// Defaults to unset, the record UID being used.
+func (rb *DNSRecordBuilder) WithOwnerID(ownerID string) *DNSRecordBuilder {
+	rb.ownerID = ownerID
+	return rb
+}
+
+// WithHealthCheck sets the health check of the DNSRecord
+func (rb *DNSRecordBuilder) WithHealthCheck(healthCheck *v1alpha1.HealthCheckSpec) *DNSRecordBuilder {
+	rb.healthCheck = healthCheck
+	return rb
+}
+
+// WithEndpoints adds the given endpoints to the DNSRecord
+func (rb *DNSRecordBuilder) WithEndpoints(endpoints ...*externaldnsendpoint.Endpoint) *DNSRecordBuilder {
+	rb.endpoints = append(rb.endpoints, endpoints...)
+	return rb
+}
+
+// WithEndpoint adds an endpoint with the given dnsName, record type and targets, and the default TTL, to the DNSRecord
+func (rb *DNSRecordBuilder) WithEndpoint(dnsName, recordType string, targets ...string) *DNSRecordBuilder {
+	return rb.WithEndpoints(externaldnsendpoint.NewEndpointWithTTL(dnsName, recordType, DefaultTTL, targets...))
+}
+
+// WithWeightedEndpoint adds a CNAME endpoint with the given dnsName, set identifier and weight, targeting the given host
+func (rb *DNSRecordBuilder) WithWeightedEndpoint(dnsName, setIdentifier string, weight int, target string) *DNSRecordBuilder {
+	return rb.WithEndpoints(externaldnsendpoint.NewEndpointWithTTL(dnsName, externaldnsendpoint.RecordTypeCNAME, DefaultTTL, target).
+		WithSetIdentifier(setIdentifier).
+		WithProviderSpecific(v1alpha1.ProviderSpecificWeight, strconv.Itoa(weight)))
+}
+
+// WithGeoEndpoint adds a CNAME endpoint with the given dnsName, set identifier and geo code, targeting the given host
+func (rb *DNSRecordBuilder) WithGeoEndpoint(dnsName, setIdentifier, geoCode, target string) *DNSRecordBuilder {
+	return rb.WithEndpoints(externaldnsendpoint.NewEndpointWithTTL(dnsName, externaldnsendpoint.RecordTypeCNAME, DefaultTTL, target).
+		WithSetIdentifier(setIdentifier).
+		WithProviderSpecific(v1alpha1.ProviderSpecificGeoCode, geoCode))
+}
+
+// Build builds and returns the DNSRecord.
+func (rb *DNSRecordBuilder) Build() *v1alpha1.DNSRecord {
+	rootHost := rb.rootHost
+	if rootHost == "" && len(rb.endpoints) > 0 {
+		rootHost = rb.endpoints[0].DNSName
+	}
+	return &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rb.name,
+			Namespace: rb.namespace,
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			OwnerID:  rb.ownerID,
+			RootHost: rootHost,
+			ProviderRef: v1alpha1.ProviderRef{
+				Name: rb.providerRef,
+			},
+			Endpoints:   rb.endpoints,
+			HealthCheck: rb.healthCheck,
+		},
+	}
+}
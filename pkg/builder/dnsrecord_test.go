@@ -0,0 +1,39 @@
+//go:build unit
+
+package builder
+
+import (
+	"testing"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+func TestDNSRecordBuilder(t *testing.T) {
+	dnsRecord := NewDNSRecordBuilder("foo", "test").
+		WithProviderSecret("dns-provider-creds").
+		WithEndpoint("foo.example.com", externaldnsendpoint.RecordTypeCNAME, "klb.foo.example.com").
+		WithGeoEndpoint("klb.foo.example.com", "GEO-EU", "GEO-EU", "eu.klb.foo.example.com").
+		WithWeightedEndpoint("eu.klb.foo.example.com", "lb1.example.org", 120, "lb1.example.org").
+		WithEndpoint("lb1.example.org", externaldnsendpoint.RecordTypeA, "127.0.0.1").
+		Build()
+
+	if dnsRecord.Spec.RootHost != "foo.example.com" {
+		t.Errorf("Build() rootHost = %s, want foo.example.com", dnsRecord.Spec.RootHost)
+	}
+	if dnsRecord.Spec.ProviderRef.Name != "dns-provider-creds" {
+		t.Errorf("Build() providerRef = %s, want dns-provider-creds", dnsRecord.Spec.ProviderRef.Name)
+	}
+	if weight, _ := dnsRecord.Spec.Endpoints[2].GetProviderSpecificProperty(v1alpha1.ProviderSpecificWeight); weight != "120" {
+		t.Errorf("Build() weight = %s, want 120", weight)
+	}
+	if err := dnsRecord.Validate(); err == nil {
+		t.Errorf("Validate() expected error for endpoint outside the root host")
+	}
+
+	dnsRecord.Spec.Endpoints = dnsRecord.Spec.Endpoints[:3]
+	if err := dnsRecord.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
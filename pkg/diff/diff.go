@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff compares sets of endpoints, e.g. the desired endpoints of a DNSRecord against its current
+// spec.endpoints, so controllers and tooling share one set comparison.
+package diff
+
+import (
+	"cmp"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+// Changes are the differences between a current and desired set of endpoints. Endpoints are matched by their key,
+// i.e. dnsName, record type and set identifier.
+type Changes struct {
+	// Create are the desired endpoints with no current endpoint of the same key
+	Create []*externaldnsendpoint.Endpoint
+	// Update are the endpoints whose current and desired endpoint differ
+	Update []Update
+	// Delete are the current endpoints with no desired endpoint of the same key
+	Delete []*externaldnsendpoint.Endpoint
+}
+
+// Update is an endpoint whose current and desired endpoint differ, with what differs between them
+type Update struct {
+	Current *externaldnsendpoint.Endpoint
+	Desired *externaldnsendpoint.Endpoint
+
+	TargetsChanged          bool
+	TTLChanged              bool
+	ProviderSpecificChanged bool
+	LabelsChanged           bool
+}
+
+// OnlyTTLChanged returns true if the TTL is the only difference of the endpoint
+func (u Update) OnlyTTLChanged() bool {
+	return u.TTLChanged && !u.TargetsChanged && !u.ProviderSpecificChanged && !u.LabelsChanged
+}
+
+// OnlyProviderSpecificChanged returns true if provider specific properties are the only difference of the endpoint
+func (u Update) OnlyProviderSpecificChanged() bool {
+	return u.ProviderSpecificChanged && !u.TargetsChanged && !u.TTLChanged && !u.LabelsChanged
+}
+
+// HasChanges returns true if there is any change
+func (c Changes) HasChanges() bool {
+	return len(c.Create) > 0 || len(c.Update) > 0 || len(c.Delete) > 0
+}
+
+// Endpoints returns the changes that turn the current endpoints into the desired endpoints.
+// Targets are compared regardless of order, as are provider specific properties.
+func Endpoints(current, desired []*externaldnsendpoint.Endpoint) Changes {
+	changes := Changes{}
+	currentByKey := make(map[externaldnsendpoint.EndpointKey]*externaldnsendpoint.Endpoint, len(current))
+	for _, ep := range current {
+		currentByKey[ep.Key()] = ep
+	}
+
+	desiredKeys := make(map[externaldnsendpoint.EndpointKey]bool, len(desired))
+	for _, ep := range desired {
+		desiredKeys[ep.Key()] = true
+		currentEp, ok := currentByKey[ep.Key()]
+		if !ok {
+			changes.Create = append(changes.Create, ep)
+			continue
+		}
+		update := Update{
+			Current:                 currentEp,
+			Desired:                 ep,
+			TargetsChanged:          !currentEp.Targets.Same(ep.Targets),
+			TTLChanged:              currentEp.RecordTTL != ep.RecordTTL,
+			ProviderSpecificChanged: !sameProviderSpecific(currentEp.ProviderSpecific, ep.ProviderSpecific),
+			LabelsChanged:           !equality.Semantic.DeepEqual(currentEp.Labels, ep.Labels),
+		}
+		if update.TargetsChanged || update.TTLChanged || update.ProviderSpecificChanged || update.LabelsChanged {
+			changes.Update = append(changes.Update, update)
+		}
+	}
+
+	for _, ep := range current {
+		if !desiredKeys[ep.Key()] {
+			changes.Delete = append(changes.Delete, ep)
+		}
+	}
+	return changes
+}
+
+// sameProviderSpecific returns true if both have the same provider specific properties, regardless of order
+func sameProviderSpecific(a, b externaldnsendpoint.ProviderSpecific) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	compare := func(x, y externaldnsendpoint.ProviderSpecificProperty) int {
+		if c := cmp.Compare(x.Name, y.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Value, y.Value)
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.SortFunc(a, compare)
+	slices.SortFunc(b, compare)
+	return slices.Equal(a, b)
+}
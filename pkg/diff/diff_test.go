@@ -0,0 +1,61 @@
+//go:build unit
+
+package diff
+
+import (
+	"testing"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestEndpoints(t *testing.T) {
+	current := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpointWithTTL("same.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1", "127.0.0.2"),
+		externaldnsendpoint.NewEndpointWithTTL("ttl.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("weight.example.com", externaldnsendpoint.RecordTypeCNAME, 60, "lb.example.org").
+			WithSetIdentifier("a").WithProviderSpecific("weight", "100").WithProviderSpecific("geo-code", "IE"),
+		externaldnsendpoint.NewEndpointWithTTL("targets.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("deleted.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.1"),
+	}
+	desired := []*externaldnsendpoint.Endpoint{
+		externaldnsendpoint.NewEndpointWithTTL("same.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.2", "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("ttl.example.com", externaldnsendpoint.RecordTypeA, 300, "127.0.0.1"),
+		externaldnsendpoint.NewEndpointWithTTL("weight.example.com", externaldnsendpoint.RecordTypeCNAME, 60, "lb.example.org").
+			WithSetIdentifier("a").WithProviderSpecific("geo-code", "IE").WithProviderSpecific("weight", "200"),
+		externaldnsendpoint.NewEndpointWithTTL("targets.example.com", externaldnsendpoint.RecordTypeA, 60, "127.0.0.3"),
+		externaldnsendpoint.NewEndpointWithTTL("weight.example.com", externaldnsendpoint.RecordTypeCNAME, 60, "lb.example.net").
+			WithSetIdentifier("b").WithProviderSpecific("weight", "100"),
+	}
+
+	changes := Endpoints(current, desired)
+	if !changes.HasChanges() {
+		t.Fatal("HasChanges() = false, want true")
+	}
+	if len(changes.Create) != 1 || changes.Create[0].SetIdentifier != "b" {
+		t.Errorf("Create = %v, want the weight.example.com endpoint with set identifier b", changes.Create)
+	}
+	if len(changes.Delete) != 1 || changes.Delete[0].DNSName != "deleted.example.com" {
+		t.Errorf("Delete = %v, want deleted.example.com", changes.Delete)
+	}
+
+	updates := map[string]Update{}
+	for _, u := range changes.Update {
+		updates[u.Desired.DNSName] = u
+	}
+	if len(updates) != 3 {
+		t.Fatalf("Update = %v, want ttl, weight and targets endpoints", changes.Update)
+	}
+	if !updates["ttl.example.com"].OnlyTTLChanged() {
+		t.Errorf("ttl.example.com update = %+v, want only TTL changed", updates["ttl.example.com"])
+	}
+	if !updates["weight.example.com"].OnlyProviderSpecificChanged() {
+		t.Errorf("weight.example.com update = %+v, want only provider specific changed", updates["weight.example.com"])
+	}
+	if u := updates["targets.example.com"]; !u.TargetsChanged || u.TTLChanged || u.ProviderSpecificChanged {
+		t.Errorf("targets.example.com update = %+v, want only targets changed", u)
+	}
+
+	if changes := Endpoints(current, current); changes.HasChanges() {
+		t.Errorf("Endpoints() of the same endpoints = %+v, want no changes", changes)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation validates DNSRecords without a cluster, applying the same checks as the DNSRecord controller.
+// It allows DNSRecord manifests to be linted, e.g. in CI pipelines, before they are applied.
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+// Validate returns an error if the given DNSRecord would be rejected at admission, or fail validation in the DNSRecord
+// controller. managedRecordTypes are the record types the controller is configured to manage.
+func Validate(dnsRecord *v1alpha1.DNSRecord, managedRecordTypes []string) error {
+	if err := dnsRecord.ValidateAdmission(); err != nil {
+		return err
+	}
+	return ValidateRecordTypes(dnsRecord, managedRecordTypes)
+}
+
+// ValidateRecordTypes ensures all endpoints of the given DNSRecord are of one of the managedRecordTypes, and that none
+// use the prefix of the registry TXT records of a managed record type, e.g. kuadrant-a-, as their dnsName
+func ValidateRecordTypes(dnsRecord *v1alpha1.DNSRecord, managedRecordTypes []string) error {
+	for _, ep := range dnsRecord.Spec.Endpoints {
+		if !slices.Contains(managedRecordTypes, ep.RecordType) {
+			return fmt.Errorf("invalid endpoint discovered %s record type %s is not one of the managed record types %v", ep.DNSName, ep.RecordType, managedRecordTypes)
+		}
+		for _, recordType := range managedRecordTypes {
+			if reserved := v1alpha1.RegistryTXTPrefix + strings.ToLower(recordType) + "-"; strings.HasPrefix(ep.DNSName, reserved) {
+				return fmt.Errorf("invalid endpoint discovered %s, the %s prefix is reserved for registry TXT records", ep.DNSName, reserved)
+			}
+		}
+	}
+	return nil
+}
+
+// EndpointCollision returns an error if the given DNSRecord defines an endpoint with the same dnsName and
+// setIdentifier as an endpoint of other, but with different targets. Records sharing the owner ownerID in a zone would
+// both be considered the owner of the record set, and overwrite each other's changes in the provider.
+func EndpointCollision(dnsRecord, other *v1alpha1.DNSRecord, ownerID string) error {
+	for _, ep := range dnsRecord.Spec.Endpoints {
+		for _, otherEp := range other.Spec.Endpoints {
+			if ep.DNSName == otherEp.DNSName && ep.SetIdentifier == otherEp.SetIdentifier && !ep.Targets.Same(otherEp.Targets) {
+				return fmt.Errorf("endpoint %s with setIdentifier '%s' collides with DNSRecord %s/%s which has the same owner '%s' and different targets",
+					ep.DNSName, ep.SetIdentifier, other.Namespace, other.Name, ownerID)
+			}
+		}
+	}
+	return nil
+}
+
+// Decode returns the DNSRecords in the given stream of YAML or JSON documents. Documents of any other kind are ignored.
+// Fields unknown to the DNSRecord API are reported as errors.
+func Decode(r io.Reader) ([]*v1alpha1.DNSRecord, error) {
+	var dnsRecords []*v1alpha1.DNSRecord
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return dnsRecords, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		typeMeta := metav1.TypeMeta{}
+		if err = yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, err
+		}
+		if typeMeta.GroupVersionKind() != v1alpha1.GroupVersion.WithKind("DNSRecord") {
+			continue
+		}
+
+		dnsRecord := &v1alpha1.DNSRecord{}
+		if err = yaml.UnmarshalStrict(doc, dnsRecord); err != nil {
+			return nil, fmt.Errorf("invalid DNSRecord: %w", err)
+		}
+		dnsRecords = append(dnsRecords, dnsRecord)
+	}
+}
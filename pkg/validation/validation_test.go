@@ -0,0 +1,145 @@
+//go:build unit
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+)
+
+const manifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-record
+---
+apiVersion: kuadrant.io/v1alpha1
+kind: DNSRecord
+metadata:
+  name: valid
+  namespace: test
+spec:
+  rootHost: foo.example.com
+  providerRef:
+    name: dns-provider-creds
+  endpoints:
+    - dnsName: foo.example.com
+      recordTTL: 60
+      recordType: A
+      targets:
+        - 127.0.0.1
+---
+apiVersion: kuadrant.io/v1alpha1
+kind: DNSRecord
+metadata:
+  name: mx
+  namespace: test
+spec:
+  rootHost: foo.example.com
+  providerRef:
+    name: dns-provider-creds
+  endpoints:
+    - dnsName: foo.example.com
+      recordTTL: 60
+      recordType: MX
+      targets:
+        - 10 mail.example.com
+`
+
+func TestDecodeAndValidate(t *testing.T) {
+	dnsRecords, err := Decode(strings.NewReader(manifests))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(dnsRecords) != 2 {
+		t.Fatalf("Decode() returned %d records, want 2", len(dnsRecords))
+	}
+
+	managedRecordTypes := []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}
+	if err = Validate(dnsRecords[0], managedRecordTypes); err != nil {
+		t.Errorf("Validate(%s) error = %v, want nil", dnsRecords[0].Name, err)
+	}
+	if err = Validate(dnsRecords[1], managedRecordTypes); err == nil {
+		t.Errorf("Validate(%s) error = nil, want unmanaged record type error", dnsRecords[1].Name)
+	}
+	if err = Validate(dnsRecords[1], append(managedRecordTypes, endpoint.RecordTypeMX)); err != nil {
+		t.Errorf("Validate(%s) error = %v, want nil", dnsRecords[1].Name, err)
+	}
+}
+
+func TestValidateRecordTypes(t *testing.T) {
+	tests := []struct {
+		name               string
+		dnsName            string
+		recordType         string
+		managedRecordTypes []string
+		wantErr            bool
+	}{
+		{
+			name:               "managed record type",
+			dnsName:            "foo.example.com",
+			recordType:         endpoint.RecordTypeA,
+			managedRecordTypes: []string{endpoint.RecordTypeA},
+		},
+		{
+			name:               "unmanaged record type",
+			dnsName:            "foo.example.com",
+			recordType:         endpoint.RecordTypeMX,
+			managedRecordTypes: []string{endpoint.RecordTypeA},
+			wantErr:            true,
+		},
+		{
+			name:               "reserved registry prefix",
+			dnsName:            "kuadrant-a-foo.example.com",
+			recordType:         endpoint.RecordTypeA,
+			managedRecordTypes: []string{endpoint.RecordTypeA},
+			wantErr:            true,
+		},
+		{
+			name:               "reserved registry prefix of another managed record type",
+			dnsName:            "kuadrant-mx-foo.example.com",
+			recordType:         endpoint.RecordTypeA,
+			managedRecordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeMX},
+			wantErr:            true,
+		},
+		{
+			name:               "registry prefix of an unmanaged record type",
+			dnsName:            "kuadrant-cname-foo.example.com",
+			recordType:         endpoint.RecordTypeA,
+			managedRecordTypes: []string{endpoint.RecordTypeA},
+		},
+		{
+			name:               "registry prefix without record type",
+			dnsName:            "kuadrant-foo.example.com",
+			recordType:         endpoint.RecordTypeA,
+			managedRecordTypes: []string{endpoint.RecordTypeA},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dnsRecord := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{Endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint(tt.dnsName, tt.recordType, "127.0.0.1"),
+			}}}
+			if err := ValidateRecordTypes(dnsRecord, tt.managedRecordTypes); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRecordTypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeUnknownField(t *testing.T) {
+	manifest := `apiVersion: kuadrant.io/v1alpha1
+kind: DNSRecord
+metadata:
+  name: typo
+spec:
+  rootHost: foo.example.com
+  endpoint: []
+`
+	if _, err := Decode(strings.NewReader(manifest)); err == nil {
+		t.Errorf("Decode() error = nil, want unknown field error")
+	}
+}
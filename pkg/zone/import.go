@@ -0,0 +1,148 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package zone relates the records of existing DNS zones, read from zone files or providers, to DNSRecords, so
+// existing zones can be moved under the management of the operator.
+package zone
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/pkg/builder"
+)
+
+// Records returns the endpoints of the zone with the given domain name that DNSRecords can manage, i.e. endpoints in
+// the zone of one of the managedRecordTypes. NS records of the zone apex, and the registry TXT records of the
+// operator, are excluded.
+func Records(zoneDomainName string, endpoints []*externaldnsendpoint.Endpoint, managedRecordTypes []string) []*externaldnsendpoint.Endpoint {
+	zoneDomainName = strings.TrimSuffix(strings.ToLower(zoneDomainName), ".")
+	var records []*externaldnsendpoint.Endpoint
+	for _, ep := range endpoints {
+		dnsName := strings.ToLower(ep.DNSName)
+		switch {
+		case dnsName != zoneDomainName && !strings.HasSuffix(dnsName, "."+zoneDomainName):
+		case !slices.Contains(managedRecordTypes, ep.RecordType):
+		case ep.RecordType == externaldnsendpoint.RecordTypeNS && dnsName == zoneDomainName:
+		case ep.RecordType == externaldnsendpoint.RecordTypeTXT && registryRecord(dnsName, managedRecordTypes):
+		default:
+			records = append(records, ep)
+		}
+	}
+	return records
+}
+
+// registryRecord returns true if the given name has the prefix of the registry TXT records of a managed record type
+func registryRecord(dnsName string, managedRecordTypes []string) bool {
+	return slices.ContainsFunc(managedRecordTypes, func(recordType string) bool {
+		return strings.HasPrefix(dnsName, v1alpha1.RegistryTXTPrefix+strings.ToLower(recordType)+"-")
+	})
+}
+
+// DNSRecords partitions the given endpoints of the zone with the given domain name by sub host into DNSRecords in the
+// given namespace, referencing the given provider secret. Each name is added to the DNSRecord of a parent name below
+// the zone apex, if there is one, or is the root host of a DNSRecord of its own. The DNSRecords are annotated to adopt
+// the existing records once applied.
+func DNSRecords(zoneDomainName, namespace, providerRef string, endpoints []*externaldnsendpoint.Endpoint) []*v1alpha1.DNSRecord {
+	zoneDomainName = strings.TrimSuffix(strings.ToLower(zoneDomainName), ".")
+	endpoints = slices.Clone(endpoints)
+	// parent names, and wildcards, are partitioned before the names below them
+	slices.SortStableFunc(endpoints, func(a, b *externaldnsendpoint.Endpoint) int {
+		if c := cmp.Compare(strings.Count(a.DNSName, "."), strings.Count(b.DNSName, ".")); c != 0 {
+			return c
+		}
+		return cmp.Compare(wildcardOrder(a.DNSName), wildcardOrder(b.DNSName))
+	})
+
+	var rootHosts []string
+	builders := map[string]*builder.DNSRecordBuilder{}
+	for _, ep := range endpoints {
+		rootHost := ep.DNSName
+		for parent := parentName(rootHost); strings.HasSuffix(strings.ToLower(parent), "."+zoneDomainName); parent = parentName(parent) {
+			if _, ok := builders[parent]; ok {
+				rootHost = parent
+				break
+			}
+			if _, ok := builders[v1alpha1.WildcardPrefix+parent]; ok {
+				rootHost = v1alpha1.WildcardPrefix + parent
+				break
+			}
+		}
+		if _, ok := builders[rootHost]; !ok {
+			rootHosts = append(rootHosts, rootHost)
+			builders[rootHost] = builder.NewDNSRecordBuilder(dnsRecordName(rootHost), namespace).
+				For(rootHost).
+				WithProviderSecret(providerRef)
+		}
+		builders[rootHost].WithEndpoints(ep)
+	}
+
+	// root hosts that are valid names take their name before those differing from it only in replaced characters
+	slices.SortFunc(rootHosts, func(a, b string) int {
+		if validA, validB := dnsRecordName(a) == a, dnsRecordName(b) == b; validA != validB {
+			if validA {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a, b)
+	})
+	dnsRecords := make([]*v1alpha1.DNSRecord, 0, len(rootHosts))
+	names := map[string]bool{}
+	for _, rootHost := range rootHosts {
+		dnsRecord := builders[rootHost].Build()
+		// names already taken are numbered
+		for i := 2; names[dnsRecord.Name]; i++ {
+			dnsRecord.Name = fmt.Sprintf("%s-%d", dnsRecordName(rootHost), i)
+		}
+		names[dnsRecord.Name] = true
+		dnsRecord.TypeMeta.APIVersion = v1alpha1.GroupVersion.String()
+		dnsRecord.TypeMeta.Kind = "DNSRecord"
+		dnsRecord.Annotations = map[string]string{v1alpha1.AdoptRecordsAnnotation: "true"}
+		dnsRecords = append(dnsRecords, dnsRecord)
+	}
+	return dnsRecords
+}
+
+// wildcardOrder orders wildcard names before other names
+func wildcardOrder(name string) int {
+	if strings.HasPrefix(name, v1alpha1.WildcardPrefix) {
+		return 0
+	}
+	return 1
+}
+
+// parentName returns the given name without its first label
+func parentName(name string) string {
+	_, parent, _ := strings.Cut(name, ".")
+	return parent
+}
+
+// dnsRecordName returns the name of the DNSRecord of the given root host. Characters not allowed in resource names are
+// replaced, and names too long are truncated.
+func dnsRecordName(rootHost string) string {
+	name := strings.NewReplacer("*", "wildcard", "_", "").Replace(strings.ToLower(rootHost))
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = name[:validation.DNS1123SubdomainMaxLength]
+	}
+	return strings.Trim(name, ".-")
+}
@@ -0,0 +1,93 @@
+//go:build unit
+
+package zone
+
+import (
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/pkg/validation"
+)
+
+func TestRecords(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeNS, "ns1.example.com"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "192.0.2.1"),
+		endpoint.NewEndpoint("sub.example.com", endpoint.RecordTypeNS, "ns1.example.org"),
+		endpoint.NewEndpoint("mail.example.com", endpoint.RecordTypeMX, "10 mx1.example.com"),
+		endpoint.NewEndpoint("kuadrant-a-foo.example.com", endpoint.RecordTypeTXT, "heritage=external-dns"),
+		endpoint.NewEndpoint("kuadrant-foo.example.com", endpoint.RecordTypeTXT, "foo"),
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.2"),
+	}
+	managedRecordTypes := []string{endpoint.RecordTypeA, endpoint.RecordTypeNS, endpoint.RecordTypeTXT}
+	records := Records("example.com.", endpoints, managedRecordTypes)
+	var names []string
+	for _, ep := range records {
+		names = append(names, ep.RecordType+" "+ep.DNSName)
+	}
+	want := []string{"A example.com", "NS sub.example.com", "TXT kuadrant-foo.example.com"}
+	if len(names) != len(want) {
+		t.Fatalf("Records() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Records() = %v, want %v", names, want)
+		}
+	}
+}
+
+func TestDNSRecords(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("v1.api.example.com", endpoint.RecordTypeA, "192.0.2.11"),
+		endpoint.NewEndpoint("x.apps.example.com", endpoint.RecordTypeA, "192.0.2.21"),
+		endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "192.0.2.10"),
+		endpoint.NewEndpoint("*.apps.example.com", endpoint.RecordTypeA, "192.0.2.20"),
+		endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "192.0.2.1"),
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeCNAME, "example.com"),
+		endpoint.NewEndpoint("a.b.example.com", endpoint.RecordTypeA, "192.0.2.30"),
+		endpoint.NewEndpoint("_foo.example.com", endpoint.RecordTypeA, "192.0.2.40"),
+		endpoint.NewEndpoint("foo.example.com", endpoint.RecordTypeA, "192.0.2.41"),
+	}
+	dnsRecords := DNSRecords("example.com", "dns", "dns-provider-creds", endpoints)
+
+	want := map[string][]string{
+		"a.b.example.com":           {"a.b.example.com"},
+		"api.example.com":           {"api.example.com", "v1.api.example.com"},
+		"wildcard.apps.example.com": {"*.apps.example.com", "x.apps.example.com"},
+		"example.com":               {"example.com"},
+		"foo.example.com":           {"foo.example.com"},
+		"foo.example.com-2":         {"_foo.example.com"},
+		"www.example.com":           {"www.example.com"},
+	}
+	if len(dnsRecords) != len(want) {
+		t.Fatalf("DNSRecords() returned %d records, want %d", len(dnsRecords), len(want))
+	}
+	for _, dnsRecord := range dnsRecords {
+		wantNames, ok := want[dnsRecord.Name]
+		if !ok {
+			t.Errorf("DNSRecords() returned unexpected record %s", dnsRecord.Name)
+			continue
+		}
+		if dnsRecord.Spec.RootHost != dnsRecord.Spec.Endpoints[0].DNSName {
+			t.Errorf("DNSRecords() %s rootHost = %s, want %s", dnsRecord.Name, dnsRecord.Spec.RootHost, dnsRecord.Spec.Endpoints[0].DNSName)
+		}
+		var names []string
+		for _, ep := range dnsRecord.Spec.Endpoints {
+			names = append(names, ep.DNSName)
+		}
+		if len(names) != len(wantNames) || names[len(names)-1] != wantNames[len(wantNames)-1] {
+			t.Errorf("DNSRecords() %s endpoints = %v, want %v", dnsRecord.Name, names, wantNames)
+		}
+		if dnsRecord.Namespace != "dns" || dnsRecord.Spec.ProviderRef.Name != "dns-provider-creds" {
+			t.Errorf("DNSRecords() %s namespace = %s, providerRef = %s", dnsRecord.Name, dnsRecord.Namespace, dnsRecord.Spec.ProviderRef.Name)
+		}
+		if dnsRecord.Annotations[v1alpha1.AdoptRecordsAnnotation] != "true" {
+			t.Errorf("DNSRecords() %s is not annotated to adopt the existing records", dnsRecord.Name)
+		}
+		if err := validation.Validate(dnsRecord, []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}); err != nil {
+			t.Errorf("DNSRecords() %s is invalid: %v", dnsRecord.Name, err)
+		}
+	}
+}
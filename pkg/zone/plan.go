@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	ownerplan "github.com/kuadrant/dns-operator/internal/external-dns/plan"
+	"github.com/kuadrant/dns-operator/pkg/diff"
+)
+
+// Desired returns the endpoints the given DNSRecords define in the zone with the given domain name, one per key.
+// DNSRecords are in the zone they are assigned to, or if not yet assigned, the zone their root host is in. Targets of
+// endpoints of the same key in several DNSRecords are merged, as they are in the zone.
+func Desired(zoneDomainName string, dnsRecords []*v1alpha1.DNSRecord) []*externaldnsendpoint.Endpoint {
+	zoneDomainName = strings.TrimSuffix(strings.ToLower(zoneDomainName), ".")
+	var endpoints []*externaldnsendpoint.Endpoint
+	byKey := map[externaldnsendpoint.EndpointKey]*externaldnsendpoint.Endpoint{}
+	for _, dnsRecord := range dnsRecords {
+		assignedZone := strings.ToLower(dnsRecord.Status.ZoneDomainName)
+		rootHost := strings.ToLower(strings.TrimPrefix(dnsRecord.Spec.RootHost, v1alpha1.WildcardPrefix))
+		if assignedZone != zoneDomainName && (assignedZone != "" || !strings.HasSuffix("."+rootHost, "."+zoneDomainName)) {
+			continue
+		}
+
+		dnsRecord = dnsRecord.DeepCopy()
+		dnsRecord.ApplyProviderSpecific()
+		for _, ep := range dnsRecord.Spec.Endpoints {
+			if merged, ok := byKey[ep.Key()]; ok {
+				merged.Targets = append(merged.Targets, ep.Targets...)
+				continue
+			}
+			byKey[ep.Key()] = ep
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints
+}
+
+// Plan returns the changes that publishing the desired endpoints would make to the current records of a zone. Endpoints
+// are updated when their targets differ, or their TTL where the desired endpoint sets one. Current records that are not
+// desired are only deleted if all the owners of their owner label are operator owners, as reported by isOperatorOwner.
+// Records without an owner label, e.g. those of zone files, are never deleted.
+func Plan(current, desired []*externaldnsendpoint.Endpoint, isOperatorOwner func(ownerID string) bool) diff.Changes {
+	changes := diff.Endpoints(current, desired)
+	changes.Update = slices.DeleteFunc(changes.Update, func(u diff.Update) bool {
+		return !u.TargetsChanged && !(u.TTLChanged && u.Desired.RecordTTL.IsConfigured())
+	})
+	changes.Delete = slices.DeleteFunc(changes.Delete, func(ep *externaldnsendpoint.Endpoint) bool {
+		owner := ep.Labels[externaldnsendpoint.OwnerLabelKey]
+		return owner == "" || slices.ContainsFunc(strings.Split(owner, ownerplan.OwnerLabelDeliminator), func(ownerID string) bool {
+			return !isOperatorOwner(ownerID)
+		})
+	})
+	return changes
+}
+
+// WritePlan writes the given changes to w, one line per endpoint ordered by name, followed by a summary
+func WritePlan(w io.Writer, changes diff.Changes) error {
+	type line struct {
+		ep   *externaldnsendpoint.Endpoint
+		text string
+	}
+	var lines []line
+	for _, ep := range changes.Create {
+		lines = append(lines, line{ep, fmt.Sprintf("+ %s %s", endpointName(ep), describe(ep))})
+	}
+	for _, u := range changes.Update {
+		lines = append(lines, line{u.Desired, fmt.Sprintf("~ %s %s -> %s", endpointName(u.Desired), describe(u.Current), describe(u.Desired))})
+	}
+	for _, ep := range changes.Delete {
+		lines = append(lines, line{ep, fmt.Sprintf("- %s %s", endpointName(ep), describe(ep))})
+	}
+	slices.SortFunc(lines, func(a, b line) int {
+		if c := cmp.Compare(a.ep.DNSName, b.ep.DNSName); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.ep.RecordType, b.ep.RecordType); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ep.SetIdentifier, b.ep.SetIdentifier)
+	})
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(w, l.text); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d to create, %d to update, %d to delete\n", len(changes.Create), len(changes.Update), len(changes.Delete))
+	return err
+}
+
+// endpointName returns the record type, dnsName and any set identifier of the given endpoint
+func endpointName(ep *externaldnsendpoint.Endpoint) string {
+	name := ep.RecordType + " " + ep.DNSName
+	if ep.SetIdentifier != "" {
+		name += " (" + ep.SetIdentifier + ")"
+	}
+	return name
+}
+
+// describe returns the targets and TTL of the given endpoint
+func describe(ep *externaldnsendpoint.Endpoint) string {
+	if !ep.RecordTTL.IsConfigured() {
+		return fmt.Sprintf("%v", ep.Targets)
+	}
+	return fmt.Sprintf("%v ttl %d", ep.Targets, ep.RecordTTL)
+}
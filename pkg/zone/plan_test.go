@@ -0,0 +1,99 @@
+//go:build unit
+
+package zone
+
+import (
+	"bytes"
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/kuadrant/dns-operator/api/v1alpha1"
+	"github.com/kuadrant/dns-operator/pkg/builder"
+)
+
+func TestDesired(t *testing.T) {
+	assigned := builder.NewDNSRecordBuilder("assigned", "test").
+		WithEndpoint("foo.example.com", endpoint.RecordTypeA, "192.0.2.1").
+		Build()
+	assigned.Status.ZoneDomainName = "example.com"
+	unassigned := builder.NewDNSRecordBuilder("unassigned", "test").
+		WithEndpoint("foo.example.com", endpoint.RecordTypeA, "192.0.2.2").
+		Build()
+	subZone := builder.NewDNSRecordBuilder("sub-zone", "test").
+		WithEndpoint("foo.sub.example.com", endpoint.RecordTypeA, "192.0.2.3").
+		Build()
+	subZone.Status.ZoneDomainName = "sub.example.com"
+	otherZone := builder.NewDNSRecordBuilder("other-zone", "test").
+		WithEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.4").
+		Build()
+	suffix := builder.NewDNSRecordBuilder("suffix", "test").
+		WithEndpoint("fooexample.com", endpoint.RecordTypeA, "192.0.2.5").
+		Build()
+	providerSpecific := builder.NewDNSRecordBuilder("provider-specific", "test").
+		WithEndpoint("bar.example.com", endpoint.RecordTypeCNAME, "lb.example.org").
+		Build()
+	providerSpecific.Spec.ProviderSpecific = endpoint.ProviderSpecific{{Name: "aws/evaluate-target-health", Value: "true"}}
+
+	desired := Desired("example.com.", []*v1alpha1.DNSRecord{assigned, unassigned, subZone, otherZone, suffix, providerSpecific})
+	if len(desired) != 2 {
+		t.Fatalf("Desired() = %v, want 2 endpoints", desired)
+	}
+	if !desired[0].Targets.Same(endpoint.Targets{"192.0.2.1", "192.0.2.2"}) {
+		t.Errorf("Desired() %s targets = %v, want the targets of both records", desired[0].DNSName, desired[0].Targets)
+	}
+	if value, _ := desired[1].GetProviderSpecificProperty("aws/evaluate-target-health"); value != "true" {
+		t.Errorf("Desired() %s did not apply the provider specific properties of the spec", desired[1].DNSName)
+	}
+	if len(assigned.Spec.Endpoints[0].Targets) != 1 {
+		t.Errorf("Desired() modified the given DNSRecords")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("same.example.com", endpoint.RecordTypeA, 300, "192.0.2.1"),
+		endpoint.NewEndpointWithTTL("default-ttl.example.com", endpoint.RecordTypeA, 300, "192.0.2.2"),
+		endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 300, "192.0.2.3"),
+		endpoint.NewEndpointWithTTL("targets.example.com", endpoint.RecordTypeA, 300, "192.0.2.4"),
+		withOwner(endpoint.NewEndpointWithTTL("deleted.example.com", endpoint.RecordTypeA, 300, "192.0.2.5"), "owner1&&owner2"),
+		// not owned by the operator
+		endpoint.NewEndpointWithTTL("unowned.example.com", endpoint.RecordTypeA, 300, "192.0.2.7"),
+		// co-owned by an owner that is not an operator owner
+		withOwner(endpoint.NewEndpointWithTTL("co-owned.example.com", endpoint.RecordTypeA, 300, "192.0.2.8"), "owner1&&other"),
+	}
+	desired := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("same.example.com", endpoint.RecordTypeA, 300, "192.0.2.1"),
+		endpoint.NewEndpoint("default-ttl.example.com", endpoint.RecordTypeA, "192.0.2.2"),
+		endpoint.NewEndpointWithTTL("ttl.example.com", endpoint.RecordTypeA, 60, "192.0.2.3"),
+		endpoint.NewEndpointWithTTL("targets.example.com", endpoint.RecordTypeA, 300, "192.0.2.40"),
+		endpoint.NewEndpointWithTTL("created.example.com", endpoint.RecordTypeA, 300, "192.0.2.6"),
+	}
+
+	isOperatorOwner := func(ownerID string) bool {
+		return ownerID == "owner1" || ownerID == "owner2"
+	}
+	changes := Plan(current, desired, isOperatorOwner)
+	var out bytes.Buffer
+	if err := WritePlan(&out, changes); err != nil {
+		t.Fatalf("WritePlan() error = %v", err)
+	}
+	want := `+ A created.example.com 192.0.2.6 ttl 300
+- A deleted.example.com 192.0.2.5 ttl 300
+~ A targets.example.com 192.0.2.4 ttl 300 -> 192.0.2.40 ttl 300
+~ A ttl.example.com 192.0.2.3 ttl 300 -> 192.0.2.3 ttl 60
+1 to create, 2 to update, 1 to delete
+`
+	if out.String() != want {
+		t.Errorf("WritePlan() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	if changes = Plan(current[:1], desired[:1], isOperatorOwner); changes.HasChanges() {
+		t.Errorf("Plan() = %v, want no changes", changes)
+	}
+}
+
+func withOwner(ep *endpoint.Endpoint, owner string) *endpoint.Endpoint {
+	ep.Labels[endpoint.OwnerLabelKey] = owner
+	return ep
+}
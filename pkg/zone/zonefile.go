@@ -0,0 +1,264 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zone
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	externaldnsendpoint "sigs.k8s.io/external-dns/endpoint"
+)
+
+// entry is a logical line of a zone file, i.e. a line with any parenthesised continuation lines joined
+type entry struct {
+	line int
+	// indented is true if the entry starts with whitespace, i.e. has the owner of the previous entry
+	indented bool
+	fields   []string
+}
+
+// ParseFile returns the records of the given RFC 1035 zone file as endpoints, one per name and record type.
+// Relative names are qualified with origin, unless the file sets its own with $ORIGIN. Targets of CNAME, NS, MX and
+// SRV records are qualified the same way, TXT targets are the unquoted text. $INCLUDE is not supported.
+func ParseFile(r io.Reader, origin string) ([]*externaldnsendpoint.Endpoint, error) {
+	entries, err := readEntries(r)
+	if err != nil {
+		return nil, err
+	}
+
+	origin = strings.TrimSuffix(strings.ToLower(origin), ".")
+	var endpoints []*externaldnsendpoint.Endpoint
+	byKey := map[externaldnsendpoint.EndpointKey]*externaldnsendpoint.Endpoint{}
+	var owner string
+	var defaultTTL, lastTTL externaldnsendpoint.TTL
+	for _, e := range entries {
+		fields := e.fields
+		switch strings.ToUpper(fields[0]) {
+		case "$ORIGIN":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: $ORIGIN requires a domain name", e.line)
+			}
+			origin = qualify(fields[1], origin)
+			continue
+		case "$TTL":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: $TTL requires a TTL", e.line)
+			}
+			if defaultTTL, err = parseTTL(fields[1]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", e.line, err)
+			}
+			continue
+		case "$INCLUDE":
+			return nil, fmt.Errorf("line %d: $INCLUDE is not supported", e.line)
+		}
+
+		if !e.indented {
+			owner, fields = qualify(fields[0], origin), fields[1:]
+		}
+		if owner == "" {
+			return nil, fmt.Errorf("line %d: record has no owner name", e.line)
+		}
+
+		// the TTL and class may be given in either order before the record type
+		ttl := defaultTTL
+		if lastTTL != 0 && defaultTTL == 0 {
+			ttl = lastTTL
+		}
+		for len(fields) > 0 {
+			if strings.EqualFold(fields[0], "IN") {
+				fields = fields[1:]
+			} else if t, err := parseTTL(fields[0]); err == nil {
+				ttl, lastTTL, fields = t, t, fields[1:]
+			} else {
+				break
+			}
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: record %s has no type or data", e.line, owner)
+		}
+
+		recordType := strings.ToUpper(fields[0])
+		target, err := parseTarget(recordType, fields[1:], origin)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s record %s: %w", e.line, recordType, owner, err)
+		}
+		key := externaldnsendpoint.EndpointKey{DNSName: owner, RecordType: recordType}
+		if ep, ok := byKey[key]; ok {
+			ep.Targets = append(ep.Targets, target)
+			continue
+		}
+		byKey[key] = externaldnsendpoint.NewEndpointWithTTL(owner, recordType, ttl, target)
+		endpoints = append(endpoints, byKey[key])
+	}
+	return endpoints, nil
+}
+
+// parseTarget returns the target of an endpoint for the given record data
+func parseTarget(recordType string, data []string, origin string) (string, error) {
+	// index of the domain name in the record data, qualified with the origin
+	nameIndex := -1
+	switch recordType {
+	case externaldnsendpoint.RecordTypeCNAME, externaldnsendpoint.RecordTypeNS, "PTR":
+		nameIndex = 0
+	case externaldnsendpoint.RecordTypeMX:
+		nameIndex = 1
+	case externaldnsendpoint.RecordTypeSRV:
+		nameIndex = 3
+	case externaldnsendpoint.RecordTypeTXT:
+		return strings.Join(data, ""), nil
+	}
+	if nameIndex >= len(data) {
+		return "", fmt.Errorf("expected %d fields, got %d", nameIndex+1, len(data))
+	}
+
+	fields := slices.Clone(data)
+	if nameIndex >= 0 {
+		fields[nameIndex] = qualify(fields[nameIndex], origin)
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// qualify returns the given name as a fully qualified domain name without the trailing dot. Names not ending in a dot
+// are relative to the origin, @ being the origin itself.
+func qualify(name, origin string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case origin == "":
+		return name
+	}
+	return name + "." + origin
+}
+
+// parseTTL parses a TTL in seconds, or in the units s, m, h, d and w, e.g. 1h30m
+func parseTTL(s string) (externaldnsendpoint.TTL, error) {
+	if ttl, err := strconv.ParseUint(s, 10, 31); err == nil {
+		return externaldnsendpoint.TTL(ttl), nil
+	}
+	units := map[rune]uint64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	var ttl, value uint64
+	digits := false
+	for _, c := range strings.ToLower(s) {
+		if unicode.IsDigit(c) {
+			value, digits = value*10+uint64(c-'0'), true
+			continue
+		}
+		unit, ok := units[c]
+		if !ok || !digits {
+			return 0, fmt.Errorf("invalid TTL %s", s)
+		}
+		ttl, value, digits = ttl+value*unit, 0, false
+	}
+	if digits || ttl == 0 || ttl > 1<<31-1 {
+		return 0, fmt.Errorf("invalid TTL %s", s)
+	}
+	return externaldnsendpoint.TTL(ttl), nil
+}
+
+// readEntries splits a zone file into its entries, removing comments and blank lines
+func readEntries(r io.Reader) ([]entry, error) {
+	var entries []entry
+	var current *entry
+	var field strings.Builder
+	inField, inQuotes, escaped, comment := false, false, false, false
+	parens, line := 0, 1
+	reader := bufio.NewReader(r)
+
+	endField := func() {
+		if inField {
+			current.fields = append(current.fields, field.String())
+		}
+		field.Reset()
+		inField = false
+	}
+	endEntry := func() {
+		if current != nil && len(current.fields) > 0 {
+			entries = append(entries, *current)
+		}
+		current = nil
+	}
+
+	for {
+		c, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			current = &entry{line: line, indented: c == ' ' || c == '\t'}
+		}
+
+		switch {
+		case c == '\n':
+			if inQuotes {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+			}
+			endField()
+			if parens == 0 {
+				endEntry()
+			}
+			line++
+			comment = false
+		case comment:
+		case escaped:
+			field.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			inField, escaped = true, true
+		case inQuotes:
+			if c == '"' {
+				inQuotes = false
+			} else {
+				field.WriteRune(c)
+			}
+		case c == '"':
+			endField()
+			inField, inQuotes = true, true
+		case c == ';':
+			comment = true
+		case c == '(':
+			endField()
+			parens++
+		case c == ')':
+			endField()
+			if parens--; parens < 0 {
+				return nil, fmt.Errorf("line %d: unbalanced parentheses", line)
+			}
+		case unicode.IsSpace(c):
+			endField()
+		default:
+			field.WriteRune(c)
+			inField = true
+		}
+	}
+	if inQuotes || parens > 0 {
+		return nil, fmt.Errorf("line %d: unexpected end of zone file", line)
+	}
+	endField()
+	endEntry()
+	return entries, nil
+}
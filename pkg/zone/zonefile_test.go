@@ -0,0 +1,86 @@
+//go:build unit
+
+package zone
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const zoneFile = `$ORIGIN example.com.
+$TTL 1h
+@       IN SOA ns1.example.com. admin.example.com. (
+                2024010101 ; serial
+                3600 900 604800 300 )
+        IN NS   ns1
+        IN A    192.0.2.1
+www     300 IN CNAME @
+api     IN A    192.0.2.10
+        IN A    192.0.2.11
+v1.api  AAAA    2001:db8::1
+mail    MX      10 mx1.example.org.
+_sip._tcp IN 60 SRV 0 5 5060 sip
+_dmarc  TXT     "v=DMARC1; " "p=none" ; policy
+$ORIGIN sub.example.com.
+foo     1d A    192.0.2.30
+`
+
+func TestParseFile(t *testing.T) {
+	endpoints, err := ParseFile(strings.NewReader(zoneFile), "ignored.example.net")
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	want := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("example.com", "SOA", 3600, "ns1.example.com. admin.example.com. 2024010101 3600 900 604800 300"),
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeNS, 3600, "ns1.example.com"),
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeA, 3600, "192.0.2.1"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeCNAME, 300, "example.com"),
+		endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeA, 3600, "192.0.2.10", "192.0.2.11"),
+		endpoint.NewEndpointWithTTL("v1.api.example.com", endpoint.RecordTypeAAAA, 3600, "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("mail.example.com", endpoint.RecordTypeMX, 3600, "10 mx1.example.org"),
+		endpoint.NewEndpointWithTTL("_sip._tcp.example.com", endpoint.RecordTypeSRV, 60, "0 5 5060 sip.example.com"),
+		endpoint.NewEndpointWithTTL("_dmarc.example.com", endpoint.RecordTypeTXT, 3600, "v=DMARC1; p=none"),
+		endpoint.NewEndpointWithTTL("foo.sub.example.com", endpoint.RecordTypeA, 86400, "192.0.2.30"),
+	}
+	if len(endpoints) != len(want) {
+		t.Fatalf("ParseFile() returned %d endpoints %v, want %d", len(endpoints), endpoints, len(want))
+	}
+	for i := range want {
+		if !equality.Semantic.DeepEqual(endpoints[i], want[i]) {
+			t.Errorf("ParseFile() endpoint %d = %v, want %v", i, endpoints[i], want[i])
+		}
+	}
+}
+
+func TestParseFileOrigin(t *testing.T) {
+	endpoints, err := ParseFile(strings.NewReader("foo 60 A 192.0.2.1\n@ 60 A 192.0.2.2\n"), "example.com.")
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].DNSName != "foo.example.com" || endpoints[1].DNSName != "example.com" {
+		t.Errorf("ParseFile() = %v, want names qualified with the given origin", endpoints)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"unterminated quoted string": "foo TXT \"bar\n",
+		"unbalanced parentheses":     "foo A 192.0.2.1 )\n",
+		"unclosed parentheses":       "foo A ( 192.0.2.1\n",
+		"include":                    "$INCLUDE other.zone\n",
+		"no owner":                   "  A 192.0.2.1\n",
+		"no data":                    "foo 60 IN A\n",
+		"invalid TTL":                "$TTL 1x\n",
+		"missing MX exchange":        "foo MX 10\n",
+	}
+	for name, zoneFile := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseFile(strings.NewReader(zoneFile), "example.com"); err == nil {
+				t.Errorf("ParseFile() error = nil, want error")
+			}
+		})
+	}
+}
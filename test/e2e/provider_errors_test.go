@@ -63,7 +63,7 @@ var _ = Describe("DNSRecord Provider Errors", Labels{"provider_errors"}, func()
 			Skip("not yet supported for azure")
 		} else {
 			//AWS
-			expectedProviderErr = "Value 'notageocode' with length = '11' is not facet-valid with respect to length '2' for type 'ContinentCode'"
+			expectedProviderErr = "invalid geo code notageocode for endpoint"
 			validGeoCode = "US"
 		}
 